@@ -26,7 +26,7 @@ SCRIPT_PATH=$(dirname "$(realpath -s "$0")")
 CRI=${CRI:-podman}
 
 options=$(getopt --options "" \
-    --long lint,unit-test,e2e,help\
+    --long lint,unit-test,build,e2e,help\
     -- "${@}")
 eval set -- "$options"
 while true; do
@@ -37,12 +37,15 @@ while true; do
     --unit-test)
         OPT_UNIT_TEST=1
         ;;
+    --build)
+        OPT_BUILD=1
+        ;;
     --e2e)
         OPT_E2E=1
         ;;
     --help)
         set +x
-        echo "$0 [--lint] [--unit-test] [--e2e]"
+        echo "$0 [--lint] [--unit-test] [--build] [--e2e]"
         exit
         ;;
     --)
@@ -63,13 +66,17 @@ if [ -n "${OPT_LINT}" ]; then
     if [ ! -f "$(go env GOPATH)"/bin/golangci-lint ]; then
         curl -sSfL https://raw.githubusercontent.com/golangci/golangci-lint/master/install.sh | sh -s -- -b "$(go env GOPATH)"/bin $golangci_lint_version
     fi
-    golangci-lint run kiagnose/...
+    golangci-lint run kiagnose/... cmd/...
 fi
 
 if [ -n "${OPT_UNIT_TEST}" ]; then
     go test -v "${PWD}"/kiagnose/...
 fi
 
+if [ -n "${OPT_BUILD}" ]; then
+    go build -v -o ./bin/ ./cmd/...
+fi
+
 if [ -n "${OPT_E2E}" ]; then
     "${SCRIPT_PATH}"/e2e.sh "$@"
 fi
@@ -20,6 +20,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
@@ -29,6 +30,13 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "rbac" {
+		if err := vmlatency.RBAC(context.Background(), os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("Kubevirt VM latency checkup rbac: %v\n", err)
+		}
+		return
+	}
+
 	const errMessagePrefix = "Kubevirt VM latency checkup failed"
 	env := environment.EnvToMap(os.Environ())
 
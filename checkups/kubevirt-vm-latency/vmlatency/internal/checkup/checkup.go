@@ -27,23 +27,30 @@ import (
 	"time"
 
 	k8scorev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	k8srand "k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/yaml"
 
 	kvcorev1 "kubevirt.io/api/core/v1"
 
 	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 
+	"github.com/kiagnose/kiagnose/kiagnose/artifacts"
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/launcher"
+
 	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/config"
 	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/status"
 	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/vmi"
 )
 
 type checker interface {
-	Check(sourceVMI, targetVMI *kvcorev1.VirtualMachineInstance, sampleTime time.Duration) error
+	Check(ctx context.Context, sourceVMI, targetVMI *kvcorev1.VirtualMachineInstance, sampleTime time.Duration) error
 	MinLatency() time.Duration
 	AverageLatency() time.Duration
 	MaxLatency() time.Duration
 	CheckDuration() time.Duration
+	Output() string
 }
 
 type checkup struct {
@@ -67,6 +74,19 @@ func New(c vmi.KubevirtVmisClient, uid, namespace string, params config.Config,
 	}
 }
 
+// Failure codes reported by the checkup, in addition to the generic ones of the failure package.
+const (
+	CodeNetworkAttachmentDefinitionNotFound failure.Code = "NetworkAttachmentDefinitionNotFound"
+	CodeVMIStartFailed                      failure.Code = "VMIStartFailed"
+	CodeVMINotReady                         failure.Code = "VMINotReady"
+	CodeLatencyCheckFailed                  failure.Code = "LatencyCheckFailed"
+	CodeLatencyThresholdExceeded            failure.Code = "LatencyThresholdExceeded"
+	CodeVMIDisposalFailed                   failure.Code = "VMIDisposalFailed"
+)
+
+// PingOutputArtifact holds the source VMI console output of the latency measurement.
+const PingOutputArtifact = "ping-output.txt"
+
 const (
 	SourceVMINamePrefix  = "latency-check-source"
 	TargetVMINamePrefix  = "latency-check-target"
@@ -76,12 +96,18 @@ const (
 func (c *checkup) Setup(ctx context.Context) (setupErr error) {
 	const errMessagePrefix = "setup"
 
+	// A retried checkup reports the results of the current attempt only.
+	c.results = status.Results{}
+
 	netAttachDef, err := c.client.GetNetworkAttachmentDefinition(
 		ctx,
 		c.params.NetworkAttachmentDefinitionNamespace,
 		c.params.NetworkAttachmentDefinitionName)
 	if err != nil {
-		return fmt.Errorf("%s: %v", errMessagePrefix, err)
+		if k8serrors.IsNotFound(err) {
+			err = failure.Wrap(CodeNetworkAttachmentDefinitionNotFound, err, failure.WithObject(netAttachDefReference(c.params)))
+		}
+		return fmt.Errorf("%s: %w", errMessagePrefix, err)
 	}
 
 	sourceVMIName := randomizeName(SourceVMINamePrefix)
@@ -90,30 +116,38 @@ func (c *checkup) Setup(ctx context.Context) (setupErr error) {
 	sourceVmi := newLatencyCheckVmi(c.uid, sourceVMIName, c.params.SourceNodeName, c.params.PodName, c.params.PodUID, netAttachDef)
 	targetVmi := newLatencyCheckVmi(c.uid, targetVMIName, c.params.TargetNodeName, c.params.PodName, c.params.PodUID, netAttachDef)
 
-	if err = vmi.Start(ctx, c.client, c.namespace, sourceVmi); err != nil {
-		return fmt.Errorf("%s: %v", errMessagePrefix, err)
+	launcher.ReportProgress(ctx, fmt.Sprintf("starting VMIs %q and %q", sourceVMIName, targetVMIName))
+	createdSourceVmi, err := vmi.Start(ctx, c.client, c.namespace, sourceVmi)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errMessagePrefix, c.vmiFailure(CodeVMIStartFailed, sourceVmi, err))
 	}
+	launcher.TrackObject(ctx, vmi.ObjectReference(c.namespace, createdSourceVmi))
 	defer func() {
 		if setupErr != nil {
 			c.cleanupVMI(sourceVmi.Name)
 		}
 	}()
 
-	if err = vmi.Start(ctx, c.client, c.namespace, targetVmi); err != nil {
-		return fmt.Errorf("%s: %v", errMessagePrefix, err)
+	createdTargetVmi, err := vmi.Start(ctx, c.client, c.namespace, targetVmi)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errMessagePrefix, c.vmiFailure(CodeVMIStartFailed, targetVmi, err))
 	}
+	launcher.TrackObject(ctx, vmi.ObjectReference(c.namespace, createdTargetVmi))
 	defer func() {
 		if setupErr != nil {
 			c.cleanupVMI(targetVmi.Name)
 		}
 	}()
 
+	launcher.ReportProgress(ctx, "waiting for VMIs to report their IP addresses")
 	if c.targetVM, err = vmi.WaitForStatusIPAddress(ctx, c.client, c.namespace, targetVmi.Name); err != nil {
-		return fmt.Errorf("%s: %v", errMessagePrefix, err)
+		c.addVMIArtifact(ctx, targetVmi.Name)
+		return fmt.Errorf("%s: %w", errMessagePrefix, c.vmiFailure(CodeVMINotReady, createdTargetVmi, err))
 	}
 
 	if c.sourceVM, err = vmi.WaitForStatusIPAddress(ctx, c.client, c.namespace, sourceVmi.Name); err != nil {
-		return fmt.Errorf("%s: %v", errMessagePrefix, err)
+		c.addVMIArtifact(ctx, sourceVmi.Name)
+		return fmt.Errorf("%s: %w", errMessagePrefix, c.vmiFailure(CodeVMINotReady, createdSourceVmi, err))
 	}
 
 	c.results.TargetNode = c.targetVM.Status.NodeName
@@ -121,6 +155,44 @@ func (c *checkup) Setup(ctx context.Context) (setupErr error) {
 	return nil
 }
 
+// vmiFailure describes a failure related to a VMI.
+// Such failures are considered transient, as they may be caused by the current cluster load.
+func (c *checkup) vmiFailure(code failure.Code, v *kvcorev1.VirtualMachineInstance, err error) error {
+	return failure.Wrap(code, err, failure.WithObject(vmi.ObjectReference(c.namespace, v)), failure.AsRetryable())
+}
+
+// addVMIArtifact adds the current VMI object as an artifact, named after the VMI, to help finding why it is not ready.
+func (c *checkup) addVMIArtifact(ctx context.Context, vmiName string) {
+	const getTimeout = 10 * time.Second
+
+	// The setup context is likely expired at this point.
+	getCtx, cancel := context.WithTimeout(context.Background(), getTimeout)
+	defer cancel()
+
+	v, err := c.client.GetVirtualMachineInstance(getCtx, c.namespace, vmiName)
+	if err != nil {
+		log.Printf("failed to get VMI '%s/%s' for the artifacts: %v", c.namespace, vmiName, err)
+		return
+	}
+
+	dump, err := yaml.Marshal(v)
+	if err != nil {
+		log.Printf("failed to encode VMI '%s/%s' for the artifacts: %v", c.namespace, vmiName, err)
+		return
+	}
+
+	artifacts.Add(ctx, vmiName+".yaml", dump)
+}
+
+func netAttachDefReference(params config.Config) k8scorev1.ObjectReference {
+	return k8scorev1.ObjectReference{
+		APIVersion: netattdefv1.SchemeGroupVersion.String(),
+		Kind:       "NetworkAttachmentDefinition",
+		Namespace:  params.NetworkAttachmentDefinitionNamespace,
+		Name:       params.NetworkAttachmentDefinitionName,
+	}
+}
+
 func (c *checkup) cleanupVMI(vmiName string) {
 	const setupCleanupTimeout = 30 * time.Second
 
@@ -167,10 +239,20 @@ func newLatencyCheckVmi(
 	)
 }
 
-func (c *checkup) Run() error {
+func (c *checkup) Run(ctx context.Context) error {
 	sampleDuration := time.Duration(c.params.SampleDurationSeconds) * time.Second
-	if err := c.checker.Check(c.sourceVM, c.targetVM, sampleDuration); err != nil {
-		return fmt.Errorf("run: %v", err)
+	launcher.ReportProgress(ctx, fmt.Sprintf("measuring latency between %q and %q for %s",
+		c.sourceVM.Name, c.targetVM.Name, sampleDuration))
+	err := c.checker.Check(ctx, c.sourceVM, c.targetVM, sampleDuration)
+	if output := c.checker.Output(); output != "" {
+		artifacts.Add(ctx, PingOutputArtifact, []byte(output))
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			// The check was aborted by the run timeout or by a cancellation, rather than failed.
+			return fmt.Errorf("run: %w", err)
+		}
+		return fmt.Errorf("run: %w", failure.Wrap(CodeLatencyCheckFailed, err, failure.AsRetryable()))
 	}
 
 	c.results.MinLatency = c.checker.MinLatency()
@@ -181,10 +263,9 @@ func (c *checkup) Run() error {
 	actualMaxLatency := c.results.MaxLatency
 	maxLatencyDesired := c.params.DesiredMaxLatency
 	if actualMaxLatency > maxLatencyDesired {
-		return fmt.Errorf("run : actual max latency %q is greater than desired %q",
-			actualMaxLatency.String(),
-			maxLatencyDesired.String(),
-		)
+		return fmt.Errorf("run : %w", failure.New(CodeLatencyThresholdExceeded,
+			fmt.Sprintf("actual max latency %q is greater than desired %q", actualMaxLatency.String(), maxLatencyDesired.String()),
+		))
 	}
 
 	return nil
@@ -211,13 +292,13 @@ func (c *checkup) Teardown(ctx context.Context) error {
 	}
 
 	if len(teardownErrors) > 0 {
-		return fmt.Errorf("%s: %v", errMessagePrefix, strings.Join(teardownErrors, ", "))
+		return fmt.Errorf("%s: %w", errMessagePrefix, failure.New(CodeVMIDisposalFailed, strings.Join(teardownErrors, ", ")))
 	}
 
 	return nil
 }
 
-func (c *checkup) Results() status.Results {
+func (c *checkup) Results() launcher.ResultsEncoder {
 	return c.results
 }
 
@@ -39,6 +39,10 @@ import (
 
 	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 
+	"github.com/kiagnose/kiagnose/kiagnose/artifacts"
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/launcher"
+
 	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/checkup"
 	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/config"
 	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/vmi"
@@ -121,6 +125,85 @@ func TestCheckupTeardownShouldFailWhen(t *testing.T) {
 	})
 }
 
+func TestCheckupShouldReportFailureCode(t *testing.T) {
+	t.Run("when NetworkAttachmentDefinition is not found", func(t *testing.T) {
+		testClient := newTestClient()
+		testClient.failGetNetAttachDef = k8serrors.NewNotFound(netattdefv1.Resource("network-attachment-definitions"), testNetAttachDefName)
+		testCheckup := checkup.New(testClient, testCheckupUID, testNamespace, newTestsCheckupParameters(), &checkerStub{})
+
+		actualFailure := failure.From(testCheckup.Setup(context.Background()), "")
+		assert.Equal(t, checkup.CodeNetworkAttachmentDefinitionNotFound, actualFailure.Code)
+		assert.Equal(t, testNetAttachDefName, actualFailure.Object.Name)
+		assert.False(t, actualFailure.Retryable)
+	})
+
+	t.Run("when VMI creation fails", func(t *testing.T) {
+		testClient := newTestClient()
+		testClient.returnNetAttachDef = newTestNetAttachDef("")
+		testClient.failCreateVmi = errors.New("vmi create test error")
+		testCheckup := checkup.New(testClient, testCheckupUID, testNamespace, newTestsCheckupParameters(), &checkerStub{})
+
+		actualFailure := failure.From(testCheckup.Setup(context.Background()), "")
+		assert.Equal(t, checkup.CodeVMIStartFailed, actualFailure.Code)
+		assert.Equal(t, "VirtualMachineInstance", actualFailure.Object.Kind)
+		assert.True(t, actualFailure.Retryable)
+	})
+
+	t.Run("when the latency check fails", func(t *testing.T) {
+		testCheckup := newSetUpCheckup(t, &checkerStub{checkFailure: errors.New("no connectivity")})
+
+		actualFailure := failure.From(testCheckup.Run(context.Background()), "")
+		assert.Equal(t, checkup.CodeLatencyCheckFailed, actualFailure.Code)
+		assert.True(t, actualFailure.Retryable)
+	})
+
+	t.Run("when max latency is greater than desired", func(t *testing.T) {
+		testCheckup := newSetUpCheckup(t, &checkerStub{maxLatency: time.Hour})
+
+		actualFailure := failure.From(testCheckup.Run(context.Background()), "")
+		assert.Equal(t, checkup.CodeLatencyThresholdExceeded, actualFailure.Code)
+		assert.False(t, actualFailure.Retryable)
+	})
+}
+
+func TestCheckupShouldAddArtifacts(t *testing.T) {
+	t.Run("with the latency check output", func(t *testing.T) {
+		const pingOutput = "PING 0.0.0.0 (0.0.0.0): 56 data bytes"
+		testCheckup := newSetUpCheckup(t, &checkerStub{checkFailure: errors.New("no connectivity"), output: pingOutput})
+		collector := artifacts.NewCollector()
+
+		assert.Error(t, testCheckup.Run(artifacts.WithCollector(context.Background(), collector)))
+
+		assert.Equal(t, []artifacts.Artifact{{Name: checkup.PingOutputArtifact, Content: []byte(pingOutput)}}, collector.Artifacts())
+	})
+
+	t.Run("with the VMI which is not ready", func(t *testing.T) {
+		testClient := newTestClient()
+		testClient.returnNetAttachDef = newTestNetAttachDef("")
+		testClient.skipIPAddress = true
+		testCheckup := checkup.New(testClient, testCheckupUID, testNamespace, newTestsCheckupParameters(), &checkerStub{})
+		collector := artifacts.NewCollector()
+
+		ctx, cancel := context.WithTimeout(artifacts.WithCollector(context.Background(), collector), time.Millisecond)
+		defer cancel()
+		actualFailure := failure.From(testCheckup.Setup(ctx), "")
+		assert.Equal(t, checkup.CodeVMINotReady, actualFailure.Code)
+
+		collected := collector.Artifacts()
+		assert.Len(t, collected, 1)
+		assert.Equal(t, actualFailure.Object.Name+".yaml", collected[0].Name)
+		assert.Contains(t, string(collected[0].Content), "name: "+actualFailure.Object.Name)
+	})
+}
+
+func newSetUpCheckup(t *testing.T, checker *checkerStub) launcher.Checkup {
+	testClient := newTestClient()
+	testClient.returnNetAttachDef = newTestNetAttachDef("")
+	testCheckup := checkup.New(testClient, testCheckupUID, testNamespace, newTestsCheckupParameters(), checker)
+	assert.NoError(t, testCheckup.Setup(context.Background()))
+	return testCheckup
+}
+
 type checkupSetupCreateVmiTestCase struct {
 	description                string
 	netAttachDef               *netattdefv1.NetworkAttachmentDefinition
@@ -341,7 +424,8 @@ type clientStub struct {
 	failCreateVmi       error
 	failDeleteVmi       error
 
-	skipDeletion bool
+	skipDeletion  bool
+	skipIPAddress bool
 }
 
 func (c *clientStub) GetVirtualMachineInstance(_ context.Context, _, name string) (*kvcorev1.VirtualMachineInstance, error) {
@@ -360,9 +444,11 @@ func (c *clientStub) CreateVirtualMachineInstance(
 		return nil, c.failCreateVmi
 	}
 
-	v.Status.Interfaces = append(v.Status.Interfaces, kvcorev1.VirtualMachineInstanceNetworkInterface{
-		IP: "0.0.0.0",
-	})
+	if !c.skipIPAddress {
+		v.Status.Interfaces = append(v.Status.Interfaces, kvcorev1.VirtualMachineInstanceNetworkInterface{
+			IP: "0.0.0.0",
+		})
+	}
 
 	c.createdVmis[v.Name] = v
 
@@ -406,9 +492,11 @@ func (c *clientStub) TargetVMIName() string {
 
 type checkerStub struct {
 	checkFailure error
+	maxLatency   time.Duration
+	output       string
 }
 
-func (c *checkerStub) Check(_, _ *kvcorev1.VirtualMachineInstance, _ time.Duration) error {
+func (c *checkerStub) Check(_ context.Context, _, _ *kvcorev1.VirtualMachineInstance, _ time.Duration) error {
 	return c.checkFailure
 }
 
@@ -421,9 +509,13 @@ func (c *checkerStub) AverageLatency() time.Duration {
 }
 
 func (c *checkerStub) MaxLatency() time.Duration {
-	return 0
+	return c.maxLatency
 }
 
 func (c *checkerStub) CheckDuration() time.Duration {
 	return 0
 }
+
+func (c *checkerStub) Output() string {
+	return c.output
+}
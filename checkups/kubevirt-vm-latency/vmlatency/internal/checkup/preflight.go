@@ -0,0 +1,81 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package checkup
+
+import (
+	"context"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/kubernetes"
+
+	kvcorev1 "kubevirt.io/api/core/v1"
+
+	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+
+	"github.com/kiagnose/kiagnose/kiagnose/preflight"
+
+	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/config"
+	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/vmi"
+)
+
+// PreflightChecks returns the prerequisites of the checkup: KubeVirt and Multus APIs, the permissions
+// to manage the VMIs and the NetworkAttachmentDefinition existence.
+// Nodes are not verified, as the checkup is not granted access to them.
+func PreflightChecks(k8sClient kubernetes.Interface, c vmi.KubevirtVmisClient, namespace string, params config.Config) []preflight.Check {
+	const (
+		vmisResource         = "virtualmachineinstances"
+		netAttachDefResource = "network-attachment-definitions"
+	)
+
+	vmiAccess := func(verb string) preflight.Check {
+		return preflight.Access(k8sClient, authorizationv1.ResourceAttributes{
+			Namespace: namespace,
+			Verb:      verb,
+			Group:     kvcorev1.GroupVersion.Group,
+			Resource:  vmisResource,
+		})
+	}
+
+	return []preflight.Check{
+		preflight.APIResource(k8sClient.Discovery(), kvcorev1.GroupVersion.String(), vmisResource),
+		preflight.APIResource(k8sClient.Discovery(), netattdefv1.SchemeGroupVersion.String(), netAttachDefResource),
+		vmiAccess("create"),
+		vmiAccess("get"),
+		vmiAccess("delete"),
+		preflight.Access(k8sClient, authorizationv1.ResourceAttributes{
+			Namespace:   namespace,
+			Verb:        "get",
+			Group:       "subresources.kubevirt.io",
+			Resource:    vmisResource,
+			Subresource: "console",
+		}),
+		preflight.Access(k8sClient, authorizationv1.ResourceAttributes{
+			Namespace: params.NetworkAttachmentDefinitionNamespace,
+			Verb:      "get",
+			Group:     netattdefv1.SchemeGroupVersion.Group,
+			Resource:  netAttachDefResource,
+		}),
+		preflight.Object(netAttachDefReference(params), func(ctx context.Context) error {
+			_, err := c.GetNetworkAttachmentDefinition(
+				ctx, params.NetworkAttachmentDefinitionNamespace, params.NetworkAttachmentDefinitionName)
+			return err
+		}),
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package checkup_test
+
+import (
+	"context"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/preflight"
+
+	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/checkup"
+)
+
+func TestPreflightChecksShouldPass(t *testing.T) {
+	fakeClient := newPreflightFakeClient()
+	testClient := newTestClient()
+	testClient.returnNetAttachDef = newTestNetAttachDef("")
+
+	checks := checkup.PreflightChecks(fakeClient, testClient, testNamespace, newTestsCheckupParameters())
+	assert.NoError(t, preflight.Run(context.Background(), checks...))
+}
+
+func TestPreflightChecksShouldFailWhenNetworkAttachmentDefinitionDoesNotExist(t *testing.T) {
+	fakeClient := newPreflightFakeClient()
+	testClient := newTestClient()
+	testClient.failGetNetAttachDef = k8serrors.NewNotFound(netattdefv1.Resource("network-attachment-definitions"), testNetAttachDefName)
+
+	checks := checkup.PreflightChecks(fakeClient, testClient, testNamespace, newTestsCheckupParameters())
+	err := preflight.Run(context.Background(), checks...)
+
+	actualFailure := failure.From(err, "")
+	assert.Equal(t, failure.CodePreflight, actualFailure.Code)
+	assert.Equal(t, testNetAttachDefName, actualFailure.Object.Name)
+}
+
+// newPreflightFakeClient returns a client of a cluster serving the KubeVirt and Multus APIs, which allows any access.
+func newPreflightFakeClient() *fake.Clientset {
+	fakeClient := fake.NewSimpleClientset()
+	fakeClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "kubevirt.io/v1", APIResources: []metav1.APIResource{{Name: "virtualmachineinstances"}}},
+		{GroupVersion: "k8s.cni.cncf.io/v1", APIResources: []metav1.APIResource{{Name: "network-attachment-definitions"}}},
+	}
+	fakeClient.PrependReactor("create", "selfsubjectaccessreviews",
+		func(action clienttesting.Action) (bool, runtime.Object, error) {
+			review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+			review.Status.Allowed = true
+			return true, review, nil
+		})
+	return fakeClient
+}
@@ -31,6 +31,8 @@ import (
 
 	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	netattdefclient "kubevirt.io/client-go/generated/network-attachment-definition-client/clientset/versioned/typed/k8s.cni.cncf.io/v1"
+
+	"github.com/kiagnose/kiagnose/kiagnose/client/clientset/versioned"
 )
 
 type Client struct {
@@ -57,6 +59,16 @@ func New() (*Client, error) {
 	return &Client{c, cniClient}, nil
 }
 
+// NewCheckupClient returns a client of the Checkup objects the checkup can be configured with.
+func NewCheckupClient() (versioned.Interface, error) {
+	kubeconfig, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return versioned.NewForConfig(kubeconfig)
+}
+
 func (c *Client) GetVirtualMachineInstance(ctx context.Context, namespace, name string) (*kvcorev1.VirtualMachineInstance, error) {
 	return c.KubevirtClient.VirtualMachineInstance(namespace).Get(ctx, name, &metav1.GetOptions{})
 }
@@ -0,0 +1,53 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package client
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"github.com/kiagnose/kiagnose/kiagnose/rbac"
+)
+
+// Role returns the permissions required by the Client methods in the checkup namespace.
+func Role() rbac.Role {
+	return rbac.Role{
+		Name: "kubevirt-vm-latency-checker",
+		Rules: []rbacv1.PolicyRule{
+			{
+				// GetVirtualMachineInstance, CreateVirtualMachineInstance, DeleteVirtualMachineInstance
+				APIGroups: []string{"kubevirt.io"},
+				Resources: []string{"virtualmachineinstances"},
+				Verbs:     []string{"get", "create", "delete"},
+			},
+			{
+				// SerialConsole
+				APIGroups: []string{"subresources.kubevirt.io"},
+				Resources: []string{"virtualmachineinstances/console"},
+				Verbs:     []string{"get"},
+			},
+			{
+				// GetNetworkAttachmentDefinition
+				APIGroups: []string{"k8s.cni.cncf.io"},
+				Resources: []string{"network-attachment-definitions"},
+				Verbs:     []string{"get"},
+			},
+		},
+	}
+}
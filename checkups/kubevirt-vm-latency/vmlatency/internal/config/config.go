@@ -22,7 +22,6 @@ package config
 import (
 	"errors"
 	"fmt"
-	"log"
 	"math"
 	"strconv"
 	"time"
@@ -58,6 +57,7 @@ type Config struct {
 	SourceNodeName                       string
 	SampleDurationSeconds                int
 	DesiredMaxLatency                    time.Duration
+	ParamsWarnings                       []string
 }
 
 var (
@@ -72,37 +72,64 @@ const (
 	DefaultDesiredMaxLatencyMilliseconds = math.MaxInt
 )
 
+var schema = kconfig.NewSchema(
+	kconfig.ParamSpec{
+		Name:            NetworkNamespaceParamName,
+		Type:            kconfig.ParamTypeString,
+		DeprecatedNames: []string{NetworkNamespaceDeprecatedParamName},
+	},
+	kconfig.ParamSpec{
+		Name:            NetworkNameParamName,
+		Type:            kconfig.ParamTypeString,
+		DeprecatedNames: []string{NetworkNameDeprecatedParamName},
+	},
+	kconfig.ParamSpec{
+		Name:            SourceNodeNameParamName,
+		Type:            kconfig.ParamTypeString,
+		DeprecatedNames: []string{SourceNodeNameDeprecatedParamName},
+	},
+	kconfig.ParamSpec{
+		Name:            TargetNodeNameParamName,
+		Type:            kconfig.ParamTypeString,
+		DeprecatedNames: []string{TargetNodeNameDeprecatedParamName},
+	},
+	kconfig.ParamSpec{
+		Name:            SampleDurationSecondsParamName,
+		Type:            kconfig.ParamTypeInt,
+		Default:         strconv.Itoa(DefaultSampleDurationSeconds),
+		DeprecatedNames: []string{SampleDurationSecondsDeprecatedParamName},
+	},
+	kconfig.ParamSpec{
+		Name:            DesiredMaxLatencyMillisecondsParamName,
+		Type:            kconfig.ParamTypeInt,
+		DeprecatedNames: []string{DesiredMaxLatencyMillisecondsDeprecatedParamName},
+	},
+)
+
 func New(baseConfig kconfig.Config) (Config, error) {
 	if len(baseConfig.Params) == 0 {
 		return Config{}, ErrInvalidParams
 	}
 
+	params, err := schema.Parse(baseConfig.Params)
+	if err != nil {
+		return Config{}, err
+	}
+
 	newConfig := Config{
 		PodName:                              baseConfig.PodName,
 		PodUID:                               baseConfig.PodUID,
-		SampleDurationSeconds:                DefaultSampleDurationSeconds,
+		SampleDurationSeconds:                params.Int(SampleDurationSecondsParamName),
 		DesiredMaxLatency:                    DefaultDesiredMaxLatencyMilliseconds,
-		NetworkAttachmentDefinitionNamespace: readConfig(baseConfig.Params, NetworkNamespaceParamName, NetworkNamespaceDeprecatedParamName),
-		NetworkAttachmentDefinitionName:      readConfig(baseConfig.Params, NetworkNameParamName, NetworkNameDeprecatedParamName),
-		SourceNodeName:                       readConfig(baseConfig.Params, SourceNodeNameParamName, SourceNodeNameDeprecatedParamName),
-		TargetNodeName:                       readConfig(baseConfig.Params, TargetNodeNameParamName, TargetNodeNameDeprecatedParamName),
+		NetworkAttachmentDefinitionNamespace: params.String(NetworkNamespaceParamName),
+		NetworkAttachmentDefinitionName:      params.String(NetworkNameParamName),
+		SourceNodeName:                       params.String(SourceNodeNameParamName),
+		TargetNodeName:                       params.String(TargetNodeNameParamName),
+		ParamsWarnings:                       params.Warnings(),
 	}
 
-	var err error
-	if v := readConfig(baseConfig.Params, SampleDurationSecondsParamName, SampleDurationSecondsDeprecatedParamName); v != "" {
-		if newConfig.SampleDurationSeconds, err = strconv.Atoi(v); err != nil {
-			return Config{}, fmt.Errorf("%q parameter is invalid: %v", SampleDurationSecondsDeprecatedParamName, err)
-		}
-	}
-
-	if v := readConfig(baseConfig.Params, DesiredMaxLatencyMillisecondsParamName, DesiredMaxLatencyMillisecondsDeprecatedParamName); v != "" {
-		var rawDesiredMaxLatencyMilliseconds int
-
-		if rawDesiredMaxLatencyMilliseconds, err = strconv.Atoi(v); err != nil {
-			return Config{}, fmt.Errorf("%q parameter is invalid: %v", DesiredMaxLatencyMillisecondsParamName, err)
-		}
-
-		newConfig.DesiredMaxLatency = time.Duration(rawDesiredMaxLatencyMilliseconds) * time.Millisecond
+	if params.IsSet(DesiredMaxLatencyMillisecondsParamName) {
+		newConfig.DesiredMaxLatency = time.Duration(params.Int(DesiredMaxLatencyMillisecondsParamName)) * time.Millisecond
 	}
 
 	err = newConfig.validate()
@@ -113,16 +140,6 @@ func New(baseConfig kconfig.Config) (Config, error) {
 	return newConfig, nil
 }
 
-func readConfig(config map[string]string, paramName, paramDeprecatedName string) string {
-	if value, exists := config[paramName]; exists {
-		return value
-	} else if value, exists := config[paramDeprecatedName]; exists {
-		log.Printf("warning: %q parameter is DEPRECATED, please use the new form: %q", paramDeprecatedName, paramName)
-		return value
-	}
-	return ""
-}
-
 func (c Config) validate() error {
 	if c.NetworkAttachmentDefinitionName == "" {
 		return ErrInvalidNetworkName
@@ -345,3 +345,14 @@ func TestCreateConfigShouldFailWhenIntegerParamsAreInvalid(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateConfigShouldFailWhenUnknownParamIsSet(t *testing.T) {
+	baseConfig := kconfig.Config{Params: map[string]string{
+		config.NetworkNameParamName:      testNetAttachDefName,
+		config.NetworkNamespaceParamName: testNamespace,
+		"sampleDurationSecond":           fmt.Sprint(testSampleDurationSeconds),
+	}}
+	_, err := config.New(baseConfig)
+	assert.ErrorIs(t, err, kconfig.ErrInvalidParam)
+	assert.ErrorContains(t, err, fmt.Sprintf("did you mean %q", config.SampleDurationSecondsParamName))
+}
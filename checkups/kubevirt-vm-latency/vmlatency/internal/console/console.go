@@ -20,6 +20,7 @@
 package console
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -49,8 +50,9 @@ func NewConsole(client kubevmi.KubevirtVmisClient, vmi *v1.VirtualMachineInstanc
 	return Console{client: client, vmi: vmi}
 }
 
-// LoginToAlpine performs a console login to an Alpine based VM
-func (c Console) LoginToAlpine() error {
+// LoginToAlpine performs a console login to an Alpine based VM.
+// The login is aborted once the context is done.
+func (c Console) LoginToAlpine(ctx context.Context) error {
 	const connectTimeout = 10 * time.Second
 	expecter, err := c.newExpecter(connectTimeout)
 	if err != nil {
@@ -68,7 +70,7 @@ func (c Console) LoginToAlpine() error {
 		&expect.BExp{R: fmt.Sprintf(`(localhost|%s):~\# `, c.vmi.Name)},
 	}
 	const batchIsLoggedTimeout = 5 * time.Second
-	if _, e := expecter.ExpectBatch(b, batchIsLoggedTimeout); e == nil {
+	if _, e := expectBatch(ctx, expecter, b, batchIsLoggedTimeout); e == nil {
 		return nil
 	}
 
@@ -79,29 +81,32 @@ func (c Console) LoginToAlpine() error {
 		&expect.BExp{R: PromptExpression},
 	}
 	const batchLoginTimeout = 2 * time.Minute
-	res, err := expecter.ExpectBatch(b, batchLoginTimeout)
+	res, err := expectBatch(ctx, expecter, b, batchLoginTimeout)
 	if err != nil {
 		log.Printf("Login attempt to VMI (%s) failed: %+v", c.vmi.Name, res)
+		if ctx.Err() != nil {
+			return err
+		}
 		// Try once more since sometimes the login prompt is ripped apart by asynchronous daemon updates
-		res, err := expecter.ExpectBatch(b, 1*time.Minute)
+		res, err := expectBatch(ctx, expecter, b, 1*time.Minute)
 		if err != nil {
 			log.Printf("Retried login attempt to VMI (%s) after two minutes failed: %+v", c.vmi.Name, res)
 			return err
 		}
 	}
 
-	return configureConsole(expecter)
+	return configureConsole(ctx, expecter)
 }
 
 // RunCommand runs the command line from `command` connecting to an already logged in console at vmi
-// and waiting `timeout` for command to return.
+// and waiting `timeout` for command to return, unless the context is done before.
 // Note: A multiline command is not supported.
-func (c Console) RunCommand(command string, timeout time.Duration) (string, error) {
+func (c Console) RunCommand(ctx context.Context, command string, timeout time.Duration) (string, error) {
 	if strings.ContainsRune(command, '\n') {
 		return "", fmt.Errorf("RunCommand failed: multiline command is not supported")
 	}
 
-	results, err := c.safeExpectBatch([]expect.Batcher{
+	results, err := c.safeExpectBatch(ctx, []expect.Batcher{
 		&expect.BSnd{S: "\n"},
 		&expect.BExp{R: PromptExpression},
 		&expect.BSnd{S: command + "\n"},
@@ -128,7 +133,7 @@ func (c Console) RunCommand(command string, timeout time.Duration) (string, erro
 //   - Use of `BatchSwitchCase`
 //   - Multiline commands
 //   - No more than one sequential send or receive
-func (c Console) safeExpectBatch(batches []expect.Batcher, timeout time.Duration) ([]expect.BatchRes, error) {
+func (c Console) safeExpectBatch(ctx context.Context, batches []expect.Batcher, timeout time.Duration) ([]expect.BatchRes, error) {
 	const connectTimeout = 30 * time.Second
 	expecter, err := c.newExpecter(connectTimeout)
 	if err != nil {
@@ -176,7 +181,34 @@ func (c Console) safeExpectBatch(batches []expect.Batcher, timeout time.Duration
 		}
 	}
 
-	return expecter.ExpectBatch(batches, timeout)
+	return expectBatch(ctx, expecter, batches, timeout)
+}
+
+// expectBatch runs the batch like expecter.ExpectBatch does, and aborts it by closing the expecter once the context is done.
+func expectBatch(ctx context.Context, expecter expect.Expecter, batches []expect.Batcher, timeout time.Duration) ([]expect.BatchRes, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	type batchResult struct {
+		res []expect.BatchRes
+		err error
+	}
+	resultCh := make(chan batchResult, 1)
+	go func() {
+		res, err := expecter.ExpectBatch(batches, timeout)
+		resultCh <- batchResult{res: res, err: err}
+	}()
+
+	select {
+	case result := <-resultCh:
+		return result.res, result.err
+	case <-ctx.Done():
+		if err := expecter.Close(); err != nil {
+			log.Printf("failed to close the console: %v", err)
+		}
+		return nil, ctx.Err()
+	}
 }
 
 // newExpecter will connect to an already logged in VMI console and return the generated expecter it will wait `timeout` for the connection.
@@ -217,7 +249,7 @@ func (c Console) newExpecter(timeout time.Duration, opts ...expect.Option) (expe
 	return expecter, err
 }
 
-func configureConsole(expecter expect.Expecter) error {
+func configureConsole(ctx context.Context, expecter expect.Expecter) error {
 	batch := []expect.Batcher{
 		&expect.BSnd{S: "stty cols 500 rows 500\n"},
 		&expect.BExp{R: PromptExpression},
@@ -229,7 +261,7 @@ func configureConsole(expecter expect.Expecter) error {
 		&expect.BExp{R: RetValue("0")},
 	}
 	const batchTimeout = 30 * time.Second
-	resp, err := expecter.ExpectBatch(batch, batchTimeout)
+	resp, err := expectBatch(ctx, expecter, batch, batchTimeout)
 	if err != nil {
 		log.Printf("console configuration error: %+v", resp)
 	}
@@ -20,6 +20,7 @@
 package latency
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -33,6 +34,7 @@ import (
 type Latency struct {
 	client  kubevmi.KubevirtVmisClient
 	results Results
+	output  string
 }
 
 func New(client kubevmi.KubevirtVmisClient) *Latency {
@@ -55,22 +57,30 @@ func (l *Latency) CheckDuration() time.Duration {
 	return l.results.Time
 }
 
-func (l *Latency) Check(sourceVMI, targetVMI *kvcorev1.VirtualMachineInstance, sampleTime time.Duration) error {
+// Output returns the raw console output of the latest check.
+func (l *Latency) Output() string {
+	return l.output
+}
+
+// Check measures the latency from the source to the target VMI, by pinging it for the sample time.
+// The check is aborted once the context is done.
+func (l *Latency) Check(ctx context.Context, sourceVMI, targetVMI *kvcorev1.VirtualMachineInstance, sampleTime time.Duration) error {
 	const errMessagePrefix = "failed to run check"
 
 	var err error
 
 	sourceVMIConsole := console.NewConsole(l.client, sourceVMI)
 
-	if err = sourceVMIConsole.LoginToAlpine(); err != nil {
-		return fmt.Errorf("%s: %v", errMessagePrefix, err)
+	if err = sourceVMIConsole.LoginToAlpine(ctx); err != nil {
+		return fmt.Errorf("%s: %w", errMessagePrefix, err)
 	}
 
 	const runCommandGracePeriod = time.Minute * 1
 	targetIPAddress := targetVMI.Status.Interfaces[0].IP
 	start := time.Now()
-	res, err := sourceVMIConsole.RunCommand(composePingCommand(targetIPAddress, sampleTime), sampleTime+runCommandGracePeriod)
+	res, err := sourceVMIConsole.RunCommand(ctx, composePingCommand(targetIPAddress, sampleTime), sampleTime+runCommandGracePeriod)
 	pingTime := time.Since(start)
+	l.output = res
 	if err != nil {
 		return err
 	}
@@ -20,9 +20,17 @@
 package status
 
 import (
+	"strconv"
 	"time"
+)
 
-	"github.com/kiagnose/kiagnose/kiagnose/status"
+const (
+	MinLatencyKey          = "minLatencyNanoSec"
+	AvgLatencyKey          = "avgLatencyNanoSec"
+	MaxLatencyKey          = "maxLatencyNanoSec"
+	MeasurementDurationKey = "measurementDurationSec"
+	SourceNodeKey          = "sourceNode"
+	TargetNodeKey          = "targetNode"
 )
 
 type Results struct {
@@ -34,7 +42,19 @@ type Results struct {
 	TargetNode          string
 }
 
-type Status struct {
-	status.Status
-	Results
+func (r Results) Encode() map[string]string {
+	data := map[string]string{}
+
+	var emptyResults Results
+	if r != emptyResults {
+		const base = 10
+		data[MinLatencyKey] = strconv.FormatInt(r.MinLatency.Nanoseconds(), base)
+		data[AvgLatencyKey] = strconv.FormatInt(r.AvgLatency.Nanoseconds(), base)
+		data[MaxLatencyKey] = strconv.FormatInt(r.MaxLatency.Nanoseconds(), base)
+		data[MeasurementDurationKey] = strconv.FormatInt(int64(r.MeasurementDuration.Seconds()), base)
+		data[SourceNodeKey] = r.SourceNode
+		data[TargetNodeKey] = r.TargetNode
+	}
+
+	return data
 }
@@ -0,0 +1,58 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package status_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/status"
+)
+
+func TestResultsShouldSuccessfullyEncode(t *testing.T) {
+	t.Run("empty results", func(t *testing.T) {
+		assert.Empty(t, status.Results{}.Encode())
+	})
+
+	t.Run("measured results", func(t *testing.T) {
+		results := status.Results{
+			MinLatency:          1 * time.Minute,
+			AvgLatency:          2 * time.Minute,
+			MeasurementDuration: 3 * time.Minute,
+			MaxLatency:          4 * time.Minute,
+			TargetNode:          "a",
+			SourceNode:          "b",
+		}
+
+		expectedData := map[string]string{
+			"minLatencyNanoSec":      fmt.Sprint(results.MinLatency.Nanoseconds()),
+			"maxLatencyNanoSec":      fmt.Sprint(results.MaxLatency.Nanoseconds()),
+			"avgLatencyNanoSec":      fmt.Sprint(results.AvgLatency.Nanoseconds()),
+			"measurementDurationSec": fmt.Sprint(results.MeasurementDuration.Seconds()),
+			"targetNode":             results.TargetNode,
+			"sourceNode":             results.SourceNode,
+		}
+
+		assert.Equal(t, expectedData, results.Encode())
+	})
+}
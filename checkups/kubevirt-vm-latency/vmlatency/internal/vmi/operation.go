@@ -25,6 +25,7 @@ import (
 	"log"
 	"time"
 
+	k8scorev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 
@@ -45,12 +46,28 @@ type KubevirtVmisClient interface {
 	GetNetworkAttachmentDefinition(ctx context.Context, namespace, name string) (*netattdefv1.NetworkAttachmentDefinition, error)
 }
 
-func Start(ctx context.Context, c KubevirtVmisClient, namespace string, vmi *kvcorev1.VirtualMachineInstance) error {
+func Start(
+	ctx context.Context,
+	c KubevirtVmisClient,
+	namespace string,
+	vmi *kvcorev1.VirtualMachineInstance) (*kvcorev1.VirtualMachineInstance, error) {
 	log.Printf("starting VMI %s/%s..", namespace, vmi.Name)
-	if _, err := c.CreateVirtualMachineInstance(ctx, namespace, vmi); err != nil {
-		return fmt.Errorf("failed to start VMI %s/%s: %v", vmi.Namespace, vmi.Name, err)
+	createdVMI, err := c.CreateVirtualMachineInstance(ctx, namespace, vmi)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start VMI %s/%s: %v", vmi.Namespace, vmi.Name, err)
+	}
+	return createdVMI, nil
+}
+
+// ObjectReference returns a reference to the given VMI, used to record events against it.
+func ObjectReference(namespace string, vmi *kvcorev1.VirtualMachineInstance) k8scorev1.ObjectReference {
+	return k8scorev1.ObjectReference{
+		APIVersion: kvcorev1.GroupVersion.String(),
+		Kind:       "VirtualMachineInstance",
+		Namespace:  namespace,
+		Name:       vmi.Name,
+		UID:        vmi.UID,
 	}
-	return nil
 }
 
 func WaitForStatusIPAddress(ctx context.Context, c KubevirtVmisClient, namespace, name string) (*kvcorev1.VirtualMachineInstance, error) {
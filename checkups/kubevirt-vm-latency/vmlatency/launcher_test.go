@@ -17,11 +17,14 @@
  *
  */
 
-package launcher_test
+package vmlatency_test
 
 import (
 	"context"
 	"errors"
+	"io"
+	"net"
+	"sync"
 	"testing"
 	"time"
 
@@ -37,10 +40,14 @@ import (
 
 	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/launcher"
+	"github.com/kiagnose/kiagnose/kiagnose/reporter"
+	kstatus "github.com/kiagnose/kiagnose/kiagnose/status"
+
 	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/checkup"
 	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/config"
-	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/launcher"
-	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/reporter"
+	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/latency"
 	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/status"
 )
 
@@ -79,74 +86,6 @@ func TestLauncherShouldRunSuccessfully(t *testing.T) {
 	assert.NoError(t, testLauncher.Run(context.Background()))
 }
 
-func TestLauncherShould(t *testing.T) {
-	t.Run("run successfully", func(t *testing.T) {
-		testLauncher := launcher.New(checkupStub{}, &reporterStub{})
-		assert.NoError(t, testLauncher.Run(context.Background()))
-	})
-
-	t.Run("fail when report is failing", func(t *testing.T) {
-		testLauncher := launcher.New(checkupStub{}, &reporterStub{failReport: errorReport})
-		assert.ErrorContains(t, testLauncher.Run(context.Background()), errorReport.Error())
-	})
-
-	t.Run("fail when setup is failing", func(t *testing.T) {
-		testLauncher := launcher.New(checkupStub{failSetup: errorSetup}, &reporterStub{})
-		assert.ErrorContains(t, testLauncher.Run(context.Background()), errorSetup.Error())
-	})
-
-	t.Run("fail when setup and 2nd report are failing", func(t *testing.T) {
-		testLauncher := launcher.New(
-			checkupStub{failSetup: errorSetup},
-			&reporterStub{failReport: errorReport, failOnSecondReport: true},
-		)
-		err := testLauncher.Run(context.Background())
-		assert.ErrorContains(t, err, errorSetup.Error())
-		assert.ErrorContains(t, err, errorReport.Error())
-	})
-
-	t.Run("fail when run is failing", func(t *testing.T) {
-		testLauncher := launcher.New(checkupStub{failRun: errorRun}, &reporterStub{})
-		assert.ErrorContains(t, testLauncher.Run(context.Background()), errorRun.Error())
-	})
-
-	t.Run("fail when teardown is failing", func(t *testing.T) {
-		testLauncher := launcher.New(checkupStub{failTeardown: errorTeardown}, &reporterStub{})
-		assert.ErrorContains(t, testLauncher.Run(context.Background()), errorTeardown.Error())
-	})
-
-	t.Run("fail when run and report are failing", func(t *testing.T) {
-		testLauncher := launcher.New(
-			checkupStub{failRun: errorRun},
-			&reporterStub{failReport: errorReport, failOnSecondReport: true},
-		)
-		err := testLauncher.Run(context.Background())
-		assert.ErrorContains(t, err, errorRun.Error())
-		assert.ErrorContains(t, err, errorReport.Error())
-	})
-
-	t.Run("fail when teardown and report are failing", func(t *testing.T) {
-		testLauncher := launcher.New(
-			checkupStub{failTeardown: errorTeardown},
-			&reporterStub{failReport: errorReport, failOnSecondReport: true},
-		)
-		err := testLauncher.Run(context.Background())
-		assert.ErrorContains(t, err, errorTeardown.Error())
-		assert.ErrorContains(t, err, errorReport.Error())
-	})
-
-	t.Run("fail when run, teardown and report are failing", func(t *testing.T) {
-		testLauncher := launcher.New(
-			checkupStub{failRun: errorRun, failTeardown: errorTeardown},
-			&reporterStub{failReport: errorReport, failOnSecondReport: true},
-		)
-		err := testLauncher.Run(context.Background())
-		assert.ErrorContains(t, err, errorRun.Error())
-		assert.ErrorContains(t, err, errorTeardown.Error())
-		assert.ErrorContains(t, err, errorReport.Error())
-	})
-}
-
 func TestLauncherShouldSuccessfullyProduceStatusResults(t *testing.T) {
 	const sourceNodeName = "worker1"
 	const targetNodeName = "worker2"
@@ -175,57 +114,75 @@ func TestLauncherShouldSuccessfullyProduceStatusResults(t *testing.T) {
 	assert.Equal(t, expectedResults, testCheckup.Results())
 }
 
-var (
-	errorSetup    = errors.New("setup error")
-	errorRun      = errors.New("run error")
-	errorTeardown = errors.New("teardown error")
-	errorReport   = errors.New("report error")
-)
+func TestLauncherShouldAbortTheLatencyCheckOnceTheRunTimesOut(t *testing.T) {
+	const runTimeout = 100 * time.Millisecond
+	testClient := newFakeClient()
+	testCheckup := checkup.New(
+		testClient,
+		testCheckupUID,
+		testNamespace,
+		config.Config{SampleDurationSeconds: int(time.Hour.Seconds())},
+		latency.New(testClient),
+	)
+	testReporter := &reporterStub{}
+	testLauncher := launcher.New(testCheckup, testReporter, launcher.WithRunTimeout(runTimeout))
 
-type checkupStub struct {
-	failSetup    error
-	failRun      error
-	failTeardown error
-}
+	assert.Error(t, testLauncher.Run(context.Background()))
 
-func (s checkupStub) Setup(_ context.Context) error {
-	return s.failSetup
+	assert.Len(t, testReporter.reportedStatus.FailureDetails, 1)
+	assert.Equal(t, failure.CodeTimeout, testReporter.reportedStatus.FailureDetails[0].Code)
+	assert.Equal(t, string(kstatus.PhaseRunning), testReporter.reportedStatus.FailureDetails[0].Phase)
 }
 
-func (s checkupStub) Run() error {
-	return s.failRun
+func TestLauncherShouldAbortTheLatencyCheckOnceCancelled(t *testing.T) {
+	testClient := newFakeClient()
+	testCheckup := checkup.New(
+		testClient,
+		testCheckupUID,
+		testNamespace,
+		config.Config{SampleDurationSeconds: int(time.Hour.Seconds())},
+		latency.New(testClient),
+	)
+	testReporter := &reporterStub{}
+	testLauncher := launcher.New(testCheckup, testReporter,
+		launcher.WithCancelWatcher(cancelWatcherStub{cancelRequested: testClient.consoleConnected}))
+
+	assert.Error(t, testLauncher.Run(context.Background()))
+
+	assert.Len(t, testReporter.reportedStatus.FailureDetails, 1)
+	assert.Equal(t, failure.CodeCancelled, testReporter.reportedStatus.FailureDetails[0].Code)
+	assert.Equal(t, string(kstatus.PhaseRunning), testReporter.reportedStatus.FailureDetails[0].Phase)
 }
 
-func (s checkupStub) Teardown(_ context.Context) error {
-	return s.failTeardown
+// cancelWatcherStub requests a cancellation once the cancelRequested channel is closed.
+type cancelWatcherStub struct {
+	cancelRequested <-chan struct{}
 }
 
-func (s checkupStub) Results() status.Results {
-	return status.Results{}
+func (w cancelWatcherStub) Watch(ctx context.Context, cancel context.CancelFunc) {
+	select {
+	case <-w.cancelRequested:
+		cancel()
+	case <-ctx.Done():
+	}
 }
 
 type reporterStub struct {
-	reportCalls int
-	failReport  error
-	// The launcher calls the report twice: To mark the start timestamp and
-	// then to update the checkup results.
-	// Use this flag to cause the second report to fail.
-	failOnSecondReport bool
+	reportedStatus kstatus.Status
 }
 
-func (r *reporterStub) Report(_ status.Status) error {
-	r.reportCalls++
-	if r.failOnSecondReport && r.reportCalls == 2 {
-		return r.failReport
-	} else if !r.failOnSecondReport {
-		return r.failReport
-	}
+func (r *reporterStub) Report(statusData kstatus.Status) error {
+	r.reportedStatus = statusData
 	return nil
 }
 
 type fakeClient struct {
 	vmiTracker         map[string]*kvcorev1.VirtualMachineInstance
 	returnNetAttachDef *netattdefv1.NetworkAttachmentDefinition
+
+	// consoleConnected is closed once a VMI serial console is first connected to.
+	consoleConnected chan struct{}
+	connectOnce      sync.Once
 }
 
 // newFakeClient returns fakeClient that tracks VMIs.
@@ -235,6 +192,7 @@ func newFakeClient() *fakeClient {
 	return &fakeClient{
 		vmiTracker:         map[string]*kvcorev1.VirtualMachineInstance{},
 		returnNetAttachDef: &netattdefv1.NetworkAttachmentDefinition{},
+		consoleConnected:   make(chan struct{}),
 	}
 }
 
@@ -275,7 +233,20 @@ func (c *fakeClient) DeleteVirtualMachineInstance(_ context.Context, namespace,
 }
 
 func (c *fakeClient) SerialConsole(namespace, vmiName string, timeout time.Duration) (kubecli.StreamInterface, error) {
-	return nil, nil
+	c.connectOnce.Do(func() { close(c.consoleConnected) })
+	return silentConsole{}, nil
+}
+
+// silentConsole is a VMI serial console which consumes its input and never responds.
+type silentConsole struct{}
+
+func (silentConsole) Stream(options kubecli.StreamOptions) error {
+	_, err := io.Copy(io.Discard, options.In)
+	return err
+}
+
+func (silentConsole) AsConn() net.Conn {
+	return nil
 }
 
 func (c *fakeClient) GetNetworkAttachmentDefinition(_ context.Context, _, _ string) (*netattdefv1.NetworkAttachmentDefinition, error) {
@@ -292,7 +263,7 @@ type checkerStub struct {
 	checkFailure error
 }
 
-func (c *checkerStub) Check(_, _ *kvcorev1.VirtualMachineInstance, _ time.Duration) error {
+func (c *checkerStub) Check(_ context.Context, _, _ *kvcorev1.VirtualMachineInstance, _ time.Duration) error {
 	return c.checkFailure
 }
 
@@ -312,6 +283,10 @@ func (c *checkerStub) CheckDuration() time.Duration {
 	return 0
 }
 
+func (c *checkerStub) Output() string {
+	return ""
+}
+
 func newConfigMap() *k8scorev1.ConfigMap {
 	return &k8scorev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -21,36 +21,118 @@ package vmlatency
 
 import (
 	"context"
+	"errors"
+	"log"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	checkupv1alpha1 "github.com/kiagnose/kiagnose/kiagnose/apis/checkup/v1alpha1"
+	"github.com/kiagnose/kiagnose/kiagnose/artifacts"
+	"github.com/kiagnose/kiagnose/kiagnose/cancellation"
 	kconfig "github.com/kiagnose/kiagnose/kiagnose/config"
+	"github.com/kiagnose/kiagnose/kiagnose/events"
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/launcher"
+	"github.com/kiagnose/kiagnose/kiagnose/reporter"
+	"github.com/kiagnose/kiagnose/kiagnose/sink"
 
 	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/checkup"
 	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/client"
 	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/config"
 	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/latency"
-	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/launcher"
-	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/reporter"
 )
 
+const checkupKind = "Checkup"
+
+var ErrBaselineIsNotSupportedForCheckup = errors.New("baseline is not supported for a checkup configured by a Checkup object")
+
 func Run(rawEnv map[string]string, namespace string) error {
 	c, err := client.New()
 	if err != nil {
 		return err
 	}
 
+	if rawEnv[kconfig.CheckupNameEnvVarName] != "" {
+		return runFromCheckup(c, rawEnv, namespace)
+	}
+
 	baseConfig, err := kconfig.Read(c, rawEnv)
+	if err != nil {
+		if failure.HasCode(err, failure.CodeInvalidInput) {
+			configMapNamespace, configMapName := rawEnv[kconfig.ConfigMapNamespaceEnvVarName], rawEnv[kconfig.ConfigMapNameEnvVarName]
+			if reportErr := reporter.ReportInvalidInput(c, configMapNamespace, configMapName, err); reportErr != nil {
+				log.Printf("failed to report the invalid input: %v", reportErr)
+			}
+		}
+		return err
+	}
+
+	// Invalid parameters are reported by the launcher, once it runs.
+	cfg, cfgErr := config.New(baseConfig)
+
+	l := launcher.New(
+		checkup.New(c, baseConfig.UID, namespace, cfg, latency.New(c)),
+		sink.New(
+			reporter.New(c, baseConfig.ConfigMapNamespace, baseConfig.ConfigMapName),
+			baseConfig.ConfigMapName,
+			baseConfig.Sinks,
+		),
+		launcherOptions(c, namespace, baseConfig, cfg, cfgErr,
+			launcher.WithEventRecorder(
+				events.NewRecorder(c, baseConfig.ConfigMapNamespace, baseConfig.ConfigMapName, baseConfig.UID),
+			),
+			launcher.WithCancelWatcher(cancellation.NewWatcher(c, baseConfig.ConfigMapNamespace, baseConfig.ConfigMapName)),
+			launcher.WithArtifactStore(
+				artifacts.NewStore(c, baseConfig.ConfigMapNamespace, baseConfig.ConfigMapName, baseConfig.UID),
+			),
+		)...,
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), baseConfig.Timeout)
+	defer cancel()
+
+	return l.Run(ctx)
+}
+
+// runFromCheckup runs the checkup configured by a Checkup object, and reports its status to it.
+func runFromCheckup(c *client.Client, rawEnv map[string]string, namespace string) error {
+	checkupClient, err := client.NewCheckupClient()
 	if err != nil {
 		return err
 	}
 
-	cfg, err := config.New(baseConfig)
+	baseConfig, err := kconfig.ReadFromCheckup(checkupClient, rawEnv)
 	if err != nil {
 		return err
 	}
 
+	// The baseline is the archived run of a re-runnable ConfigMap, which a Checkup object has no counterpart of.
+	if baseConfig.Baseline != nil {
+		return ErrBaselineIsNotSupportedForCheckup
+	}
+
+	cfg, cfgErr := config.New(baseConfig)
+
+	checkupRef := corev1.ObjectReference{
+		APIVersion: checkupv1alpha1.SchemeGroupVersion.String(),
+		Kind:       checkupKind,
+		Namespace:  baseConfig.CheckupNamespace,
+		Name:       baseConfig.CheckupName,
+		UID:        types.UID(baseConfig.UID),
+	}
+
 	l := launcher.New(
 		checkup.New(c, baseConfig.UID, namespace, cfg, latency.New(c)),
-		reporter.New(c, baseConfig.ConfigMapNamespace, baseConfig.ConfigMapName),
+		reporter.NewCheckupReporter(checkupClient, baseConfig.CheckupNamespace, baseConfig.CheckupName),
+		launcherOptions(c, namespace, baseConfig, cfg, cfgErr,
+			launcher.WithEventRecorder(events.NewObjectRecorder(c, checkupRef)),
+			launcher.WithCancelWatcher(cancellation.NewCheckupWatcher(checkupClient, baseConfig.CheckupNamespace, baseConfig.CheckupName)),
+			launcher.WithArtifactStore(
+				artifacts.NewStore(c, baseConfig.CheckupNamespace, baseConfig.CheckupName, baseConfig.UID,
+					artifacts.WithOwnerKind(checkupRef.APIVersion, checkupRef.Kind)),
+			),
+		)...,
 	)
 
 	ctx, cancel := context.WithTimeout(context.Background(), baseConfig.Timeout)
@@ -58,3 +140,28 @@ func Run(rawEnv map[string]string, namespace string) error {
 
 	return l.Run(ctx)
 }
+
+// launcherOptions returns the launcher options shared by checkups configured by a ConfigMap and by a Checkup object,
+// followed by the given options, which are specific to the object configuring the checkup.
+func launcherOptions(c *client.Client, namespace string, baseConfig kconfig.Config, cfg config.Config, cfgErr error,
+	objectOptions ...launcher.Option) []launcher.Option {
+	options := []launcher.Option{
+		launcher.WithInputValidation(paramsValidation(cfg, cfgErr)),
+		launcher.WithPreflightChecks(checkup.PreflightChecks(c, c, namespace, cfg)...),
+		launcher.WithSetupTimeout(baseConfig.SetupTimeout),
+		launcher.WithRunTimeout(baseConfig.RunTimeout),
+		launcher.WithTeardownTimeout(baseConfig.TeardownTimeout),
+		launcher.WithRetries(baseConfig.Retries, baseConfig.RetryBackoff),
+		launcher.WithBaseline(baseConfig.Baseline),
+		launcher.WithSuccessCriteria(baseConfig.SuccessCriteria),
+	}
+
+	return append(options, objectOptions...)
+}
+
+// paramsValidation reports the outcome of parsing the checkup parameters.
+func paramsValidation(cfg config.Config, err error) launcher.InputValidation {
+	return func() ([]string, error) {
+		return cfg.ParamsWarnings, err
+	}
+}
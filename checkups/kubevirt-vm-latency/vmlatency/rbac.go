@@ -0,0 +1,106 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package vmlatency
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/kiagnose/kiagnose/kiagnose/rbac"
+
+	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency/internal/client"
+)
+
+const defaultServiceAccountName = "vm-latency-checkup-sa"
+
+// RBAC renders the ServiceAccount and the permissions the checkup requires,
+// or verifies the permissions are granted when the verify flags are set.
+func RBAC(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("rbac", flag.ContinueOnError)
+
+	var (
+		kubeconfig         string
+		namespace          string
+		serviceAccountName string
+		verifyAccount      bool
+		verifySelf         bool
+	)
+	fs.StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file, used for verification")
+	fs.StringVar(&namespace, "namespace", "", "The namespace the checkup runs in")
+	fs.StringVar(&serviceAccountName, "service-account", defaultServiceAccountName, "The ServiceAccount the checkup runs with")
+	fs.BoolVar(&verifyAccount, "verify", false, "Verify the ServiceAccount is granted the permissions, instead of rendering them")
+	fs.BoolVar(&verifySelf, "verify-self", false, "Verify the current identity is granted the permissions, instead of rendering them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	roles := []rbac.Role{rbac.FrameworkRole(), rbac.CheckupRole(), client.Role()}
+
+	if !verifyAccount && !verifySelf {
+		return rbac.Render(out, rbac.Manifests(namespace, serviceAccountName, roles...)...)
+	}
+
+	k8sClient, namespace, err := newClient(kubeconfig, namespace)
+	if err != nil {
+		return err
+	}
+
+	if verifySelf {
+		if err := rbac.VerifySelf(ctx, k8sClient, namespace, roles...); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "Current identity is granted the checkup permissions in namespace %q\n", namespace)
+		return err
+	}
+
+	if err := rbac.Verify(ctx, k8sClient, namespace, serviceAccountName, roles...); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "ServiceAccount %s/%s is granted the checkup permissions\n", namespace, serviceAccountName)
+	return err
+}
+
+func newClient(kubeconfig, namespace string) (kubernetes.Interface, string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		loadingRules,
+		&clientcmd.ConfigOverrides{Context: clientcmdapi.Context{Namespace: namespace}},
+	)
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, "", err
+	}
+
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", err
+	}
+
+	k8sClient, err := kubernetes.NewForConfig(restConfig)
+	return k8sClient, namespace, err
+}
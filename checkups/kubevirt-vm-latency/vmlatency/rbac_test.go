@@ -0,0 +1,44 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package vmlatency_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/kiagnose/kiagnose/checkups/kubevirt-vm-latency/vmlatency"
+)
+
+func TestRBACShouldRenderPermissions(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, vmlatency.RBAC(context.Background(), []string{"--namespace", "target-ns", "--service-account", "my-sa"}, &out))
+
+	rendered := out.String()
+	assert.Contains(t, rendered, "kind: ServiceAccount\nmetadata:\n  name: my-sa\n  namespace: target-ns\n")
+	assert.Contains(t, rendered, "kind: Role\nmetadata:\n  name: kubevirt-vm-latency-checker\n")
+	assert.Contains(t, rendered, "kind: Role\nmetadata:\n  name: kiagnose-configmap-access\n")
+	assert.Contains(t, rendered, "kind: Role\nmetadata:\n  name: kiagnose-checkup-access\n")
+	assert.Contains(t, rendered, "  - checkups/status\n")
+	assert.Contains(t, rendered, "  - virtualmachineinstances/console\n")
+	assert.Contains(t, rendered, "subjects:\n- kind: ServiceAccount\n  name: my-sa\n")
+}
@@ -0,0 +1,94 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	checkupv1alpha1 "github.com/kiagnose/kiagnose/kiagnose/apis/checkup/v1alpha1"
+	"github.com/kiagnose/kiagnose/kiagnose/client/clientset/versioned"
+	"github.com/kiagnose/kiagnose/kiagnose/controller"
+	"github.com/kiagnose/kiagnose/kiagnose/environment"
+)
+
+const watchNamespaceEnvVarName = "WATCH_NAMESPACE"
+
+func main() {
+	const (
+		errMessagePrefix = "Kiagnose controller failed"
+		workers          = 2
+	)
+
+	namespace := os.Getenv(watchNamespaceEnvVarName)
+	if namespace == "" {
+		var err error
+		if namespace, err = environment.ReadNamespaceFile(); err != nil {
+			log.Fatalf("%s: %v\n", errMessagePrefix, err)
+		}
+	}
+
+	restConfig, err := rest.InClusterConfig()
+	if err != nil {
+		log.Fatalf("%s: %v\n", errMessagePrefix, err)
+	}
+
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		log.Fatalf("%s: %v\n", errMessagePrefix, err)
+	}
+
+	var options []controller.Option
+	served, err := checkupAPIServed(client.Discovery())
+	if err != nil {
+		log.Fatalf("%s: %v\n", errMessagePrefix, err)
+	}
+	if served {
+		checkupClient, err := versioned.NewForConfig(restConfig)
+		if err != nil {
+			log.Fatalf("%s: %v\n", errMessagePrefix, err)
+		}
+		options = append(options, controller.WithCheckupClient(checkupClient))
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if err := controller.New(client, namespace, options...).Run(ctx, workers); err != nil {
+		log.Fatalf("%s: %v\n", errMessagePrefix, err)
+	}
+}
+
+// checkupAPIServed returns whether the Checkup custom resource definition is installed.
+func checkupAPIServed(discoveryClient discovery.DiscoveryInterface) (bool, error) {
+	_, err := discoveryClient.ServerResourcesForGroupVersion(checkupv1alpha1.SchemeGroupVersion.String())
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,343 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/kiagnose/kiagnose/kiagnose/cli"
+	"github.com/kiagnose/kiagnose/kiagnose/report"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const usage = `Run and inspect Kiagnose checkups.
+
+Usage:
+  kubectl kiagnose run <name> --image <checkup-image> --timeout <duration> [flags]
+  kubectl kiagnose get <name> [flags]
+  kubectl kiagnose history <name> [flags]
+  kubectl kiagnose artifact <name> --artifact <artifact-name> [flags]
+  kubectl kiagnose report <name> --format <json|junit> [flags]
+  kubectl kiagnose report --file <configmap-yaml> --format <json|junit>
+
+Use "kubectl kiagnose <command> -h" for the command flags.
+`
+
+type paramsFlag map[string]string
+
+func (p paramsFlag) String() string {
+	return fmt.Sprint(map[string]string(p))
+}
+
+func (p paramsFlag) Set(value string) error {
+	const requiredElementsCount = 2
+
+	keyValue := strings.SplitN(value, "=", requiredElementsCount)
+	if len(keyValue) != requiredElementsCount {
+		return fmt.Errorf("param %q should be in the form of key=value", value)
+	}
+
+	p[keyValue[0]] = keyValue[1]
+	return nil
+}
+
+type clientFlags struct {
+	kubeconfig string
+	namespace  string
+}
+
+func (f *clientFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file")
+	fs.StringVar(&f.namespace, "namespace", "", "The namespace of the checkup")
+	fs.StringVar(&f.namespace, "n", "", "The namespace of the checkup (shorthand)")
+}
+
+func (f *clientFlags) client() (kubernetes.Interface, string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = f.kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		loadingRules,
+		&clientcmd.ConfigOverrides{Context: clientcmdapi.Context{Namespace: f.namespace}},
+	)
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, "", err
+	}
+
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", err
+	}
+
+	client, err := kubernetes.NewForConfig(restConfig)
+	return client, namespace, err
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var err error
+	switch os.Args[1] {
+	case "run":
+		err = run(ctx, os.Args[2:])
+	case "get":
+		err = get(os.Args[2:])
+	case "history":
+		err = showHistory(os.Args[2:])
+	case "artifact":
+		err = showArtifact(ctx, os.Args[2:])
+	case "report":
+		err = showReport(ctx, os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	if err != nil {
+		log.Fatalf("kubectl kiagnose %s: %v\n", os.Args[1], err)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+
+	var (
+		cf         clientFlags
+		opts       cli.RunOptions
+		paramsFile string
+		params     = paramsFlag{}
+	)
+	cf.register(fs)
+	fs.StringVar(&opts.Image, "image", "", "The checkup image")
+	fs.StringVar(&opts.ServiceAccountName, "service-account", "", "The ServiceAccount the checkup should run with")
+	fs.StringVar(&opts.Timeout, "timeout", "", "After how much time should the checkup be stopped (e.g. 5m)")
+	fs.StringVar(&paramsFile, "params-file", "", "A YAML file holding the checkup parameters as key-value pairs")
+	fs.Var(params, "param", "A checkup parameter in the form of key=value (can be repeated)")
+	fs.StringVar(&opts.Output, "output", cli.OutputTable, "Output format: table or json")
+	fs.BoolVar(&opts.FollowLogs, "follow", true, "Stream the checkup logs while waiting for its completion")
+
+	name, err := parseNameAndFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	opts.Name = name
+
+	if opts.Image == "" {
+		return fmt.Errorf("--image is required")
+	}
+
+	opts.Params = map[string]string{}
+	if paramsFile != "" {
+		if opts.Params, err = cli.ReadParamsFile(paramsFile); err != nil {
+			return err
+		}
+	}
+	for k, v := range params {
+		opts.Params[k] = v
+	}
+
+	// Reject invalid input before connecting to the cluster.
+	if _, err = cli.NewConfigMap("", opts.Name, opts.Timeout, opts.Params); err != nil {
+		return err
+	}
+	if err = cli.ValidateOutputFormat(opts.Output); err != nil {
+		return err
+	}
+
+	client, namespace, err := cf.client()
+	if err != nil {
+		return err
+	}
+	opts.Namespace = namespace
+
+	return cli.Run(ctx, client, opts, os.Stdout)
+}
+
+func get(args []string) error {
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+
+	var (
+		cf     clientFlags
+		output string
+	)
+	cf.register(fs)
+	fs.StringVar(&output, "output", cli.OutputTable, "Output format: table or json")
+
+	name, err := parseNameAndFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	client, namespace, err := cf.client()
+	if err != nil {
+		return err
+	}
+
+	return cli.Get(client, namespace, name, output, os.Stdout)
+}
+
+func showHistory(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+
+	var (
+		cf     clientFlags
+		output string
+	)
+	cf.register(fs)
+	fs.StringVar(&output, "output", cli.OutputTable, "Output format: table or json")
+
+	name, err := parseNameAndFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	client, namespace, err := cf.client()
+	if err != nil {
+		return err
+	}
+
+	return cli.History(client, namespace, name, output, os.Stdout)
+}
+
+func showArtifact(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("artifact", flag.ExitOnError)
+
+	var (
+		cf           clientFlags
+		artifactName string
+		run          int
+	)
+	cf.register(fs)
+	fs.StringVar(&artifactName, "artifact", "", "The name of the artifact to print")
+	fs.IntVar(&run, "run", 0, "The archived run to read the artifact from (default is the latest run)")
+
+	name, err := parseNameAndFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if artifactName == "" {
+		return fmt.Errorf("--artifact is required")
+	}
+
+	client, namespace, err := cf.client()
+	if err != nil {
+		return err
+	}
+
+	return cli.Artifact(ctx, client, namespace, name, artifactName, run, os.Stdout)
+}
+
+func showReport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+
+	var (
+		cf     clientFlags
+		format string
+		file   string
+		run    int
+	)
+	cf.register(fs)
+	fs.StringVar(&format, "format", report.FormatJUnit, "Report format: junit or json")
+	fs.StringVar(&file, "file", "", "A checkup ConfigMap saved as YAML, to report on without cluster access")
+	fs.IntVar(&run, "run", 0, "The archived run to report on (default is the latest run)")
+
+	name, err := parseOptionalNameAndFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if file == "" {
+		if name == "" {
+			return fmt.Errorf("checkup name or --file is required")
+		}
+
+		client, namespace, err := cf.client()
+		if err != nil {
+			return err
+		}
+
+		return cli.Report(ctx, client, namespace, name, run, format, os.Stdout)
+	}
+
+	configMap, err := cli.ReadConfigMapFile(file)
+	if err != nil {
+		return err
+	}
+
+	// Results spilled into overflow ConfigMaps can only be read from the cluster.
+	var client kubernetes.Interface
+	if _, spilled := configMap.Data[types.OverflowKey]; spilled {
+		if client, _, err = cf.client(); err != nil {
+			return err
+		}
+	}
+
+	return cli.WriteReport(ctx, client, configMap, format, os.Stdout)
+}
+
+// parseNameAndFlags allows the checkup name to be placed either before or after the flags.
+func parseNameAndFlags(fs *flag.FlagSet, args []string) (string, error) {
+	name, err := parseOptionalNameAndFlags(fs, args)
+	if err != nil {
+		return "", err
+	}
+
+	if name == "" {
+		return "", fmt.Errorf("checkup name is required")
+	}
+
+	return name, nil
+}
+
+func parseOptionalNameAndFlags(fs *flag.FlagSet, args []string) (string, error) {
+	var name string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+
+	if name == "" && fs.NArg() > 0 {
+		name = fs.Arg(0)
+	}
+
+	return name, nil
+}
@@ -0,0 +1,37 @@
+#!/usr/bin/env bash
+#
+# This file is part of the kiagnose project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+# Copyright 2022 Red Hat, Inc.
+#
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+SCRIPT_ROOT=$(dirname "$(realpath -s "$0")")/..
+CODEGEN_PKG=${CODEGEN_PKG:-$(cd "${SCRIPT_ROOT}"; go list -m -f '{{.Dir}}' k8s.io/code-generator)}
+MODULE=github.com/kiagnose/kiagnose
+
+OUTPUT_BASE=$(mktemp -d)
+trap 'rm -rf "${OUTPUT_BASE}"' EXIT
+
+bash "${CODEGEN_PKG}"/generate-groups.sh "deepcopy,client" \
+  ${MODULE}/kiagnose/client ${MODULE}/kiagnose/apis \
+  "checkup:v1alpha1" \
+  --output-base "${OUTPUT_BASE}" \
+  --go-header-file "${SCRIPT_ROOT}"/hack/boilerplate.go.txt
+
+cp -r "${OUTPUT_BASE}/${MODULE}/kiagnose/." "${SCRIPT_ROOT}/kiagnose/"
@@ -0,0 +1,142 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Checkup describes a single checkup execution: its input and, once executed, its results.
+type Checkup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CheckupSpec   `json:"spec"`
+	Status CheckupStatus `json:"status,omitempty"`
+}
+
+type CheckupSpec struct {
+	// Timeout is the overall time the checkup is allowed to run.
+	Timeout metav1.Duration `json:"timeout"`
+	// SetupTimeout bounds the checkup setup phase.
+	// +optional
+	SetupTimeout *metav1.Duration `json:"setupTimeout,omitempty"`
+	// RunTimeout bounds the checkup run phase.
+	// +optional
+	RunTimeout *metav1.Duration `json:"runTimeout,omitempty"`
+	// TeardownTimeout bounds the checkup teardown phase.
+	// +optional
+	TeardownTimeout *metav1.Duration `json:"teardownTimeout,omitempty"`
+	// Retries is the number of times a checkup failing with retryable failures is executed again.
+	// +optional
+	Retries int `json:"retries,omitempty"`
+	// RetryBackoff is the delay before the first retry.
+	// +optional
+	RetryBackoff *metav1.Duration `json:"retryBackoff,omitempty"`
+	// SuccessCriteria is an expression over the checkup results, which must hold for the checkup to succeed.
+	// +optional
+	SuccessCriteria string `json:"successCriteria,omitempty"`
+	// Params are arbitrary strings passed to the checkup as input parameters.
+	// +optional
+	Params map[string]string `json:"params,omitempty"`
+}
+
+type CheckupStatus struct {
+	// +optional
+	StartTimestamp *metav1.Time `json:"startTimestamp,omitempty"`
+	// +optional
+	CompletionTimestamp *metav1.Time `json:"completionTimestamp,omitempty"`
+	// Phase is the checkup lifecycle stage: Validating, SettingUp, Running, TearingDown or Completed.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+	// Progress is a human readable message describing what the checkup is currently doing.
+	// +optional
+	Progress string `json:"progress,omitempty"`
+	// LastHeartbeat is the last time the running checkup reported it is alive.
+	// +optional
+	LastHeartbeat *metav1.Time `json:"lastHeartbeat,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Results are arbitrary strings reported by the checkup.
+	// +optional
+	Results map[string]string `json:"results,omitempty"`
+	// FailureDetails describe why the checkup has failed, in a machine-readable form.
+	// +optional
+	FailureDetails []FailureDetail `json:"failureDetails,omitempty"`
+	// Artifacts reference the objects holding the artifacts added by the checkup, keyed by the artifact name.
+	// +optional
+	Artifacts map[string]string `json:"artifacts,omitempty"`
+	// Attempts describe the failed attempts preceding the last attempt of a retried checkup.
+	// +optional
+	Attempts []Attempt `json:"attempts,omitempty"`
+}
+
+// FailureDetail is the machine-readable description of a checkup failure.
+type FailureDetail struct {
+	Code string `json:"code"`
+	// Phase is the checkup lifecycle stage the failure occurred in.
+	// +optional
+	Phase   string `json:"phase,omitempty"`
+	Message string `json:"message"`
+	// Object is the object the failure relates to.
+	// +optional
+	Object    *corev1.ObjectReference `json:"object,omitempty"`
+	Retryable bool                    `json:"retryable"`
+}
+
+// Attempt describes a single attempt of a retried checkup.
+type Attempt struct {
+	StartTimestamp      metav1.Time `json:"startTimestamp"`
+	CompletionTimestamp metav1.Time `json:"completionTimestamp"`
+	Succeeded           bool        `json:"succeeded"`
+	// +optional
+	FailureReason []string `json:"failureReason,omitempty"`
+	// +optional
+	FailureDetails []FailureDetail `json:"failureDetails,omitempty"`
+	// +optional
+	Results map[string]string `json:"results,omitempty"`
+}
+
+const (
+	// ConditionCompleted is true once the checkup has finished, regardless of its outcome.
+	ConditionCompleted = "Completed"
+	// ConditionSucceeded reflects the checkup verdict; its message holds the failure reason.
+	ConditionSucceeded = "Succeeded"
+)
+
+const (
+	ReasonRunning   = "CheckupRunning"
+	ReasonCompleted = "CheckupCompleted"
+	ReasonSucceeded = "CheckupSucceeded"
+	ReasonFailed    = "CheckupFailed"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type CheckupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Checkup `json:"items"`
+}
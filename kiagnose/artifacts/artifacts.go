@@ -0,0 +1,110 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+var ErrInvalidName = errors.New("invalid artifact name")
+
+// Artifact is a named piece of raw data produced by a checkup, e.g. a console transcript or an object dump.
+type Artifact struct {
+	Name    string
+	Content []byte
+	// Secret artifacts are stored in Secrets rather than in ConfigMaps.
+	Secret bool
+}
+
+type Option func(*Artifact)
+
+// AsSecret stores the artifact in Secrets, for content which should not be readable by every ConfigMap reader.
+func AsSecret() Option {
+	return func(a *Artifact) {
+		a.Secret = true
+	}
+}
+
+// Collector accumulates the artifacts added during a checkup run.
+// It is safe for concurrent use.
+type Collector struct {
+	mu        sync.Mutex
+	artifacts []Artifact
+}
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+// Add adds an artifact, replacing a previously added artifact with the same name.
+func (c *Collector) Add(name string, content []byte, options ...Option) {
+	artifact := Artifact{Name: name, Content: content}
+	for _, option := range options {
+		option(&artifact)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := range c.artifacts {
+		if c.artifacts[i].Name == name {
+			c.artifacts[i] = artifact
+			return
+		}
+	}
+	c.artifacts = append(c.artifacts, artifact)
+}
+
+// Artifacts returns the added artifacts, in the order they were first added.
+func (c *Collector) Artifacts() []Artifact {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Artifact{}, c.artifacts...)
+}
+
+type collectorKey struct{}
+
+// WithCollector returns a context through which artifacts are added to the given collector.
+func WithCollector(ctx context.Context, collector *Collector) context.Context {
+	return context.WithValue(ctx, collectorKey{}, collector)
+}
+
+// Add adds an artifact to the checkup run the context belongs to.
+// It has no effect when the context does not originate from a launcher run.
+func Add(ctx context.Context, name string, content []byte, options ...Option) {
+	if collector, ok := ctx.Value(collectorKey{}).(*Collector); ok {
+		collector.Add(name, content, options...)
+	}
+}
+
+// ValidateName verifies the artifact name can be used as a ConfigMap key suffix.
+func ValidateName(name string) error {
+	if errs := validation.IsConfigMapKey(name); len(errs) > 0 {
+		return fmt.Errorf("%w %q: %s", ErrInvalidName, name, strings.Join(errs, ", "))
+	}
+	return nil
+}
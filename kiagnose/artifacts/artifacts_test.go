@@ -0,0 +1,68 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package artifacts_test
+
+import (
+	"context"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/kiagnose/kiagnose/kiagnose/artifacts"
+)
+
+func TestCollector(t *testing.T) {
+	t.Run("collect artifacts added through the context", func(t *testing.T) {
+		collector := artifacts.NewCollector()
+		ctx := artifacts.WithCollector(context.Background(), collector)
+
+		artifacts.Add(ctx, "console.log", []byte("login: "))
+		artifacts.Add(ctx, "token", []byte("secret"), artifacts.AsSecret())
+
+		assert.Equal(t, []artifacts.Artifact{
+			{Name: "console.log", Content: []byte("login: ")},
+			{Name: "token", Content: []byte("secret"), Secret: true},
+		}, collector.Artifacts())
+	})
+
+	t.Run("replace an artifact added with the same name", func(t *testing.T) {
+		collector := artifacts.NewCollector()
+
+		collector.Add("console.log", []byte("login: "))
+		collector.Add("vmi.yaml", []byte("kind: VirtualMachineInstance"))
+		collector.Add("console.log", []byte("login: root"))
+
+		assert.Equal(t, []artifacts.Artifact{
+			{Name: "console.log", Content: []byte("login: root")},
+			{Name: "vmi.yaml", Content: []byte("kind: VirtualMachineInstance")},
+		}, collector.Artifacts())
+	})
+
+	t.Run("ignore artifacts added without a collector", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			artifacts.Add(context.Background(), "console.log", []byte("login: "))
+		})
+	})
+}
+
+func TestValidateName(t *testing.T) {
+	assert.NoError(t, artifacts.ValidateName("source-vmi.console_log"))
+	assert.ErrorIs(t, artifacts.ValidateName("source vmi/console"), artifacts.ErrInvalidName)
+}
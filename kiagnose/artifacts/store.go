@@ -0,0 +1,283 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package artifacts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const (
+	// DefaultChunkSize keeps each artifact object well below the 1MiB object size limit.
+	DefaultChunkSize = 512 * 1024
+
+	ContentKey = "content"
+
+	KindConfigMap = "ConfigMap"
+	KindSecret    = "Secret"
+)
+
+var ErrUnknownKind = errors.New("unknown artifact kind")
+
+// Reference locates the objects holding an artifact content, ordered by chunk.
+// It is reported as JSON under the status.artifacts.<name> key of the user ConfigMap.
+type Reference struct {
+	Kind    string   `json:"kind"`
+	Objects []string `json:"objects"`
+	Size    int      `json:"size"`
+}
+
+// Store writes artifacts into ConfigMaps and Secrets owned by the user ConfigMap,
+// so they are garbage collected along with it.
+type Store struct {
+	client          kubernetes.Interface
+	namespace       string
+	configMapName   string
+	configMapUID    string
+	ownerAPIVersion string
+	ownerKind       string
+	chunkSize       int
+}
+
+type StoreOption func(*Store)
+
+// WithChunkSize sets the maximal content size held by a single object.
+// A size which is not positive is ignored, keeping the DefaultChunkSize.
+func WithChunkSize(size int) StoreOption {
+	return func(s *Store) {
+		if size > 0 {
+			s.chunkSize = size
+		}
+	}
+}
+
+// WithOwnerKind makes the artifacts owned by an object of the given kind, having the name and UID given to NewStore,
+// e.g. a Checkup object configuring the checkup instead of a ConfigMap.
+func WithOwnerKind(apiVersion, kind string) StoreOption {
+	return func(s *Store) {
+		s.ownerAPIVersion = apiVersion
+		s.ownerKind = kind
+	}
+}
+
+func NewStore(client kubernetes.Interface, configMapNamespace, configMapName, configMapUID string, options ...StoreOption) *Store {
+	s := &Store{
+		client:          client,
+		namespace:       configMapNamespace,
+		configMapName:   configMapName,
+		configMapUID:    configMapUID,
+		ownerAPIVersion: "v1",
+		ownerKind:       "ConfigMap",
+		chunkSize:       DefaultChunkSize,
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// Store writes the artifacts and returns their encoded references, keyed by the artifacts names.
+// Artifacts which failed to be stored are skipped, and their errors are returned joined.
+func (s *Store) Store(ctx context.Context, artifacts []Artifact) (map[string]string, error) {
+	references := map[string]string{}
+	var errs []string
+	for _, artifact := range artifacts {
+		reference, err := s.store(ctx, artifact)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("artifact %q: %v", artifact.Name, err))
+			continue
+		}
+
+		rawReference, err := json.Marshal(reference)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("artifact %q: %v", artifact.Name, err))
+			continue
+		}
+		references[artifact.Name] = string(rawReference)
+		log.Printf("stored artifact %q (%d bytes) in %d %s(s)", artifact.Name, reference.Size, len(reference.Objects), reference.Kind)
+	}
+
+	if len(errs) > 0 {
+		return references, errors.New(strings.Join(errs, ", "))
+	}
+	return references, nil
+}
+
+func (s *Store) store(ctx context.Context, artifact Artifact) (Reference, error) {
+	if err := ValidateName(artifact.Name); err != nil {
+		return Reference{}, err
+	}
+
+	reference := Reference{Kind: KindConfigMap, Size: len(artifact.Content)}
+	if artifact.Secret {
+		reference.Kind = KindSecret
+	}
+
+	for _, chunk := range split(artifact.Content, s.chunkSize) {
+		name, err := s.create(ctx, reference.Kind, chunk)
+		if err != nil {
+			// Remove the chunks created so far, rather than leaving a partial artifact behind.
+			if deleteErr := deleteObjects(ctx, s.client, s.namespace, reference); deleteErr != nil {
+				log.Printf("failed to delete the partial artifact %q: %v", artifact.Name, deleteErr)
+			}
+			return Reference{}, err
+		}
+		reference.Objects = append(reference.Objects, name)
+	}
+
+	return reference, nil
+}
+
+func (s *Store) create(ctx context.Context, kind string, chunk []byte) (string, error) {
+	objectMeta := metav1.ObjectMeta{
+		GenerateName: s.configMapName + "-artifact-",
+		Namespace:    s.namespace,
+		Labels:       map[string]string{types.ArtifactOfLabel: s.configMapName},
+		OwnerReferences: []metav1.OwnerReference{
+			{
+				APIVersion: s.ownerAPIVersion,
+				Kind:       s.ownerKind,
+				Name:       s.configMapName,
+				UID:        k8stypes.UID(s.configMapUID),
+			},
+		},
+	}
+
+	if kind == KindSecret {
+		secret, err := s.client.CoreV1().Secrets(s.namespace).Create(ctx, &corev1.Secret{
+			ObjectMeta: objectMeta,
+			Data:       map[string][]byte{ContentKey: chunk},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return "", err
+		}
+		return secret.Name, nil
+	}
+
+	configMap, err := s.client.CoreV1().ConfigMaps(s.namespace).Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: objectMeta,
+		BinaryData: map[string][]byte{ContentKey: chunk},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return "", err
+	}
+	return configMap.Name, nil
+}
+
+// ParseReference decodes a reference reported under a status.artifacts.<name> key.
+func ParseReference(rawReference string) (Reference, error) {
+	var reference Reference
+	if err := json.Unmarshal([]byte(rawReference), &reference); err != nil {
+		return Reference{}, fmt.Errorf("artifact reference is illegal: %v", err)
+	}
+	if reference.Kind != KindConfigMap && reference.Kind != KindSecret {
+		return Reference{}, fmt.Errorf("%w %q", ErrUnknownKind, reference.Kind)
+	}
+	return reference, nil
+}
+
+// Read returns the content of a stored artifact, given its encoded reference.
+func Read(ctx context.Context, client kubernetes.Interface, namespace, rawReference string) ([]byte, error) {
+	reference, err := ParseReference(rawReference)
+	if err != nil {
+		return nil, err
+	}
+
+	content := make([]byte, 0, reference.Size)
+	for _, name := range reference.Objects {
+		chunk, err := readChunk(ctx, client, namespace, reference.Kind, name)
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, chunk...)
+	}
+
+	return content, nil
+}
+
+// Delete removes the objects of a stored artifact, given its encoded reference.
+// Objects which no longer exist are ignored.
+func Delete(ctx context.Context, client kubernetes.Interface, namespace, rawReference string) error {
+	reference, err := ParseReference(rawReference)
+	if err != nil {
+		return err
+	}
+	return deleteObjects(ctx, client, namespace, reference)
+}
+
+func readChunk(ctx context.Context, client kubernetes.Interface, namespace, kind, name string) ([]byte, error) {
+	if kind == KindSecret {
+		secret, err := client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return secret.Data[ContentKey], nil
+	}
+
+	configMap, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return configMap.BinaryData[ContentKey], nil
+}
+
+func deleteObjects(ctx context.Context, client kubernetes.Interface, namespace string, reference Reference) error {
+	for _, name := range reference.Objects {
+		var err error
+		if reference.Kind == KindSecret {
+			err = client.CoreV1().Secrets(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		} else {
+			err = client.CoreV1().ConfigMaps(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		}
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// split returns the content in chunks of at most size bytes.
+// Empty content results in a single empty chunk, so every artifact is backed by an object.
+func split(content []byte, size int) [][]byte {
+	if len(content) == 0 {
+		return [][]byte{{}}
+	}
+
+	var chunks [][]byte
+	for len(content) > size {
+		chunks = append(chunks, content[:size])
+		content = content[size:]
+	}
+	return append(chunks, content)
+}
@@ -0,0 +1,217 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package artifacts_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/kiagnose/kiagnose/kiagnose/artifacts"
+	"github.com/kiagnose/kiagnose/kiagnose/internal/generatename"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const (
+	testNamespace     = "default"
+	testConfigMapName = "checkup-config"
+	testConfigMapUID  = "0123-4567"
+	testChunkSize     = 4
+)
+
+func TestStoreShouldSucceed(t *testing.T) {
+	testCases := map[string]artifacts.Artifact{
+		"a small artifact":            {Name: "console.log", Content: []byte("abc")},
+		"an artifact spanning chunks": {Name: "console.log", Content: []byte("login: root")},
+		"an empty artifact":           {Name: "console.log", Content: []byte{}},
+		"a secret artifact":           {Name: "token", Content: []byte("secret token"), Secret: true},
+	}
+
+	for description, artifact := range testCases {
+		t.Run(description, func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset()
+			generatename.Emulate(fakeClient)
+			store := artifacts.NewStore(fakeClient, testNamespace, testConfigMapName, testConfigMapUID,
+				artifacts.WithChunkSize(testChunkSize))
+
+			references, err := store.Store(context.Background(), []artifacts.Artifact{artifact})
+			assert.NoError(t, err)
+			assert.Contains(t, references, artifact.Name)
+
+			reference, err := artifacts.ParseReference(references[artifact.Name])
+			assert.NoError(t, err)
+			assert.Equal(t, len(artifact.Content), reference.Size)
+			expectedChunks := (len(artifact.Content) + testChunkSize - 1) / testChunkSize
+			if expectedChunks == 0 {
+				expectedChunks = 1
+			}
+			assert.Len(t, reference.Objects, expectedChunks)
+
+			content, err := artifacts.Read(context.Background(), fakeClient, testNamespace, references[artifact.Name])
+			assert.NoError(t, err)
+			assert.Equal(t, artifact.Content, content)
+		})
+	}
+}
+
+func TestStoreShouldIgnoreNonPositiveChunkSize(t *testing.T) {
+	for _, chunkSize := range []int{0, -1} {
+		t.Run(fmt.Sprintf("of %d", chunkSize), func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset()
+			generatename.Emulate(fakeClient)
+			store := artifacts.NewStore(fakeClient, testNamespace, testConfigMapName, testConfigMapUID,
+				artifacts.WithChunkSize(chunkSize))
+
+			artifact := artifacts.Artifact{Name: "console.log", Content: []byte("login: root")}
+			references, err := store.Store(context.Background(), []artifacts.Artifact{artifact})
+			assert.NoError(t, err)
+
+			reference, err := artifacts.ParseReference(references[artifact.Name])
+			assert.NoError(t, err)
+			assert.Len(t, reference.Objects, 1)
+		})
+	}
+}
+
+func TestStoreShouldCreateOwnedObjects(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	generatename.Emulate(fakeClient)
+	store := artifacts.NewStore(fakeClient, testNamespace, testConfigMapName, testConfigMapUID)
+
+	_, err := store.Store(context.Background(), []artifacts.Artifact{
+		{Name: "console.log", Content: []byte("login: ")},
+		{Name: "token", Content: []byte("secret"), Secret: true},
+	})
+	assert.NoError(t, err)
+
+	configMaps, err := fakeClient.CoreV1().ConfigMaps(testNamespace).List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, configMaps.Items, 1)
+	assertOwnedArtifactObject(t, configMaps.Items[0].ObjectMeta)
+	assert.Equal(t, []byte("login: "), configMaps.Items[0].BinaryData[artifacts.ContentKey])
+
+	secrets, err := fakeClient.CoreV1().Secrets(testNamespace).List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, secrets.Items, 1)
+	assertOwnedArtifactObject(t, secrets.Items[0].ObjectMeta)
+	assert.Equal(t, []byte("secret"), secrets.Items[0].Data[artifacts.ContentKey])
+}
+
+func TestStoreShouldCreateObjectsOwnedByTheGivenKind(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	generatename.Emulate(fakeClient)
+	store := artifacts.NewStore(fakeClient, testNamespace, testConfigMapName, testConfigMapUID,
+		artifacts.WithOwnerKind("kiagnose.io/v1alpha1", "Checkup"))
+
+	_, err := store.Store(context.Background(), []artifacts.Artifact{{Name: "console.log", Content: []byte("login: ")}})
+	assert.NoError(t, err)
+
+	configMaps, err := fakeClient.CoreV1().ConfigMaps(testNamespace).List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, configMaps.Items, 1)
+	ownerReferences := configMaps.Items[0].OwnerReferences
+	assert.Len(t, ownerReferences, 1)
+	assert.Equal(t, "kiagnose.io/v1alpha1", ownerReferences[0].APIVersion)
+	assert.Equal(t, "Checkup", ownerReferences[0].Kind)
+	assert.Equal(t, testConfigMapName, ownerReferences[0].Name)
+	assert.Equal(t, testConfigMapUID, string(ownerReferences[0].UID))
+}
+
+func TestStoreShouldFail(t *testing.T) {
+	t.Run("on an invalid artifact name, while storing the other artifacts", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset()
+		generatename.Emulate(fakeClient)
+		store := artifacts.NewStore(fakeClient, testNamespace, testConfigMapName, testConfigMapUID)
+
+		references, err := store.Store(context.Background(), []artifacts.Artifact{
+			{Name: "console log", Content: []byte("login: ")},
+			{Name: "vmi.yaml", Content: []byte("kind: VirtualMachineInstance")},
+		})
+
+		assert.ErrorContains(t, err, artifacts.ErrInvalidName.Error())
+		assert.Contains(t, references, "vmi.yaml")
+		assert.NotContains(t, references, "console log")
+	})
+
+	t.Run("and remove the partially stored artifact when an object creation fails", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset()
+		createdCount := 0
+		fakeClient.PrependReactor("create", "configmaps", func(clienttesting.Action) (bool, runtime.Object, error) {
+			createdCount++
+			if createdCount > 1 {
+				return true, nil, errors.New("create error")
+			}
+			return false, nil, nil
+		})
+		generatename.Emulate(fakeClient)
+		store := artifacts.NewStore(fakeClient, testNamespace, testConfigMapName, testConfigMapUID,
+			artifacts.WithChunkSize(testChunkSize))
+
+		_, err := store.Store(context.Background(), []artifacts.Artifact{{Name: "console.log", Content: []byte("login: root")}})
+		assert.ErrorContains(t, err, "create error")
+
+		configMaps, err := fakeClient.CoreV1().ConfigMaps(testNamespace).List(context.Background(), metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Empty(t, configMaps.Items)
+	})
+}
+
+func TestDeleteShouldRemoveArtifactObjects(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	generatename.Emulate(fakeClient)
+	store := artifacts.NewStore(fakeClient, testNamespace, testConfigMapName, testConfigMapUID,
+		artifacts.WithChunkSize(testChunkSize))
+
+	references, err := store.Store(context.Background(), []artifacts.Artifact{{Name: "token", Content: []byte("secret token"), Secret: true}})
+	assert.NoError(t, err)
+
+	assert.NoError(t, artifacts.Delete(context.Background(), fakeClient, testNamespace, references["token"]))
+	secrets, err := fakeClient.CoreV1().Secrets(testNamespace).List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, secrets.Items)
+
+	assert.NoError(t, artifacts.Delete(context.Background(), fakeClient, testNamespace, references["token"]))
+}
+
+func TestParseReferenceShouldFail(t *testing.T) {
+	_, err := artifacts.ParseReference("not json")
+	assert.Error(t, err)
+
+	_, err = artifacts.ParseReference(`{"kind":"Pod","objects":["pod1"]}`)
+	assert.ErrorIs(t, err, artifacts.ErrUnknownKind)
+}
+
+func assertOwnedArtifactObject(t *testing.T, objectMeta metav1.ObjectMeta) {
+	assert.Equal(t, testConfigMapName+"-artifact-", objectMeta.GenerateName)
+	assert.NotEqual(t, objectMeta.GenerateName, objectMeta.Name)
+	assert.Equal(t, testConfigMapName, objectMeta.Labels[types.ArtifactOfLabel])
+	assert.Len(t, objectMeta.OwnerReferences, 1)
+	assert.Equal(t, testConfigMapName, objectMeta.OwnerReferences[0].Name)
+	assert.Equal(t, "ConfigMap", objectMeta.OwnerReferences[0].Kind)
+	assert.Equal(t, testConfigMapUID, string(objectMeta.OwnerReferences[0].UID))
+}
@@ -0,0 +1,148 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package baseline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kiagnose/kiagnose/kiagnose/configmap"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+)
+
+// DeltaResultPrefix prefixes the result keys reporting the relative change of a result from its baseline, in percent.
+const DeltaResultPrefix = "baselineDeltaPercent."
+
+const (
+	floatBitSize   = 64
+	deltaPrecision = 2
+	percent        = 100
+)
+
+var (
+	ErrBaselineNotCompleted = errors.New("baseline checkup has not completed")
+	ErrToleranceIsIllegal   = errors.New("tolerance is illegal")
+	ErrMissingResult        = errors.New("result is missing")
+)
+
+// Baseline holds the results of a previous checkup run, and the tolerated changes of the new results from them.
+type Baseline struct {
+	// Name identifies the ConfigMap the baseline results were read from, as namespace/name.
+	Name       string
+	Results    map[string]string
+	Tolerances map[string]Tolerance
+}
+
+// Load reads the results reported to the given checkup ConfigMap, which should have completed.
+func Load(ctx context.Context, client kubernetes.Interface, namespace, name string) (map[string]string, error) {
+	configMap, err := configmap.Get(client, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	baselineStatus, err := status.FromConfigMap(ctx, client, configMap)
+	if err != nil {
+		return nil, err
+	}
+
+	if baselineStatus.CompletionTimestamp.IsZero() {
+		return nil, fmt.Errorf("%w: %s/%s", ErrBaselineNotCompleted, namespace, name)
+	}
+
+	return baselineStatus.Results, nil
+}
+
+// Compare reports the relative change of each numeric result from its baseline, keyed by DeltaResultPrefix and the result name.
+// Results which changed beyond their tolerance are regressions, returned as an error along with the deltas.
+func (b *Baseline) Compare(results map[string]string) (map[string]string, error) {
+	deltas := map[string]string{}
+	for name, rawValue := range results {
+		value, valueErr := strconv.ParseFloat(rawValue, floatBitSize)
+		baselineValue, baselineErr := strconv.ParseFloat(b.Results[name], floatBitSize)
+		if valueErr != nil || baselineErr != nil || baselineValue == 0 {
+			continue
+		}
+		deltas[DeltaResultPrefix+name] = strconv.FormatFloat(relativeChange(baselineValue, value), 'f', deltaPrecision, floatBitSize)
+	}
+
+	names := make([]string, 0, len(b.Tolerances))
+	for name := range b.Tolerances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var regressions []string
+	for _, name := range names {
+		tolerance := b.Tolerances[name]
+		baselineValue, err := numericResult(b.Results, name)
+		if err != nil {
+			return deltas, fmt.Errorf("baseline %q: %w", b.Name, err)
+		}
+		value, err := numericResult(results, name)
+		if err != nil {
+			return deltas, err
+		}
+
+		if tolerance.Exceeded(baselineValue, value) {
+			regressions = append(regressions, fmt.Sprintf("%s changed from %s to %s, beyond the %s tolerance",
+				name, b.Results[name], results[name], tolerance))
+		}
+	}
+
+	if len(regressions) > 0 {
+		return deltas, &RegressionError{Baseline: b.Name, Regressions: regressions}
+	}
+	return deltas, nil
+}
+
+// RegressionError describes the results which changed from their baseline beyond their tolerance.
+type RegressionError struct {
+	Baseline    string
+	Regressions []string
+}
+
+func (e *RegressionError) Error() string {
+	return fmt.Sprintf("regression from baseline %q: %s", e.Baseline, strings.Join(e.Regressions, ", "))
+}
+
+func numericResult(results map[string]string, name string) (float64, error) {
+	rawValue, exists := results[name]
+	if !exists {
+		return 0, fmt.Errorf("%w: %q", ErrMissingResult, name)
+	}
+
+	value, err := strconv.ParseFloat(rawValue, floatBitSize)
+	if err != nil {
+		return 0, fmt.Errorf("result %q is not a number: %q", name, rawValue)
+	}
+	return value, nil
+}
+
+// relativeChange returns the change from the baseline value, in percent of the baseline value.
+func relativeChange(baselineValue, value float64) float64 {
+	return (value - baselineValue) / math.Abs(baselineValue) * percent
+}
@@ -0,0 +1,140 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package baseline_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kiagnose/kiagnose/kiagnose/baseline"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const (
+	testNamespace     = "target-ns"
+	baselineName      = "checkup-baseline"
+	baselineReference = testNamespace + "/" + baselineName
+)
+
+func TestLoadShould(t *testing.T) {
+	t.Run("read the results of a completed checkup", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newBaselineConfigMap(map[string]string{
+			types.StartTimestampKey:                   "2022-01-01T10:00:00Z",
+			types.CompletionTimestampKey:              "2022-01-01T10:01:00Z",
+			types.SucceededKey:                        "true",
+			types.ResultsPrefix + "maxLatencyNanoSec": "1000000",
+		}))
+
+		results, err := baseline.Load(context.Background(), fakeClient, testNamespace, baselineName)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"maxLatencyNanoSec": "1000000"}, results)
+	})
+
+	t.Run("fail when the checkup has not completed", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newBaselineConfigMap(map[string]string{
+			types.StartTimestampKey: "2022-01-01T10:00:00Z",
+		}))
+
+		_, err := baseline.Load(context.Background(), fakeClient, testNamespace, baselineName)
+		assert.ErrorIs(t, err, baseline.ErrBaselineNotCompleted)
+	})
+
+	t.Run("fail when the ConfigMap does not exist", func(t *testing.T) {
+		_, err := baseline.Load(context.Background(), fake.NewSimpleClientset(), testNamespace, baselineName)
+		assert.ErrorContains(t, err, "not found")
+	})
+}
+
+func TestCompareShould(t *testing.T) {
+	newBaseline := func(tolerances map[string]string) *baseline.Baseline {
+		b := &baseline.Baseline{
+			Name: baselineReference,
+			Results: map[string]string{
+				"avgLatencyNanoSec": "400000",
+				"maxLatencyNanoSec": "1000000",
+				"sourceNode":        "node1",
+			},
+			Tolerances: map[string]baseline.Tolerance{},
+		}
+		for name, rawTolerance := range tolerances {
+			tolerance, err := baseline.ParseTolerance(rawTolerance)
+			assert.NoError(t, err)
+			b.Tolerances[name] = tolerance
+		}
+		return b
+	}
+
+	results := map[string]string{
+		"avgLatencyNanoSec": "300000",
+		"maxLatencyNanoSec": "1450000",
+		"sourceNode":        "node2",
+		"newResult":         "1",
+	}
+	expectedDeltas := map[string]string{
+		baseline.DeltaResultPrefix + "avgLatencyNanoSec": "-25.00",
+		baseline.DeltaResultPrefix + "maxLatencyNanoSec": "45.00",
+	}
+
+	t.Run("report deltas when within tolerance", func(t *testing.T) {
+		deltas, err := newBaseline(map[string]string{"maxLatencyNanoSec": "+50%", "avgLatencyNanoSec": "+0%"}).Compare(results)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedDeltas, deltas)
+	})
+
+	t.Run("report regressions beyond tolerance", func(t *testing.T) {
+		deltas, err := newBaseline(map[string]string{"maxLatencyNanoSec": "+30%", "avgLatencyNanoSec": "-20%"}).Compare(results)
+
+		assert.Equal(t, expectedDeltas, deltas)
+		var regressionErr *baseline.RegressionError
+		assert.True(t, errors.As(err, &regressionErr))
+		assert.Equal(t, []string{
+			"avgLatencyNanoSec changed from 400000 to 300000, beyond the -20% tolerance",
+			"maxLatencyNanoSec changed from 1000000 to 1450000, beyond the +30% tolerance",
+		}, regressionErr.Regressions)
+		assert.ErrorContains(t, err, `regression from baseline "`+baselineReference+`"`)
+	})
+
+	t.Run("fail when a result with a tolerance is missing", func(t *testing.T) {
+		_, err := newBaseline(map[string]string{"minLatencyNanoSec": "+30%"}).Compare(results)
+
+		assert.ErrorIs(t, err, baseline.ErrMissingResult)
+	})
+
+	t.Run("fail when a result with a tolerance is not a number", func(t *testing.T) {
+		_, err := newBaseline(map[string]string{"sourceNode": "+30%"}).Compare(results)
+
+		assert.ErrorContains(t, err, "is not a number")
+	})
+}
+
+func newBaselineConfigMap(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: baselineName, Namespace: testNamespace},
+		Data:       data,
+	}
+}
@@ -0,0 +1,105 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package baseline
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type Direction string
+
+const (
+	// DirectionAny tolerates changes in both directions up to the limit.
+	DirectionAny Direction = ""
+	// DirectionIncrease regards only increases beyond the limit as regressions, e.g. for latencies.
+	DirectionIncrease Direction = "+"
+	// DirectionDecrease regards only decreases beyond the limit as regressions, e.g. for throughput.
+	DirectionDecrease Direction = "-"
+)
+
+// Tolerance is the change of a result from its baseline which is not considered a regression.
+// It is given as an optional direction followed by a limit, either relative to the baseline value
+// in percent (e.g. "+30%") or absolute (e.g. "-1000").
+type Tolerance struct {
+	Direction Direction
+	Limit     float64
+	Relative  bool
+}
+
+func ParseTolerance(raw string) (Tolerance, error) {
+	var t Tolerance
+
+	rawLimit := strings.TrimSpace(raw)
+	switch {
+	case strings.HasPrefix(rawLimit, string(DirectionIncrease)):
+		t.Direction = DirectionIncrease
+	case strings.HasPrefix(rawLimit, string(DirectionDecrease)):
+		t.Direction = DirectionDecrease
+	}
+	rawLimit = strings.TrimPrefix(rawLimit, string(t.Direction))
+
+	if strings.HasSuffix(rawLimit, "%") {
+		t.Relative = true
+		rawLimit = strings.TrimSuffix(rawLimit, "%")
+	}
+
+	limit, err := strconv.ParseFloat(rawLimit, floatBitSize)
+	if err != nil || limit < 0 || math.IsInf(limit, 0) || math.IsNaN(limit) {
+		return Tolerance{}, fmt.Errorf("%w: %q", ErrToleranceIsIllegal, raw)
+	}
+	t.Limit = limit
+
+	return t, nil
+}
+
+func (t Tolerance) String() string {
+	s := string(t.Direction) + strconv.FormatFloat(t.Limit, 'f', -1, floatBitSize)
+	if t.Relative {
+		s += "%"
+	}
+	return s
+}
+
+// Exceeded reports whether the change of the value from the baseline value is beyond the tolerance.
+func (t Tolerance) Exceeded(baselineValue, value float64) bool {
+	change := value - baselineValue
+	if t.Relative {
+		if baselineValue == 0 {
+			return change != 0 && t.applies(change)
+		}
+		change = relativeChange(baselineValue, value)
+	}
+
+	return t.applies(change) && math.Abs(change) > t.Limit
+}
+
+func (t Tolerance) applies(change float64) bool {
+	switch t.Direction {
+	case DirectionIncrease:
+		return change > 0
+	case DirectionDecrease:
+		return change < 0
+	default:
+		return true
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package baseline_test
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/kiagnose/kiagnose/kiagnose/baseline"
+)
+
+func TestParseToleranceShouldSucceed(t *testing.T) {
+	testCases := map[string]baseline.Tolerance{
+		"+30%":   {Direction: baseline.DirectionIncrease, Limit: 30, Relative: true},
+		"-10%":   {Direction: baseline.DirectionDecrease, Limit: 10, Relative: true},
+		"5.5%":   {Direction: baseline.DirectionAny, Limit: 5.5, Relative: true},
+		"+1000":  {Direction: baseline.DirectionIncrease, Limit: 1000},
+		" 0 ":    {Direction: baseline.DirectionAny, Limit: 0},
+		"-0.25%": {Direction: baseline.DirectionDecrease, Limit: 0.25, Relative: true},
+	}
+
+	for raw, expected := range testCases {
+		t.Run(raw, func(t *testing.T) {
+			actual, err := baseline.ParseTolerance(raw)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestParseToleranceShouldFail(t *testing.T) {
+	for _, raw := range []string{"", "%", "+", "30%%", "thirty", "+-5", "Inf", "NaN%"} {
+		t.Run(raw, func(t *testing.T) {
+			_, err := baseline.ParseTolerance(raw)
+			assert.ErrorIs(t, err, baseline.ErrToleranceIsIllegal)
+		})
+	}
+}
+
+func TestToleranceExceeded(t *testing.T) {
+	type exceededTestCase struct {
+		tolerance     string
+		baselineValue float64
+		value         float64
+		expected      bool
+	}
+
+	testCases := []exceededTestCase{
+		{"+30%", 100, 129, false},
+		{"+30%", 100, 131, true},
+		{"+30%", 100, 10, false},
+		{"-10%", 100, 89, true},
+		{"-10%", 100, 200, false},
+		{"10%", 100, 111, true},
+		{"10%", 100, 89, true},
+		{"10%", 100, 105, false},
+		{"+1000", 5000, 6000, false},
+		{"+1000", 5000, 6001, true},
+		{"+30%", 0, 1, true},
+		{"+30%", 0, 0, false},
+	}
+
+	for _, testCase := range testCases {
+		tolerance, err := baseline.ParseTolerance(testCase.tolerance)
+		assert.NoError(t, err)
+
+		assert.Equal(t, testCase.expected, tolerance.Exceeded(testCase.baselineValue, testCase.value),
+			"tolerance %s, baseline %v, value %v", testCase.tolerance, testCase.baselineValue, testCase.value)
+	}
+}
@@ -0,0 +1,175 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package cancellation
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+
+	checkupv1alpha1 "github.com/kiagnose/kiagnose/kiagnose/apis/checkup/v1alpha1"
+	"github.com/kiagnose/kiagnose/kiagnose/checkup"
+	"github.com/kiagnose/kiagnose/kiagnose/client/clientset/versioned"
+	"github.com/kiagnose/kiagnose/kiagnose/configmap"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const DefaultRetryInterval = 5 * time.Second
+
+// Watcher watches the user ConfigMap, or the Checkup object, for a request to cancel the checkup run.
+type Watcher struct {
+	kind          string
+	namespace     string
+	name          string
+	get           func() (requested bool, resourceVersion string, err error)
+	watch         func(ctx context.Context, resourceVersion string) (watch.Interface, error)
+	requested     func(obj runtime.Object) bool
+	retryInterval time.Duration
+}
+
+func NewWatcher(client kubernetes.Interface, configMapNamespace, configMapName string) *Watcher {
+	return &Watcher{
+		kind:      "ConfigMap",
+		namespace: configMapNamespace,
+		name:      configMapName,
+		get: func() (bool, string, error) {
+			configMap, err := configmap.Get(client, configMapNamespace, configMapName)
+			if err != nil {
+				return false, "", err
+			}
+			return Requested(configMap), configMap.ResourceVersion, nil
+		},
+		watch: func(ctx context.Context, resourceVersion string) (watch.Interface, error) {
+			return configmap.Watch(ctx, client, configMapNamespace, configMapName, resourceVersion)
+		},
+		requested: func(obj runtime.Object) bool {
+			configMap, ok := obj.(*corev1.ConfigMap)
+			return ok && configMap.Name == configMapName && Requested(configMap)
+		},
+		retryInterval: DefaultRetryInterval,
+	}
+}
+
+// NewCheckupWatcher returns a Watcher of the Checkup object configuring the checkup,
+// which requests to cancel the checkup run using the kiagnose.io/cancel annotation.
+func NewCheckupWatcher(client versioned.Interface, checkupNamespace, checkupName string) *Watcher {
+	return &Watcher{
+		kind:      "Checkup",
+		namespace: checkupNamespace,
+		name:      checkupName,
+		get: func() (bool, string, error) {
+			checkupObj, err := checkup.Get(client, checkupNamespace, checkupName)
+			if err != nil {
+				return false, "", err
+			}
+			return isTrue(checkupObj.Annotations[types.CancelAnnotation]), checkupObj.ResourceVersion, nil
+		},
+		watch: func(ctx context.Context, resourceVersion string) (watch.Interface, error) {
+			return checkup.Watch(ctx, client, checkupNamespace, checkupName, resourceVersion)
+		},
+		requested: func(obj runtime.Object) bool {
+			checkupObj, ok := obj.(*checkupv1alpha1.Checkup)
+			return ok && checkupObj.Name == checkupName && isTrue(checkupObj.Annotations[types.CancelAnnotation])
+		},
+		retryInterval: DefaultRetryInterval,
+	}
+}
+
+// Requested reports whether the user asked to cancel the checkup run,
+// either using the spec.cancel key or the kiagnose.io/cancel annotation.
+func Requested(configMap *corev1.ConfigMap) bool {
+	return isTrue(configMap.Data[types.CancelKey]) || isTrue(configMap.Annotations[types.CancelAnnotation])
+}
+
+// Watch calls cancel once a cancellation is requested.
+// It returns when the cancellation is requested or when the context is done.
+// Failures to access the watched object are logged and retried.
+func (w *Watcher) Watch(ctx context.Context, cancel context.CancelFunc) {
+	for {
+		requested, err := w.watchUntilRequested(ctx)
+		if requested {
+			log.Printf("cancellation was requested through %s %s/%s", w.kind, w.namespace, w.name)
+			cancel()
+			return
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			log.Printf("failed to watch %s %s/%s for cancellation: %v", w.kind, w.namespace, w.name, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.retryInterval):
+			}
+		}
+	}
+}
+
+// watchUntilRequested returns once a cancellation is requested, the context is done or the watch is closed.
+func (w *Watcher) watchUntilRequested(ctx context.Context) (bool, error) {
+	requested, resourceVersion, err := w.get()
+	if err != nil {
+		return false, err
+	}
+
+	if requested {
+		return true, nil
+	}
+
+	objectWatcher, err := w.watch(ctx, resourceVersion)
+	if err != nil {
+		return false, err
+	}
+	defer objectWatcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case event, ok := <-objectWatcher.ResultChan():
+			if !ok {
+				return false, nil
+			}
+
+			if event.Type == watch.Error {
+				return false, k8serrors.FromObject(event.Object)
+			}
+
+			if w.requested(event.Object) {
+				return true, nil
+			}
+		}
+	}
+}
+
+func isTrue(value string) bool {
+	b, err := strconv.ParseBool(value)
+	return err == nil && b
+}
@@ -0,0 +1,186 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package cancellation_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	checkupv1alpha1 "github.com/kiagnose/kiagnose/kiagnose/apis/checkup/v1alpha1"
+	"github.com/kiagnose/kiagnose/kiagnose/cancellation"
+	checkupfake "github.com/kiagnose/kiagnose/kiagnose/client/clientset/versioned/fake"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const (
+	configMapNamespace = "kiagnose"
+	configMapName      = "checkup1"
+	testTimeout        = 10 * time.Second
+)
+
+func TestWatcherShouldCancel(t *testing.T) {
+	t.Run("when cancellation was requested before watching", func(t *testing.T) {
+		configMap := newConfigMap()
+		configMap.Data[types.CancelKey] = "true"
+		fakeClient := fake.NewSimpleClientset(configMap)
+
+		assert.True(t, watchForCancellation(t, cancellation.NewWatcher(fakeClient, configMapNamespace, configMapName), nil))
+	})
+
+	t.Run("when spec.cancel is set while watching", func(t *testing.T) {
+		fakeClient, fakeWatcher := newFakeClientWithWatcher()
+
+		cancelled := watchForCancellation(t, cancellation.NewWatcher(fakeClient, configMapNamespace, configMapName), func() {
+			configMap := newConfigMap()
+			configMap.Data[types.CancelKey] = "true"
+			fakeWatcher.Modify(configMap)
+		})
+		assert.True(t, cancelled)
+	})
+
+	t.Run("when the cancel annotation is set while watching", func(t *testing.T) {
+		fakeClient, fakeWatcher := newFakeClientWithWatcher()
+
+		cancelled := watchForCancellation(t, cancellation.NewWatcher(fakeClient, configMapNamespace, configMapName), func() {
+			configMap := newConfigMap()
+			configMap.Annotations = map[string]string{types.CancelAnnotation: "true"}
+			fakeWatcher.Modify(configMap)
+		})
+		assert.True(t, cancelled)
+	})
+}
+
+func TestCheckupWatcherShouldCancel(t *testing.T) {
+	t.Run("when the cancel annotation was set before watching", func(t *testing.T) {
+		checkupObj := newCheckup()
+		checkupObj.Annotations = map[string]string{types.CancelAnnotation: "true"}
+		fakeClient := checkupfake.NewSimpleClientset(checkupObj)
+
+		assert.True(t, watchForCancellation(t, cancellation.NewCheckupWatcher(fakeClient, configMapNamespace, configMapName), nil))
+	})
+
+	t.Run("when the cancel annotation is set while watching", func(t *testing.T) {
+		fakeClient := checkupfake.NewSimpleClientset(newCheckup())
+		fakeWatcher := watch.NewFake()
+		fakeClient.PrependWatchReactor("checkups", clienttesting.DefaultWatchReactor(fakeWatcher, nil))
+
+		cancelled := watchForCancellation(t, cancellation.NewCheckupWatcher(fakeClient, configMapNamespace, configMapName), func() {
+			checkupObj := newCheckup()
+			checkupObj.Annotations = map[string]string{types.CancelAnnotation: "false"}
+			fakeWatcher.Modify(checkupObj)
+
+			checkupObj = newCheckup()
+			checkupObj.Annotations = map[string]string{types.CancelAnnotation: "true"}
+			fakeWatcher.Modify(checkupObj)
+		})
+		assert.True(t, cancelled)
+	})
+}
+
+func TestWatcherShouldNotCancel(t *testing.T) {
+	t.Run("when unrelated changes are made", func(t *testing.T) {
+		fakeClient, fakeWatcher := newFakeClientWithWatcher()
+
+		cancelled := watchForCancellation(t, cancellation.NewWatcher(fakeClient, configMapNamespace, configMapName), func() {
+			configMap := newConfigMap()
+			configMap.Data[types.CancelKey] = "false"
+			fakeWatcher.Modify(configMap)
+
+			otherConfigMap := newConfigMap()
+			otherConfigMap.Name = "other"
+			otherConfigMap.Data[types.CancelKey] = "true"
+			fakeWatcher.Modify(otherConfigMap)
+		})
+		assert.False(t, cancelled)
+	})
+}
+
+// watchForCancellation watches until the changes are applied and then stops the watch.
+// It returns whether the run was cancelled.
+func watchForCancellation(t *testing.T, watcher *cancellation.Watcher, applyChanges func()) bool {
+	ctx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+
+	runCtx, cancelRun := context.WithCancel(context.Background())
+	defer cancelRun()
+
+	watchDone := make(chan struct{})
+	go func() {
+		defer close(watchDone)
+		watcher.Watch(ctx, cancelRun)
+	}()
+
+	if applyChanges != nil {
+		applyChanges()
+	}
+
+	select {
+	case <-runCtx.Done():
+	case <-watchDone:
+	case <-time.After(testTimeout / 10):
+	}
+	stopWatch()
+
+	select {
+	case <-watchDone:
+	case <-time.After(testTimeout):
+		t.Fatal("watch did not stop")
+	}
+
+	return runCtx.Err() != nil
+}
+
+// newFakeClientWithWatcher returns a client whose ConfigMap changes are injected using the returned watcher.
+// The injected changes are received only once the watch is established.
+func newFakeClientWithWatcher() (*fake.Clientset, *watch.FakeWatcher) {
+	fakeClient := fake.NewSimpleClientset(newConfigMap())
+	fakeWatcher := watch.NewFake()
+	fakeClient.PrependWatchReactor("configmaps", clienttesting.DefaultWatchReactor(fakeWatcher, nil))
+	return fakeClient, fakeWatcher
+}
+
+func newConfigMap() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapName,
+			Namespace: configMapNamespace,
+		},
+		Data: map[string]string{types.TimeoutKey: "1m"},
+	}
+}
+
+func newCheckup() *checkupv1alpha1.Checkup {
+	return &checkupv1alpha1.Checkup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapName,
+			Namespace: configMapNamespace,
+		},
+		Spec: checkupv1alpha1.CheckupSpec{Timeout: metav1.Duration{Duration: time.Minute}},
+	}
+}
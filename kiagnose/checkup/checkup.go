@@ -0,0 +1,46 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package checkup
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+
+	checkupv1alpha1 "github.com/kiagnose/kiagnose/kiagnose/apis/checkup/v1alpha1"
+	"github.com/kiagnose/kiagnose/kiagnose/client/clientset/versioned"
+)
+
+func Get(client versioned.Interface, namespace, name string) (*checkupv1alpha1.Checkup, error) {
+	return client.KiagnoseV1alpha1().Checkups(namespace).Get(context.Background(), name, metav1.GetOptions{})
+}
+
+func Watch(ctx context.Context, client versioned.Interface, namespace, name, resourceVersion string) (watch.Interface, error) {
+	return client.KiagnoseV1alpha1().Checkups(namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector:   fields.OneTermEqualSelector("metadata.name", name).String(),
+		ResourceVersion: resourceVersion,
+	})
+}
+
+func UpdateStatus(client versioned.Interface, checkup *checkupv1alpha1.Checkup) (*checkupv1alpha1.Checkup, error) {
+	return client.KiagnoseV1alpha1().Checkups(checkup.Namespace).UpdateStatus(context.Background(), checkup, metav1.UpdateOptions{})
+}
@@ -0,0 +1,63 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kiagnose/kiagnose/kiagnose/artifacts"
+	"github.com/kiagnose/kiagnose/kiagnose/configmap"
+	"github.com/kiagnose/kiagnose/kiagnose/history"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+// Artifact writes the content of an artifact stored by the checkup.
+// A zero run number refers to the latest run, otherwise the artifact is read from the archived run.
+func Artifact(ctx context.Context, client kubernetes.Interface, namespace, name, artifactName string, run int, out io.Writer) error {
+	configMap, err := runConfigMap(client, namespace, name, run)
+	if err != nil {
+		return err
+	}
+
+	rawReference, exists := configMap.Data[types.ArtifactsPrefix+artifactName]
+	if !exists {
+		return fmt.Errorf("artifact %q was not found in %q", artifactName, configMap.Name)
+	}
+
+	content, err := artifacts.Read(ctx, client, namespace, rawReference)
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(content)
+	return err
+}
+
+func runConfigMap(client kubernetes.Interface, namespace, name string, run int) (*corev1.ConfigMap, error) {
+	if run == 0 {
+		return configmap.Get(client, namespace, name)
+	}
+	return configmap.Get(client, namespace, history.NameFor(name, run))
+}
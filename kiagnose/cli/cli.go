@@ -0,0 +1,211 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kiagnose/kiagnose/kiagnose/config"
+	"github.com/kiagnose/kiagnose/kiagnose/configmap"
+	"github.com/kiagnose/kiagnose/kiagnose/job"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+var (
+	ErrCheckupFailed      = errors.New("checkup failed")
+	ErrCheckupNotFinished = errors.New("checkup has not finished yet")
+)
+
+type RunOptions struct {
+	Namespace          string
+	Name               string
+	Image              string
+	ServiceAccountName string
+	Timeout            string
+	Params             map[string]string
+	Output             string
+	FollowLogs         bool
+}
+
+// NewConfigMap composes the user ConfigMap, rejecting an invalid configuration before anything is applied.
+func NewConfigMap(namespace, name, timeout string, params map[string]string) (*corev1.ConfigMap, error) {
+	data := map[string]string{types.TimeoutKey: timeout}
+	for k, v := range params {
+		data[types.ParamNameKeyPrefix+k] = v
+	}
+
+	if err := config.Validate(data); err != nil {
+		return nil, err
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Data: data,
+	}, nil
+}
+
+// ReadParamsFile reads checkup parameters from a YAML file holding a flat key-value map.
+func ReadParamsFile(path string) (map[string]string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]string{}
+	if err := yaml.UnmarshalStrict(raw, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse params file %q: %v", path, err)
+	}
+
+	return params, nil
+}
+
+// Run creates the checkup ConfigMap and Job, waits for the checkup to complete and prints its status.
+func Run(ctx context.Context, client kubernetes.Interface, opts RunOptions, out io.Writer) error {
+	if err := ValidateOutputFormat(opts.Output); err != nil {
+		return err
+	}
+
+	configMap, err := NewConfigMap(opts.Namespace, opts.Name, opts.Timeout, opts.Params)
+	if err != nil {
+		return err
+	}
+
+	checkupTimeout, err := time.ParseDuration(opts.Timeout)
+	if err != nil {
+		return err
+	}
+
+	if configMap, err = client.CoreV1().ConfigMaps(opts.Namespace).Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+
+	checkupJob, err := job.Create(client, job.New(configMap, job.NameFor(configMap.Name), opts.Image, opts.ServiceAccountName))
+	if err != nil {
+		// Remove the ConfigMap, so the checkup can be executed again under the same name.
+		if deleteErr := client.CoreV1().ConfigMaps(configMap.Namespace).Delete(ctx, configMap.Name, metav1.DeleteOptions{}); deleteErr != nil {
+			log.Printf("failed to delete ConfigMap %s/%s: %v", configMap.Namespace, configMap.Name, deleteErr)
+		}
+		return err
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, completionDeadline(checkupTimeout, checkupJob))
+	defer cancel()
+
+	if opts.FollowLogs {
+		if err := StreamLogs(waitCtx, client, checkupJob.Namespace, checkupJob.Name, out); err != nil {
+			log.Printf("failed to stream checkup logs: %v", err)
+		}
+	}
+
+	checkupStatus, err := WaitForCompletion(waitCtx, client, opts.Namespace, opts.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := PrintStatus(out, checkupStatus, opts.Output); err != nil {
+		return err
+	}
+
+	if !checkupStatus.Succeeded {
+		return ErrCheckupFailed
+	}
+
+	return nil
+}
+
+// completionDeadline returns how long to wait for the checkup to report its completion:
+// its timeout, which bounds all its attempts, followed by the termination grace period of its Job,
+// which covers its teardown, the storing of its artifacts and its completion report.
+// The checkup is allowed some time to be scheduled on top of these.
+func completionDeadline(checkupTimeout time.Duration, checkupJob *batchv1.Job) time.Duration {
+	const schedulingGracePeriod = 2 * time.Minute
+
+	deadline := checkupTimeout + schedulingGracePeriod
+	if gracePeriodSeconds := checkupJob.Spec.Template.Spec.TerminationGracePeriodSeconds; gracePeriodSeconds != nil {
+		deadline += time.Duration(*gracePeriodSeconds) * time.Second
+	}
+
+	return deadline
+}
+
+// Get prints the status of a previously executed checkup.
+func Get(client kubernetes.Interface, namespace, name, output string, out io.Writer) error {
+	if err := ValidateOutputFormat(output); err != nil {
+		return err
+	}
+
+	configMap, err := configmap.Get(client, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	checkupStatus, err := status.FromConfigMap(context.Background(), client, configMap)
+	if err != nil {
+		return err
+	}
+
+	if checkupStatus.CompletionTimestamp.IsZero() {
+		return ErrCheckupNotFinished
+	}
+
+	return PrintStatus(out, checkupStatus, output)
+}
+
+// WaitForCompletion waits for the checkup to report its completion timestamp.
+func WaitForCompletion(ctx context.Context, client kubernetes.Interface, namespace, name string) (status.Status, error) {
+	const pollInterval = 5 * time.Second
+
+	var checkupStatus status.Status
+	conditionFn := func(ctx context.Context) (bool, error) {
+		configMap, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		if checkupStatus, err = status.FromConfigMap(ctx, client, configMap); err != nil {
+			return false, err
+		}
+
+		return !checkupStatus.CompletionTimestamp.IsZero(), nil
+	}
+
+	if err := wait.PollImmediateUntilWithContext(ctx, pollInterval, conditionFn); err != nil {
+		return status.Status{}, fmt.Errorf("failed to wait for checkup %s/%s to complete: %v", namespace, name, err)
+	}
+
+	return checkupStatus, nil
+}
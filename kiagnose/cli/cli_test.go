@@ -0,0 +1,327 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kiagnose/kiagnose/kiagnose/artifacts"
+	"github.com/kiagnose/kiagnose/kiagnose/cli"
+	"github.com/kiagnose/kiagnose/kiagnose/config"
+	"github.com/kiagnose/kiagnose/kiagnose/history"
+	"github.com/kiagnose/kiagnose/kiagnose/internal/generatename"
+	"github.com/kiagnose/kiagnose/kiagnose/job"
+	"github.com/kiagnose/kiagnose/kiagnose/report"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const (
+	testNamespace     = "target-ns"
+	testConfigMapName = "checkup1"
+	testImage         = "my-registry/example-checkup:main"
+)
+
+func TestNewConfigMapShouldSucceed(t *testing.T) {
+	configMap, err := cli.NewConfigMap(testNamespace, testConfigMapName, "5m", map[string]string{"key1": "value1"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		types.TimeoutKey:                  "5m",
+		types.ParamNameKeyPrefix + "key1": "value1",
+	}, configMap.Data)
+}
+
+func TestNewConfigMapShouldFail(t *testing.T) {
+	t.Run("when timeout is illegal", func(t *testing.T) {
+		_, err := cli.NewConfigMap(testNamespace, testConfigMapName, "5 minutes", nil)
+		assert.ErrorIs(t, err, config.ErrTimeoutFieldIsIllegal)
+	})
+
+	t.Run("when param name is empty", func(t *testing.T) {
+		_, err := cli.NewConfigMap(testNamespace, testConfigMapName, "5m", map[string]string{"": "value"})
+		assert.ErrorIs(t, err, config.ErrParamNameIsIllegal)
+	})
+}
+
+func TestRunShould(t *testing.T) {
+	t.Run("create the ConfigMap and Job and print the results", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset()
+		injectCompletion(fakeClient, true)
+
+		var out bytes.Buffer
+		assert.NoError(t, cli.Run(context.Background(), fakeClient, newRunOptions(cli.OutputJSON), &out))
+
+		_, err := fakeClient.BatchV1().Jobs(testNamespace).Get(context.Background(), job.NameFor(testConfigMapName), metav1.GetOptions{})
+		assert.NoError(t, err)
+
+		var printed map[string]string
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &printed))
+		assert.Equal(t, "true", printed[types.SucceededKey])
+		assert.Equal(t, "value1", printed[types.ResultsPrefix+"key1"])
+	})
+
+	t.Run("fail when the checkup fails", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset()
+		injectCompletion(fakeClient, false)
+
+		var out bytes.Buffer
+		assert.ErrorIs(t, cli.Run(context.Background(), fakeClient, newRunOptions(cli.OutputTable), &out), cli.ErrCheckupFailed)
+		assert.Contains(t, out.String(), "some reason")
+	})
+
+	t.Run("delete the ConfigMap when the Job creation fails", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset()
+		expectedErr := errors.New("failed to create Job")
+		fakeClient.PrependReactor("create", "jobs", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, expectedErr
+		})
+
+		assert.ErrorIs(t, cli.Run(context.Background(), fakeClient, newRunOptions(cli.OutputTable), &bytes.Buffer{}), expectedErr)
+
+		_, err := fakeClient.CoreV1().ConfigMaps(testNamespace).Get(context.Background(), testConfigMapName, metav1.GetOptions{})
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+
+	t.Run("reject invalid input before applying anything", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset()
+		opts := newRunOptions(cli.OutputTable)
+		opts.Timeout = "illegal"
+
+		assert.Error(t, cli.Run(context.Background(), fakeClient, opts, &bytes.Buffer{}))
+		assert.Empty(t, fakeClient.Actions())
+	})
+}
+
+func TestGetShould(t *testing.T) {
+	t.Run("print the results of a finished checkup", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newConfigMap(completedData(true)))
+
+		var out bytes.Buffer
+		assert.NoError(t, cli.Get(fakeClient, testNamespace, testConfigMapName, cli.OutputTable, &out))
+		assert.Contains(t, out.String(), types.SucceededKey)
+		assert.Contains(t, out.String(), types.ResultsPrefix+"key1")
+	})
+
+	t.Run("fail when the checkup has not finished", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newConfigMap(map[string]string{types.TimeoutKey: "5m"}))
+
+		assert.ErrorIs(t, cli.Get(fakeClient, testNamespace, testConfigMapName, cli.OutputTable, &bytes.Buffer{}), cli.ErrCheckupNotFinished)
+	})
+
+	t.Run("fail when the output format is not supported", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newConfigMap(completedData(true)))
+
+		assert.Error(t, cli.Get(fakeClient, testNamespace, testConfigMapName, "yaml", &bytes.Buffer{}))
+	})
+}
+
+func TestHistoryShould(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		newHistoryEntry(2, completedData(false)),
+		newHistoryEntry(1, completedData(true)),
+	)
+
+	t.Run("print the archived runs as a table", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, cli.History(fakeClient, testNamespace, testConfigMapName, cli.OutputTable, &out))
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Len(t, lines, 3)
+		assert.True(t, strings.HasPrefix(lines[1], "1 "))
+		assert.True(t, strings.HasPrefix(lines[2], "2 "))
+		assert.Contains(t, lines[2], "some reason")
+	})
+
+	t.Run("print the archived runs as JSON", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, cli.History(fakeClient, testNamespace, testConfigMapName, cli.OutputJSON, &out))
+
+		var runs []map[string]string
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &runs))
+		assert.Len(t, runs, 2)
+		assert.Equal(t, "1", runs[0]["run"])
+		assert.Equal(t, "true", runs[0][types.SucceededKey])
+		assert.Equal(t, "false", runs[1][types.SucceededKey])
+	})
+}
+
+func TestArtifactShould(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	generatename.Emulate(fakeClient)
+	store := artifacts.NewStore(fakeClient, testNamespace, testConfigMapName, "")
+	references, err := store.Store(context.Background(), []artifacts.Artifact{
+		{Name: "latest.log", Content: []byte("latest run")},
+		{Name: "archived.log", Content: []byte("archived run")},
+	})
+	assert.NoError(t, err)
+
+	latestData := completedData(true)
+	latestData[types.ArtifactsPrefix+"latest.log"] = references["latest.log"]
+	_, err = fakeClient.CoreV1().ConfigMaps(testNamespace).Create(context.Background(), newConfigMap(latestData), metav1.CreateOptions{})
+	assert.NoError(t, err)
+	archivedData := completedData(true)
+	archivedData[types.ArtifactsPrefix+"archived.log"] = references["archived.log"]
+	_, err = fakeClient.CoreV1().ConfigMaps(testNamespace).Create(context.Background(), newHistoryEntry(1, archivedData), metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	t.Run("print an artifact of the latest run", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, cli.Artifact(context.Background(), fakeClient, testNamespace, testConfigMapName, "latest.log", 0, &out))
+		assert.Equal(t, "latest run", out.String())
+	})
+
+	t.Run("print an artifact of an archived run", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, cli.Artifact(context.Background(), fakeClient, testNamespace, testConfigMapName, "archived.log", 1, &out))
+		assert.Equal(t, "archived run", out.String())
+	})
+
+	t.Run("fail when the artifact does not exist", func(t *testing.T) {
+		err := cli.Artifact(context.Background(), fakeClient, testNamespace, testConfigMapName, "archived.log", 0, &bytes.Buffer{})
+		assert.ErrorContains(t, err, "not found")
+	})
+}
+
+func TestReportShould(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(newConfigMap(completedData(true)), newHistoryEntry(1, completedData(false)))
+
+	t.Run("write the report of the latest run", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, cli.Report(context.Background(), fakeClient, testNamespace, testConfigMapName, 0, report.FormatJSON, &out))
+
+		var r report.Report
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &r))
+		assert.Equal(t, testConfigMapName, r.Name)
+		assert.True(t, r.Succeeded)
+		assert.Equal(t, []report.Entry{{Name: "key1", Value: "value1"}}, r.Results)
+	})
+
+	t.Run("write the report of an archived run", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, cli.Report(context.Background(), fakeClient, testNamespace, testConfigMapName, 1, report.FormatJUnit, &out))
+
+		assert.Contains(t, out.String(), `<testsuite name="`+testConfigMapName+`" tests="5" failures="1" skipped="4"`)
+	})
+
+	t.Run("fail on unknown format", func(t *testing.T) {
+		err := cli.Report(context.Background(), fakeClient, testNamespace, testConfigMapName, 0, "yaml", &bytes.Buffer{})
+		assert.ErrorContains(t, err, "not supported")
+	})
+}
+
+func TestWriteReportShouldReadSavedConfigMap(t *testing.T) {
+	raw, err := yaml.Marshal(newConfigMap(completedData(true)))
+	assert.NoError(t, err)
+	configMapFile := filepath.Join(t.TempDir(), "checkup.yaml")
+	assert.NoError(t, os.WriteFile(configMapFile, raw, 0o600))
+
+	configMap, err := cli.ReadConfigMapFile(configMapFile)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	assert.NoError(t, cli.WriteReport(context.Background(), nil, configMap, report.FormatJUnit, &out))
+	assert.Contains(t, out.String(), `<property name="result.key1" value="value1"></property>`)
+}
+
+func TestReadParamsFile(t *testing.T) {
+	paramsFile := filepath.Join(t.TempDir(), "params.yaml")
+	assert.NoError(t, os.WriteFile(paramsFile, []byte("key1: value1\nkey2: \"2\"\n"), 0o600))
+
+	params, err := cli.ReadParamsFile(paramsFile)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"key1": "value1", "key2": "2"}, params)
+}
+
+func newRunOptions(output string) cli.RunOptions {
+	return cli.RunOptions{
+		Namespace: testNamespace,
+		Name:      testConfigMapName,
+		Image:     testImage,
+		Timeout:   "5m",
+		Params:    map[string]string{"param1": "value1"},
+		Output:    output,
+	}
+}
+
+// injectCompletion simulates a checkup which has already reported its completion.
+func injectCompletion(fakeClient *fake.Clientset, succeeded bool) {
+	fakeClient.PrependReactor("get", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, newConfigMap(completedData(succeeded)), nil
+	})
+}
+
+func completedData(succeeded bool) map[string]string {
+	data := map[string]string{
+		types.TimeoutKey:             "5m",
+		types.StartTimestampKey:      time.Now().Format(time.RFC3339),
+		types.CompletionTimestampKey: time.Now().Format(time.RFC3339),
+		types.SucceededKey:           "true",
+		types.FailureReasonKey:       "",
+		types.ResultsPrefix + "key1": "value1",
+	}
+	if !succeeded {
+		data[types.SucceededKey] = "false"
+		data[types.FailureReasonKey] = "some reason"
+	}
+	return data
+}
+
+func newHistoryEntry(runNumber int, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      history.NameFor(testConfigMapName, runNumber),
+			Namespace: testNamespace,
+			Labels: map[string]string{
+				types.HistoryOfLabel: testConfigMapName,
+				types.RunNumberLabel: strconv.Itoa(runNumber),
+			},
+		},
+		Data: data,
+	}
+}
+
+func newConfigMap(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testConfigMapName,
+			Namespace: testNamespace,
+		},
+		Data: data,
+	}
+}
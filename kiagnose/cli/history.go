@@ -0,0 +1,95 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kiagnose/kiagnose/kiagnose/history"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const runKey = "run"
+
+// History prints the status of the archived runs of a re-runnable checkup, from the oldest to the latest.
+func History(client kubernetes.Interface, namespace, name, output string, out io.Writer) error {
+	if err := ValidateOutputFormat(output); err != nil {
+		return err
+	}
+
+	entries, err := history.List(client, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	runs := make([]map[string]string, 0, len(entries))
+	for i := range entries {
+		runStatus, err := status.FromConfigMap(context.Background(), client, &entries[i])
+		if err != nil {
+			return fmt.Errorf("run %d: %v", history.RunNumber(&entries[i]), err)
+		}
+
+		fields := statusFields(runStatus)
+		fields[runKey] = strconv.Itoa(history.RunNumber(&entries[i]))
+		runs = append(runs, fields)
+	}
+
+	if output == OutputJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(runs)
+	}
+
+	return printHistoryTable(out, runs)
+}
+
+func printHistoryTable(out io.Writer, runs []map[string]string) error {
+	const (
+		minWidth = 0
+		tabWidth = 8
+		padding  = 2
+	)
+	w := tabwriter.NewWriter(out, minWidth, tabWidth, padding, ' ', 0)
+	fmt.Fprintln(w, "RUN\tSUCCEEDED\tSTARTED\tDURATION\tFAILURE REASON")
+	for _, run := range runs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			run[runKey], run[types.SucceededKey], run[types.StartTimestampKey], runDuration(run), run[types.FailureReasonKey])
+	}
+
+	return w.Flush()
+}
+
+func runDuration(run map[string]string) string {
+	start, startErr := time.Parse(time.RFC3339, run[types.StartTimestampKey])
+	completion, completionErr := time.Parse(time.RFC3339, run[types.CompletionTimestampKey])
+	if startErr != nil || completionErr != nil {
+		return ""
+	}
+	return completion.Sub(start).String()
+}
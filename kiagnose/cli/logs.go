@@ -0,0 +1,76 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// StreamLogs follows the logs of the checkup Job pod until the checkup container terminates.
+func StreamLogs(ctx context.Context, client kubernetes.Interface, namespace, jobName string, out io.Writer) error {
+	pod, err := waitForJobPod(ctx, client, namespace, jobName)
+	if err != nil {
+		return err
+	}
+
+	stream, err := client.CoreV1().Pods(namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Follow: true}).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	_, err = io.Copy(out, stream)
+	return err
+}
+
+func waitForJobPod(ctx context.Context, client kubernetes.Interface, namespace, jobName string) (*corev1.Pod, error) {
+	const pollInterval = 2 * time.Second
+
+	var pod *corev1.Pod
+	conditionFn := func(ctx context.Context) (bool, error) {
+		pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + jobName})
+		if err != nil {
+			return false, err
+		}
+
+		for i := range pods.Items {
+			if pods.Items[i].Status.Phase != corev1.PodPending {
+				pod = &pods.Items[i]
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+
+	if err := wait.PollImmediateUntilWithContext(ctx, pollInterval, conditionFn); err != nil {
+		return nil, fmt.Errorf("failed to wait for checkup job %s/%s pod to start: %v", namespace, jobName, err)
+	}
+
+	return pod, nil
+}
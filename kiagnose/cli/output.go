@@ -0,0 +1,156 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+func ValidateOutputFormat(output string) error {
+	if output != OutputTable && output != OutputJSON {
+		return fmt.Errorf("output format %q is not supported, use %q or %q", output, OutputTable, OutputJSON)
+	}
+	return nil
+}
+
+// PrintStatus prints the checkup status using the ConfigMap keys, either as a table or as JSON.
+func PrintStatus(out io.Writer, checkupStatus status.Status, output string) error {
+	switch output {
+	case OutputJSON:
+		return printJSON(out, checkupStatus)
+	case OutputTable:
+		return printTable(out, checkupStatus)
+	default:
+		return ValidateOutputFormat(output)
+	}
+}
+
+func printJSON(out io.Writer, checkupStatus status.Status) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(statusFields(checkupStatus))
+}
+
+func printTable(out io.Writer, checkupStatus status.Status) error {
+	fields := statusFields(checkupStatus)
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return fieldOrder(keys[i]) < fieldOrder(keys[j]) ||
+			fieldOrder(keys[i]) == fieldOrder(keys[j]) && keys[i] < keys[j]
+	})
+
+	const (
+		minWidth = 0
+		tabWidth = 8
+		padding  = 2
+	)
+	w := tabwriter.NewWriter(out, minWidth, tabWidth, padding, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE")
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s\t%s\n", k, fields[k])
+	}
+
+	return w.Flush()
+}
+
+func statusFields(checkupStatus status.Status) map[string]string {
+	fields := map[string]string{
+		types.SucceededKey:     strconv.FormatBool(checkupStatus.Succeeded),
+		types.FailureReasonKey: strings.Join(checkupStatus.FailureReason, ","),
+	}
+
+	if len(checkupStatus.FailureDetails) > 0 {
+		if failureDetails, err := json.Marshal(checkupStatus.FailureDetails); err == nil {
+			fields[types.FailureDetailsKey] = string(failureDetails)
+		}
+	}
+
+	if !checkupStatus.StartTimestamp.IsZero() {
+		fields[types.StartTimestampKey] = checkupStatus.StartTimestamp.Format(time.RFC3339)
+	}
+
+	if !checkupStatus.CompletionTimestamp.IsZero() {
+		fields[types.CompletionTimestampKey] = checkupStatus.CompletionTimestamp.Format(time.RFC3339)
+	}
+
+	if checkupStatus.Phase != "" {
+		fields[types.PhaseKey] = string(checkupStatus.Phase)
+		fields[types.ProgressKey] = checkupStatus.Progress
+	}
+
+	if !checkupStatus.LastHeartbeat.IsZero() {
+		fields[types.LastHeartbeatKey] = checkupStatus.LastHeartbeat.Format(time.RFC3339)
+	}
+
+	for k, v := range checkupStatus.Results {
+		fields[types.ResultsPrefix+k] = v
+	}
+
+	for k, v := range checkupStatus.Artifacts {
+		fields[types.ArtifactsPrefix+k] = v
+	}
+
+	if len(checkupStatus.Attempts) > 0 {
+		if attempts, err := json.Marshal(checkupStatus.Attempts); err == nil {
+			fields[types.AttemptsKey] = string(attempts)
+		}
+	}
+
+	return fields
+}
+
+// fieldOrder lists the core status fields first, followed by the checkup results.
+func fieldOrder(key string) int {
+	order := []string{
+		types.PhaseKey,
+		types.ProgressKey,
+		types.SucceededKey,
+		types.FailureReasonKey,
+		types.FailureDetailsKey,
+		types.StartTimestampKey,
+		types.CompletionTimestampKey,
+		types.LastHeartbeatKey,
+	}
+	for i, k := range order {
+		if k == key {
+			return i
+		}
+	}
+	return len(order)
+}
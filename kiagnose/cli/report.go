@@ -0,0 +1,91 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kiagnose/kiagnose/kiagnose/report"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+// Report writes the report of a completed checkup, in one of the report formats.
+// A zero run number refers to the latest run, otherwise the report describes the archived run.
+func Report(ctx context.Context, client kubernetes.Interface, namespace, name string, run int, format string, out io.Writer) error {
+	if err := report.ValidateFormat(format); err != nil {
+		return err
+	}
+
+	configMap, err := runConfigMap(client, namespace, name, run)
+	if err != nil {
+		return err
+	}
+
+	return WriteReport(ctx, client, configMap, format, out)
+}
+
+// WriteReport writes the report of the checkup which reported its status to the given ConfigMap,
+// which may also be an archived run of the checkup.
+// The client is only used to read the results spilled into overflow ConfigMaps, and may be nil otherwise.
+func WriteReport(ctx context.Context, client kubernetes.Interface, configMap *corev1.ConfigMap, format string, out io.Writer) error {
+	if err := report.ValidateFormat(format); err != nil {
+		return err
+	}
+
+	checkupStatus, err := status.FromConfigMap(ctx, client, configMap)
+	if err != nil {
+		return err
+	}
+
+	name := configMap.Name
+	if historyOf, archived := configMap.Labels[types.HistoryOfLabel]; archived {
+		name = historyOf
+	}
+
+	r, err := report.New(name, checkupStatus)
+	if err != nil {
+		return err
+	}
+
+	return r.Write(out, format)
+}
+
+// ReadConfigMapFile reads a ConfigMap saved as YAML or JSON, e.g. using "kubectl get configmap -o yaml".
+func ReadConfigMapFile(path string) (*corev1.ConfigMap, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := yaml.Unmarshal(raw, configMap); err != nil {
+		return nil, fmt.Errorf("failed to parse ConfigMap file %q: %v", path, err)
+	}
+
+	return configMap, nil
+}
@@ -0,0 +1,132 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	checkupv1alpha1 "github.com/kiagnose/kiagnose/kiagnose/apis/checkup/v1alpha1"
+	"github.com/kiagnose/kiagnose/kiagnose/checkup"
+	"github.com/kiagnose/kiagnose/kiagnose/client/clientset/versioned"
+	"github.com/kiagnose/kiagnose/kiagnose/criteria"
+)
+
+var ErrCheckupIsAlreadyInUse = errors.New("checkup is already in use")
+
+// ReadFromCheckup reads the configuration from a Checkup custom resource,
+// as an alternative to the ConfigMap based user API used by Read.
+func ReadFromCheckup(client versioned.Interface, rawEnv map[string]string) (Config, error) {
+	env := newCheckupEnvironment(rawEnv)
+
+	if err := env.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	checkupObj, err := checkup.Get(client, env.CheckupNamespace, env.CheckupName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	if checkupObj.Status.StartTimestamp != nil {
+		return Config{}, ErrCheckupIsAlreadyInUse
+	}
+
+	if err := validateCheckupSpec(checkupObj.Spec); err != nil {
+		return Config{}, err
+	}
+
+	params := map[string]string{}
+	for k, v := range checkupObj.Spec.Params {
+		params[k] = v
+	}
+
+	var successCriteria *criteria.Expression
+	if checkupObj.Spec.SuccessCriteria != "" {
+		if successCriteria, err = criteria.Parse(checkupObj.Spec.SuccessCriteria); err != nil {
+			return Config{}, fmt.Errorf("%w: %v", ErrSuccessCriteriaFieldIsIllegal, err)
+		}
+	}
+
+	return Config{
+		CheckupNamespace: env.CheckupNamespace,
+		CheckupName:      env.CheckupName,
+		PodName:          env.PodName,
+		PodUID:           env.PodUID,
+		UID:              string(checkupObj.UID),
+		Timeout:          checkupObj.Spec.Timeout.Duration,
+		SetupTimeout:     optionalDuration(checkupObj.Spec.SetupTimeout),
+		RunTimeout:       optionalDuration(checkupObj.Spec.RunTimeout),
+		TeardownTimeout:  optionalDuration(checkupObj.Spec.TeardownTimeout),
+		Params:           params,
+		SuccessCriteria:  successCriteria,
+		Retries:          checkupObj.Spec.Retries,
+		RetryBackoff:     optionalDuration(checkupObj.Spec.RetryBackoff),
+	}, nil
+}
+
+func validateCheckupSpec(spec checkupv1alpha1.CheckupSpec) error {
+	if spec.Timeout.Duration == 0 {
+		return ErrTimeoutFieldIsMissing
+	}
+
+	if spec.Timeout.Duration < 0 {
+		return ErrTimeoutFieldIsIllegal
+	}
+
+	optionalDurations := []struct {
+		duration *metav1.Duration
+		err      error
+	}{
+		{spec.SetupTimeout, ErrSetupTimeoutFieldIsIllegal},
+		{spec.RunTimeout, ErrRunTimeoutFieldIsIllegal},
+		{spec.TeardownTimeout, ErrTeardownTimeoutFieldIsIllegal},
+		{spec.RetryBackoff, ErrRetryBackoffFieldIsIllegal},
+	}
+
+	for _, optional := range optionalDurations {
+		if optional.duration != nil && optional.duration.Duration <= 0 {
+			return optional.err
+		}
+	}
+
+	if spec.Retries < 0 {
+		return ErrRetriesFieldIsIllegal
+	}
+
+	for paramName := range spec.Params {
+		if paramName == "" {
+			return ErrParamNameIsIllegal
+		}
+	}
+
+	return nil
+}
+
+// optionalDuration returns the given duration, or zero when it is not set.
+func optionalDuration(duration *metav1.Duration) time.Duration {
+	if duration == nil {
+		return 0
+	}
+	return duration.Duration
+}
@@ -0,0 +1,221 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	checkupv1alpha1 "github.com/kiagnose/kiagnose/kiagnose/apis/checkup/v1alpha1"
+	"github.com/kiagnose/kiagnose/kiagnose/client/clientset/versioned/fake"
+	"github.com/kiagnose/kiagnose/kiagnose/config"
+	"github.com/kiagnose/kiagnose/kiagnose/criteria"
+)
+
+const (
+	checkupNamespace = "target-ns"
+	checkupName      = "checkup1"
+	checkupUID       = "9876543210"
+)
+
+var validCheckupRawEnv = map[string]string{
+	config.CheckupNamespaceEnvVarName: checkupNamespace,
+	config.CheckupNameEnvVarName:      checkupName,
+	config.PodNameEnvVarName:          podName,
+	config.PodUIDEnvVarName:           podUID,
+}
+
+func TestReadFromCheckupShouldSucceed(t *testing.T) {
+	spec := checkupv1alpha1.CheckupSpec{
+		Timeout: metav1.Duration{Duration: time.Minute},
+		Params: map[string]string{
+			param1Key: param1Value,
+			param2Key: param2Value,
+		},
+	}
+	fakeClient := fake.NewSimpleClientset(newCheckup(spec, checkupv1alpha1.CheckupStatus{}))
+
+	actualConfig, err := config.ReadFromCheckup(fakeClient, validCheckupRawEnv)
+	assert.NoError(t, err)
+
+	expectedConfig := config.Config{
+		CheckupNamespace: checkupNamespace,
+		CheckupName:      checkupName,
+		PodName:          podName,
+		PodUID:           podUID,
+		UID:              checkupUID,
+		Timeout:          time.Minute,
+		Params: map[string]string{
+			param1Key: param1Value,
+			param2Key: param2Value,
+		},
+	}
+	assert.Equal(t, expectedConfig, actualConfig)
+}
+
+func TestReadFromCheckupShouldSucceedWithOptionalFields(t *testing.T) {
+	const successCriteria = "maxLatencyNanoSec < 2000000"
+	spec := checkupv1alpha1.CheckupSpec{
+		Timeout:         metav1.Duration{Duration: time.Hour},
+		SetupTimeout:    &metav1.Duration{Duration: time.Minute},
+		RunTimeout:      &metav1.Duration{Duration: 30 * time.Minute},
+		TeardownTimeout: &metav1.Duration{Duration: 2 * time.Minute},
+		Retries:         2,
+		RetryBackoff:    &metav1.Duration{Duration: 10 * time.Second},
+		SuccessCriteria: successCriteria,
+	}
+	fakeClient := fake.NewSimpleClientset(newCheckup(spec, checkupv1alpha1.CheckupStatus{}))
+
+	actualConfig, err := config.ReadFromCheckup(fakeClient, validCheckupRawEnv)
+	assert.NoError(t, err)
+
+	expectedSuccessCriteria, err := criteria.Parse(successCriteria)
+	assert.NoError(t, err)
+
+	assert.Equal(t, time.Minute, actualConfig.SetupTimeout)
+	assert.Equal(t, 30*time.Minute, actualConfig.RunTimeout)
+	assert.Equal(t, 2*time.Minute, actualConfig.TeardownTimeout)
+	assert.Equal(t, 2, actualConfig.Retries)
+	assert.Equal(t, 10*time.Second, actualConfig.RetryBackoff)
+	assert.Equal(t, expectedSuccessCriteria, actualConfig.SuccessCriteria)
+}
+
+func TestReadFromCheckupShouldFail(t *testing.T) {
+	t.Run("when Checkup doesn't exist", func(t *testing.T) {
+		_, err := config.ReadFromCheckup(fake.NewSimpleClientset(), validCheckupRawEnv)
+		assert.ErrorContains(t, err, "not found")
+	})
+
+	t.Run("when Checkup's name environment variable is missing", func(t *testing.T) {
+		_, err := config.ReadFromCheckup(fake.NewSimpleClientset(), map[string]string{
+			config.CheckupNamespaceEnvVarName: checkupNamespace,
+		})
+		assert.ErrorIs(t, err, config.ErrMissingCheckupName)
+	})
+
+	startTimestamp := metav1.Now()
+
+	type readFailureTestCase struct {
+		description   string
+		spec          checkupv1alpha1.CheckupSpec
+		status        checkupv1alpha1.CheckupStatus
+		expectedError error
+	}
+
+	failureTestCases := []readFailureTestCase{
+		{
+			description:   "when Checkup is already in use",
+			spec:          checkupv1alpha1.CheckupSpec{Timeout: metav1.Duration{Duration: time.Minute}},
+			status:        checkupv1alpha1.CheckupStatus{StartTimestamp: &startTimestamp},
+			expectedError: config.ErrCheckupIsAlreadyInUse,
+		},
+		{
+			description:   "when timeout field is missing",
+			spec:          checkupv1alpha1.CheckupSpec{},
+			expectedError: config.ErrTimeoutFieldIsMissing,
+		},
+		{
+			description:   "when timeout field is negative",
+			spec:          checkupv1alpha1.CheckupSpec{Timeout: metav1.Duration{Duration: -time.Minute}},
+			expectedError: config.ErrTimeoutFieldIsIllegal,
+		},
+		{
+			description: "when param name is empty",
+			spec: checkupv1alpha1.CheckupSpec{
+				Timeout: metav1.Duration{Duration: time.Minute},
+				Params:  map[string]string{"": "some value"},
+			},
+			expectedError: config.ErrParamNameIsIllegal,
+		},
+		{
+			description: "when setup timeout field is not positive",
+			spec: checkupv1alpha1.CheckupSpec{
+				Timeout:      metav1.Duration{Duration: time.Minute},
+				SetupTimeout: &metav1.Duration{},
+			},
+			expectedError: config.ErrSetupTimeoutFieldIsIllegal,
+		},
+		{
+			description: "when run timeout field is negative",
+			spec: checkupv1alpha1.CheckupSpec{
+				Timeout:    metav1.Duration{Duration: time.Minute},
+				RunTimeout: &metav1.Duration{Duration: -time.Minute},
+			},
+			expectedError: config.ErrRunTimeoutFieldIsIllegal,
+		},
+		{
+			description: "when teardown timeout field is negative",
+			spec: checkupv1alpha1.CheckupSpec{
+				Timeout:         metav1.Duration{Duration: time.Minute},
+				TeardownTimeout: &metav1.Duration{Duration: -time.Minute},
+			},
+			expectedError: config.ErrTeardownTimeoutFieldIsIllegal,
+		},
+		{
+			description: "when retries field is negative",
+			spec: checkupv1alpha1.CheckupSpec{
+				Timeout: metav1.Duration{Duration: time.Minute},
+				Retries: -1,
+			},
+			expectedError: config.ErrRetriesFieldIsIllegal,
+		},
+		{
+			description: "when retry backoff field is not positive",
+			spec: checkupv1alpha1.CheckupSpec{
+				Timeout:      metav1.Duration{Duration: time.Minute},
+				RetryBackoff: &metav1.Duration{},
+			},
+			expectedError: config.ErrRetryBackoffFieldIsIllegal,
+		},
+		{
+			description: "when success criteria field is illegal",
+			spec: checkupv1alpha1.CheckupSpec{
+				Timeout:         metav1.Duration{Duration: time.Minute},
+				SuccessCriteria: "maxLatencyNanoSec <",
+			},
+			expectedError: config.ErrSuccessCriteriaFieldIsIllegal,
+		},
+	}
+
+	for _, testCase := range failureTestCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset(newCheckup(testCase.spec, testCase.status))
+
+			_, err := config.ReadFromCheckup(fakeClient, validCheckupRawEnv)
+			assert.ErrorIs(t, err, testCase.expectedError)
+		})
+	}
+}
+
+func newCheckup(spec checkupv1alpha1.CheckupSpec, status checkupv1alpha1.CheckupStatus) *checkupv1alpha1.Checkup {
+	return &checkupv1alpha1.Checkup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      checkupName,
+			Namespace: checkupNamespace,
+			UID:       checkupUID,
+		},
+		Spec:   spec,
+		Status: status,
+	}
+}
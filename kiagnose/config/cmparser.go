@@ -21,9 +21,18 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/kiagnose/kiagnose/kiagnose/baseline"
+	"github.com/kiagnose/kiagnose/kiagnose/criteria"
+	"github.com/kiagnose/kiagnose/kiagnose/history"
+	"github.com/kiagnose/kiagnose/kiagnose/sink"
 	"github.com/kiagnose/kiagnose/kiagnose/types"
 )
 
@@ -31,18 +40,59 @@ var (
 	ErrTimeoutFieldIsMissing = errors.New("timeout field is missing")
 	ErrTimeoutFieldIsIllegal = errors.New("timeout field is illegal")
 	ErrParamNameIsIllegal    = errors.New("param name is illegal")
+
+	ErrSuccessCriteriaFieldIsIllegal = errors.New("success criteria field is illegal")
+
+	ErrBaselineConfigMapFieldIsIllegal = errors.New("baseline ConfigMap field is illegal")
+	ErrBaselineToleranceFieldIsIllegal = errors.New("baseline tolerance field is illegal")
+
+	ErrSetupTimeoutFieldIsIllegal    = errors.New("setup timeout field is illegal")
+	ErrRunTimeoutFieldIsIllegal      = errors.New("run timeout field is illegal")
+	ErrTeardownTimeoutFieldIsIllegal = errors.New("teardown timeout field is illegal")
+
+	ErrRerunnableFieldIsIllegal   = errors.New("rerunnable field is illegal")
+	ErrHistoryLimitFieldIsIllegal = errors.New("history limit field is illegal")
+
+	ErrRetriesFieldIsIllegal      = errors.New("retries field is illegal")
+	ErrRetryBackoffFieldIsIllegal = errors.New("retry backoff field is illegal")
+
+	ErrSinkJSONFileFieldIsIllegal           = errors.New("sink json file field is illegal")
+	ErrSinkTerminationMessageFieldIsIllegal = errors.New("sink termination message field is illegal")
+	ErrSinkWebhookFieldIsIllegal            = errors.New("sink webhook field is illegal")
+	ErrSinkJUnitFileFieldIsIllegal          = errors.New("sink junit file field is illegal")
+	ErrSinkReportFileFieldIsIllegal         = errors.New("sink report file field is illegal")
+	ErrSinkFieldIsUnknown                   = errors.New("sink field is unknown")
 )
 
+// Validate checks that the given ConfigMap data is a valid checkup configuration,
+// allowing clients to reject invalid input before applying it.
+func Validate(configMapData map[string]string) error {
+	return newConfigMapParser(configMapData).Parse()
+}
+
 type configMapParser struct {
-	configMapRawData map[string]string
-	Timeout          time.Duration
-	Params           map[string]string
+	configMapRawData   map[string]string
+	Timeout            time.Duration
+	SetupTimeout       time.Duration
+	RunTimeout         time.Duration
+	TeardownTimeout    time.Duration
+	Params             map[string]string
+	SuccessCriteria    *criteria.Expression
+	BaselineNamespace  string
+	BaselineName       string
+	BaselineTolerances map[string]baseline.Tolerance
+	Rerunnable         bool
+	HistoryLimit       int
+	Retries            int
+	RetryBackoff       time.Duration
+	Sinks              sink.Settings
 }
 
 func newConfigMapParser(configMapRawData map[string]string) *configMapParser {
 	return &configMapParser{
 		configMapRawData: configMapRawData,
 		Params:           map[string]string{},
+		HistoryLimit:     history.DefaultLimit,
 	}
 }
 
@@ -51,10 +101,34 @@ func (cmp *configMapParser) Parse() error {
 		return err
 	}
 
+	if err := cmp.parsePhaseTimeoutFields(); err != nil {
+		return err
+	}
+
 	if err := cmp.parseParamsField(); err != nil {
 		return err
 	}
 
+	if err := cmp.parseSuccessCriteriaField(); err != nil {
+		return err
+	}
+
+	if err := cmp.parseBaselineFields(); err != nil {
+		return err
+	}
+
+	if err := cmp.parseRerunFields(); err != nil {
+		return err
+	}
+
+	if err := cmp.parseRetryFields(); err != nil {
+		return err
+	}
+
+	if err := cmp.parseSinkFields(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -73,6 +147,34 @@ func (cmp *configMapParser) parseTimeoutField() error {
 	return nil
 }
 
+// parsePhaseTimeoutFields parses the optional per-phase timeouts, which are left zero when not set.
+func (cmp *configMapParser) parsePhaseTimeoutFields() error {
+	phaseTimeouts := []struct {
+		key     string
+		timeout *time.Duration
+		err     error
+	}{
+		{types.SetupTimeoutKey, &cmp.SetupTimeout, ErrSetupTimeoutFieldIsIllegal},
+		{types.RunTimeoutKey, &cmp.RunTimeout, ErrRunTimeoutFieldIsIllegal},
+		{types.TeardownTimeoutKey, &cmp.TeardownTimeout, ErrTeardownTimeoutFieldIsIllegal},
+	}
+
+	for _, phaseTimeout := range phaseTimeouts {
+		rawTimeout, exists := cmp.configMapRawData[phaseTimeout.key]
+		if !exists {
+			continue
+		}
+
+		timeout, err := time.ParseDuration(rawTimeout)
+		if err != nil || timeout <= 0 {
+			return phaseTimeout.err
+		}
+		*phaseTimeout.timeout = timeout
+	}
+
+	return nil
+}
+
 func (cmp *configMapParser) parseParamsField() error {
 	for k, v := range cmp.configMapRawData {
 		if strings.HasPrefix(k, types.ParamNameKeyPrefix) {
@@ -87,3 +189,171 @@ func (cmp *configMapParser) parseParamsField() error {
 
 	return nil
 }
+
+func (cmp *configMapParser) parseSuccessCriteriaField() error {
+	rawSuccessCriteria, exists := cmp.configMapRawData[types.SuccessCriteriaKey]
+	if !exists {
+		return nil
+	}
+
+	var err error
+	if cmp.SuccessCriteria, err = criteria.Parse(rawSuccessCriteria); err != nil {
+		return fmt.Errorf("%w: %v", ErrSuccessCriteriaFieldIsIllegal, err)
+	}
+
+	return nil
+}
+
+// parseBaselineFields parses the baseline ConfigMap reference, given as name or namespace/name,
+// and the tolerances of the results compared with it.
+func (cmp *configMapParser) parseBaselineFields() error {
+	if rawReference, exists := cmp.configMapRawData[types.BaselineConfigMapKey]; exists {
+		const maxElementsCount = 2
+
+		elements := strings.SplitN(rawReference, "/", maxElementsCount)
+		for _, element := range elements {
+			if element == "" {
+				return ErrBaselineConfigMapFieldIsIllegal
+			}
+		}
+
+		cmp.BaselineName = elements[len(elements)-1]
+		if len(elements) == maxElementsCount {
+			cmp.BaselineNamespace = elements[0]
+		}
+	}
+
+	for k, v := range cmp.configMapRawData {
+		if !strings.HasPrefix(k, types.BaselineTolerancePrefix) {
+			continue
+		}
+
+		resultName := strings.TrimPrefix(k, types.BaselineTolerancePrefix)
+		tolerance, err := baseline.ParseTolerance(v)
+		if resultName == "" || err != nil || cmp.BaselineName == "" {
+			return fmt.Errorf("%w: %q", ErrBaselineToleranceFieldIsIllegal, k)
+		}
+
+		if cmp.BaselineTolerances == nil {
+			cmp.BaselineTolerances = map[string]baseline.Tolerance{}
+		}
+		cmp.BaselineTolerances[resultName] = tolerance
+	}
+
+	return nil
+}
+
+func (cmp *configMapParser) parseRerunFields() error {
+	if rawRerunnable, exists := cmp.configMapRawData[types.RerunnableKey]; exists {
+		var err error
+		if cmp.Rerunnable, err = strconv.ParseBool(rawRerunnable); err != nil {
+			return ErrRerunnableFieldIsIllegal
+		}
+	}
+
+	if rawHistoryLimit, exists := cmp.configMapRawData[types.HistoryLimitKey]; exists {
+		historyLimit, err := strconv.Atoi(rawHistoryLimit)
+		if err != nil || historyLimit < 0 {
+			return ErrHistoryLimitFieldIsIllegal
+		}
+		cmp.HistoryLimit = historyLimit
+	}
+
+	return nil
+}
+
+// parseRetryFields parses the number of times a checkup failing with retryable failures is executed again,
+// and the delay before the first retry. The backoff is left zero when not set.
+func (cmp *configMapParser) parseRetryFields() error {
+	if rawRetries, exists := cmp.configMapRawData[types.RetriesKey]; exists {
+		retries, err := strconv.Atoi(rawRetries)
+		if err != nil || retries < 0 {
+			return ErrRetriesFieldIsIllegal
+		}
+		cmp.Retries = retries
+	}
+
+	if rawRetryBackoff, exists := cmp.configMapRawData[types.RetryBackoffKey]; exists {
+		retryBackoff, err := time.ParseDuration(rawRetryBackoff)
+		if err != nil || retryBackoff <= 0 {
+			return ErrRetryBackoffFieldIsIllegal
+		}
+		cmp.RetryBackoff = retryBackoff
+	}
+
+	return nil
+}
+
+// parseSinkFields parses the optional sinks the status is reported to, in addition to the user ConfigMap.
+// Unknown sink fields are rejected, so a misspelled sink is not silently ignored.
+func (cmp *configMapParser) parseSinkFields() error {
+	if err := cmp.validateSinkKeys(); err != nil {
+		return err
+	}
+
+	files := []struct {
+		key  string
+		path *string
+		err  error
+	}{
+		{types.SinkJSONFileKey, &cmp.Sinks.JSONFile, ErrSinkJSONFileFieldIsIllegal},
+		{types.SinkJUnitFileKey, &cmp.Sinks.JUnitFile, ErrSinkJUnitFileFieldIsIllegal},
+		{types.SinkReportFileKey, &cmp.Sinks.ReportFile, ErrSinkReportFileFieldIsIllegal},
+	}
+
+	for _, file := range files {
+		path, exists := cmp.configMapRawData[file.key]
+		if !exists {
+			continue
+		}
+
+		if !filepath.IsAbs(path) {
+			return file.err
+		}
+		*file.path = path
+	}
+
+	if rawTerminationMessage, exists := cmp.configMapRawData[types.SinkTerminationMessageKey]; exists {
+		terminationMessage, err := strconv.ParseBool(rawTerminationMessage)
+		if err != nil {
+			return ErrSinkTerminationMessageFieldIsIllegal
+		}
+		if terminationMessage {
+			cmp.Sinks.TerminationMessage = sink.DefaultTerminationMessagePath
+		}
+	}
+
+	if webhook, exists := cmp.configMapRawData[types.SinkWebhookKey]; exists {
+		webhookURL, err := url.Parse(webhook)
+		if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") || webhookURL.Host == "" {
+			return ErrSinkWebhookFieldIsIllegal
+		}
+		cmp.Sinks.Webhook = webhook
+	}
+
+	return nil
+}
+
+func (cmp *configMapParser) validateSinkKeys() error {
+	knownKeys := map[string]struct{}{
+		types.SinkJSONFileKey:           {},
+		types.SinkTerminationMessageKey: {},
+		types.SinkWebhookKey:            {},
+		types.SinkJUnitFileKey:          {},
+		types.SinkReportFileKey:         {},
+	}
+
+	var unknownKeys []string
+	for key := range cmp.configMapRawData {
+		if _, known := knownKeys[key]; strings.HasPrefix(key, types.SinkKeyPrefix) && !known {
+			unknownKeys = append(unknownKeys, strconv.Quote(key))
+		}
+	}
+
+	if len(unknownKeys) > 0 {
+		sort.Strings(unknownKeys)
+		return fmt.Errorf("%w: %s", ErrSinkFieldIsUnknown, strings.Join(unknownKeys, ", "))
+	}
+
+	return nil
+}
@@ -20,12 +20,21 @@
 package config
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strconv"
 	"time"
 
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/kiagnose/kiagnose/kiagnose/baseline"
 	"github.com/kiagnose/kiagnose/kiagnose/configmap"
+	"github.com/kiagnose/kiagnose/kiagnose/criteria"
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/history"
+	"github.com/kiagnose/kiagnose/kiagnose/sink"
 	"github.com/kiagnose/kiagnose/kiagnose/types"
 )
 
@@ -37,19 +46,39 @@ var (
 type Config struct {
 	ConfigMapNamespace string
 	ConfigMapName      string
+	CheckupNamespace   string
+	CheckupName        string
 	PodName            string
 	PodUID             string
 	UID                string
 	Timeout            time.Duration
+	SetupTimeout       time.Duration
+	RunTimeout         time.Duration
+	TeardownTimeout    time.Duration
 	Params             map[string]string
+	SuccessCriteria    *criteria.Expression
+	Baseline           *baseline.Baseline
+	Retries            int
+	RetryBackoff       time.Duration
+	Sinks              sink.Settings
 }
 
 type configMapSettings struct {
-	UID     string
-	Timeout time.Duration
-	Params  map[string]string
+	UID             string
+	Timeout         time.Duration
+	SetupTimeout    time.Duration
+	RunTimeout      time.Duration
+	TeardownTimeout time.Duration
+	Params          map[string]string
+	SuccessCriteria *criteria.Expression
+	Baseline        *baseline.Baseline
+	Retries         int
+	RetryBackoff    time.Duration
+	Sinks           sink.Settings
 }
 
+// Read reads the checkup configuration from the ConfigMap pointed to by the environment.
+// Errors caused by an invalid configuration carry the InvalidInput failure code.
 func Read(client kubernetes.Interface, rawEnv map[string]string) (Config, error) {
 	env := newEnvironment(rawEnv)
 
@@ -69,7 +98,15 @@ func Read(client kubernetes.Interface, rawEnv map[string]string) (Config, error)
 		PodUID:             env.PodUID,
 		UID:                cmSettings.UID,
 		Timeout:            cmSettings.Timeout,
+		SetupTimeout:       cmSettings.SetupTimeout,
+		RunTimeout:         cmSettings.RunTimeout,
+		TeardownTimeout:    cmSettings.TeardownTimeout,
 		Params:             cmSettings.Params,
+		SuccessCriteria:    cmSettings.SuccessCriteria,
+		Baseline:           cmSettings.Baseline,
+		Retries:            cmSettings.Retries,
+		RetryBackoff:       cmSettings.RetryBackoff,
+		Sinks:              cmSettings.Sinks,
 	}, nil
 }
 
@@ -83,20 +120,73 @@ func readFromConfigMap(client kubernetes.Interface, configMapNamespace, configMa
 		return configMapSettings{}, ErrConfigMapDataIsNil
 	}
 
-	if isConfigMapAlreadyInUse(configMap.Data) {
-		return configMapSettings{}, ErrConfigMapIsAlreadyInUse
+	inUse := isConfigMapAlreadyInUse(configMap.Data)
+	if inUse {
+		// A re-runnable ConfigMap may be used again once its previous run has completed.
+		if _, completed := configMap.Data[types.CompletionTimestampKey]; !completed || !isConfigMapRerunnable(configMap.Data) {
+			return configMapSettings{}, ErrConfigMapIsAlreadyInUse
+		}
 	}
 
 	parser := newConfigMapParser(configMap.Data)
 	err = parser.Parse()
+	if err != nil {
+		return configMapSettings{}, failure.Wrap(failure.CodeInvalidInput, err)
+	}
+
+	checkupBaseline, err := readBaseline(client, configMapNamespace, parser)
 	if err != nil {
 		return configMapSettings{}, err
 	}
 
+	// Archiving clears the status of the previous run, so it is the last step which may fail:
+	// a run which fails to start keeps the previous status intact.
+	if inUse {
+		if configMap, err = history.Archive(client, configMap, parser.HistoryLimit); err != nil {
+			return configMapSettings{}, err
+		}
+	}
+
 	return configMapSettings{
-		UID:     string(configMap.UID),
-		Timeout: parser.Timeout,
-		Params:  parser.Params,
+		UID:             string(configMap.UID),
+		Timeout:         parser.Timeout,
+		SetupTimeout:    parser.SetupTimeout,
+		RunTimeout:      parser.RunTimeout,
+		TeardownTimeout: parser.TeardownTimeout,
+		Params:          parser.Params,
+		SuccessCriteria: parser.SuccessCriteria,
+		Baseline:        checkupBaseline,
+		Retries:         parser.Retries,
+		RetryBackoff:    parser.RetryBackoff,
+		Sinks:           parser.Sinks,
+	}, nil
+}
+
+// readBaseline loads the results of the baseline ConfigMap, which defaults to the checkup ConfigMap namespace.
+// A missing or an incomplete baseline is an invalid input.
+func readBaseline(client kubernetes.Interface, configMapNamespace string, parser *configMapParser) (*baseline.Baseline, error) {
+	if parser.BaselineName == "" {
+		return nil, nil
+	}
+
+	namespace := parser.BaselineNamespace
+	if namespace == "" {
+		namespace = configMapNamespace
+	}
+
+	results, err := baseline.Load(context.Background(), client, namespace, parser.BaselineName)
+	if err != nil {
+		err = fmt.Errorf("failed to load baseline: %w", err)
+		if k8serrors.IsNotFound(err) || errors.Is(err, baseline.ErrBaselineNotCompleted) {
+			err = failure.Wrap(failure.CodeInvalidInput, err)
+		}
+		return nil, err
+	}
+
+	return &baseline.Baseline{
+		Name:       namespace + "/" + parser.BaselineName,
+		Results:    results,
+		Tolerances: parser.BaselineTolerances,
 	}, nil
 }
 
@@ -104,3 +194,8 @@ func isConfigMapAlreadyInUse(data map[string]string) bool {
 	_, exists := data[types.StartTimestampKey]
 	return exists
 }
+
+func isConfigMapRerunnable(data map[string]string) bool {
+	rerunnable, err := strconv.ParseBool(data[types.RerunnableKey])
+	return err == nil && rerunnable
+}
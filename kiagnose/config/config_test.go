@@ -20,6 +20,7 @@
 package config_test
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -29,7 +30,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 
+	"github.com/kiagnose/kiagnose/kiagnose/baseline"
 	"github.com/kiagnose/kiagnose/kiagnose/config"
+	"github.com/kiagnose/kiagnose/kiagnose/configmap"
+	"github.com/kiagnose/kiagnose/kiagnose/criteria"
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/history"
+	"github.com/kiagnose/kiagnose/kiagnose/sink"
 	"github.com/kiagnose/kiagnose/kiagnose/types"
 )
 
@@ -45,6 +52,9 @@ const (
 	param1Value  = "message1 value"
 	param2Key    = "message2"
 	param2Value  = "message2 value"
+
+	successCriteria = "avgLatencyNanoSec < 500000 && maxLatencyNanoSec < 2000000"
+	baselineName    = "checkup-baseline"
 )
 
 var validRawEnv = map[string]string{
@@ -84,6 +94,17 @@ func TestReadShouldSucceed(t *testing.T) {
 			rawEnv:      validRawEnv,
 			configMapData: map[string]string{
 				types.TimeoutKey:                     timeoutValue,
+				types.SetupTimeoutKey:                "2m",
+				types.RunTimeoutKey:                  "3m",
+				types.TeardownTimeoutKey:             "4m",
+				types.RetriesKey:                     "2",
+				types.RetryBackoffKey:                "1m",
+				types.SuccessCriteriaKey:             successCriteria,
+				types.SinkJSONFileKey:                "/results/status.json",
+				types.SinkTerminationMessageKey:      "true",
+				types.SinkWebhookKey:                 "https://ci.example.com/results",
+				types.SinkJUnitFileKey:               "/results/junit.xml",
+				types.SinkReportFileKey:              "/results/report.json",
 				types.ParamNameKeyPrefix + param1Key: param1Value,
 				types.ParamNameKeyPrefix + param2Key: param2Value,
 			},
@@ -94,10 +115,23 @@ func TestReadShouldSucceed(t *testing.T) {
 				PodUID:             podUID,
 				UID:                configMapUID,
 				Timeout:            stringToDurationMustParse(timeoutValue),
+				SetupTimeout:       2 * time.Minute,
+				RunTimeout:         3 * time.Minute,
+				TeardownTimeout:    4 * time.Minute,
+				Retries:            2,
+				RetryBackoff:       time.Minute,
+				SuccessCriteria:    criteriaMustParse(successCriteria),
 				Params: map[string]string{
 					param1Key: param1Value,
 					param2Key: param2Value,
 				},
+				Sinks: sink.Settings{
+					JSONFile:           "/results/status.json",
+					TerminationMessage: sink.DefaultTerminationMessagePath,
+					Webhook:            "https://ci.example.com/results",
+					JUnitFile:          "/results/junit.xml",
+					ReportFile:         "/results/report.json",
+				},
 			},
 		},
 	}
@@ -210,6 +244,126 @@ func TestConfigMapReadShouldFail(t *testing.T) {
 			},
 			expectedError: config.ErrConfigMapIsAlreadyInUse.Error(),
 		},
+		{
+			description: "when re-runnable ConfigMap has a run in progress",
+			rawEnv:      validRawEnv,
+			configMapData: map[string]string{
+				types.TimeoutKey:        timeoutValue,
+				types.RerunnableKey:     "true",
+				types.StartTimestampKey: time.Now().Format(time.RFC3339),
+			},
+			expectedError: config.ErrConfigMapIsAlreadyInUse.Error(),
+		},
+		{
+			description: "when rerunnable field is illegal",
+			rawEnv:      validRawEnv,
+			configMapData: map[string]string{
+				types.TimeoutKey:    timeoutValue,
+				types.RerunnableKey: "sometimes",
+			},
+			expectedError: config.ErrRerunnableFieldIsIllegal.Error(),
+		},
+		{
+			description: "when history limit field is illegal",
+			rawEnv:      validRawEnv,
+			configMapData: map[string]string{
+				types.TimeoutKey:      timeoutValue,
+				types.HistoryLimitKey: "-1",
+			},
+			expectedError: config.ErrHistoryLimitFieldIsIllegal.Error(),
+		},
+		{
+			description: "when success criteria field is illegal",
+			rawEnv:      validRawEnv,
+			configMapData: map[string]string{
+				types.TimeoutKey:         timeoutValue,
+				types.SuccessCriteriaKey: "avgLatencyNanoSec <",
+			},
+			expectedError: config.ErrSuccessCriteriaFieldIsIllegal.Error(),
+		},
+		{
+			description: "when sink json file field is not an absolute path",
+			rawEnv:      validRawEnv,
+			configMapData: map[string]string{
+				types.TimeoutKey:      timeoutValue,
+				types.SinkJSONFileKey: "status.json",
+			},
+			expectedError: config.ErrSinkJSONFileFieldIsIllegal.Error(),
+		},
+		{
+			description: "when sink junit file field is not an absolute path",
+			rawEnv:      validRawEnv,
+			configMapData: map[string]string{
+				types.TimeoutKey:       timeoutValue,
+				types.SinkJUnitFileKey: "junit.xml",
+			},
+			expectedError: config.ErrSinkJUnitFileFieldIsIllegal.Error(),
+		},
+		{
+			description: "when sink termination message field is illegal",
+			rawEnv:      validRawEnv,
+			configMapData: map[string]string{
+				types.TimeoutKey:                timeoutValue,
+				types.SinkTerminationMessageKey: "maybe",
+			},
+			expectedError: config.ErrSinkTerminationMessageFieldIsIllegal.Error(),
+		},
+		{
+			description: "when sink webhook field is not an http URL",
+			rawEnv:      validRawEnv,
+			configMapData: map[string]string{
+				types.TimeoutKey:     timeoutValue,
+				types.SinkWebhookKey: "ftp://ci.example.com/results",
+			},
+			expectedError: config.ErrSinkWebhookFieldIsIllegal.Error(),
+		},
+		{
+			description: "when sink field is unknown",
+			rawEnv:      validRawEnv,
+			configMapData: map[string]string{
+				types.TimeoutKey:                 timeoutValue,
+				types.SinkJSONFileKey:            "/results/status.json",
+				types.SinkKeyPrefix + "jsonfile": "/results/status.json",
+			},
+			expectedError: config.ErrSinkFieldIsUnknown.Error() + `: "spec.sink.jsonfile"`,
+		},
+		{
+			description: "when baseline ConfigMap field is illegal",
+			rawEnv:      validRawEnv,
+			configMapData: map[string]string{
+				types.TimeoutKey:           timeoutValue,
+				types.BaselineConfigMapKey: "target-ns/",
+			},
+			expectedError: config.ErrBaselineConfigMapFieldIsIllegal.Error(),
+		},
+		{
+			description: "when baseline tolerance field is illegal",
+			rawEnv:      validRawEnv,
+			configMapData: map[string]string{
+				types.TimeoutKey:                                    timeoutValue,
+				types.BaselineConfigMapKey:                          baselineName,
+				types.BaselineTolerancePrefix + "maxLatencyNanoSec": "thirty percent",
+			},
+			expectedError: config.ErrBaselineToleranceFieldIsIllegal.Error(),
+		},
+		{
+			description: "when baseline tolerance field is set without a baseline ConfigMap",
+			rawEnv:      validRawEnv,
+			configMapData: map[string]string{
+				types.TimeoutKey: timeoutValue,
+				types.BaselineTolerancePrefix + "maxLatencyNanoSec": "+30%",
+			},
+			expectedError: config.ErrBaselineToleranceFieldIsIllegal.Error(),
+		},
+		{
+			description: "when baseline ConfigMap does not exist",
+			rawEnv:      validRawEnv,
+			configMapData: map[string]string{
+				types.TimeoutKey:           timeoutValue,
+				types.BaselineConfigMapKey: baselineName,
+			},
+			expectedError: "failed to load baseline",
+		},
 		{
 			description:   "when timout field is missing",
 			rawEnv:        validRawEnv,
@@ -224,6 +378,51 @@ func TestConfigMapReadShouldFail(t *testing.T) {
 			},
 			expectedError: config.ErrTimeoutFieldIsIllegal.Error(),
 		},
+		{
+			description: "when setup timeout field is illegal",
+			rawEnv:      validRawEnv,
+			configMapData: map[string]string{
+				types.TimeoutKey:      timeoutValue,
+				types.SetupTimeoutKey: "illegalValue",
+			},
+			expectedError: config.ErrSetupTimeoutFieldIsIllegal.Error(),
+		},
+		{
+			description: "when run timeout field is not positive",
+			rawEnv:      validRawEnv,
+			configMapData: map[string]string{
+				types.TimeoutKey:    timeoutValue,
+				types.RunTimeoutKey: "0s",
+			},
+			expectedError: config.ErrRunTimeoutFieldIsIllegal.Error(),
+		},
+		{
+			description: "when teardown timeout field is illegal",
+			rawEnv:      validRawEnv,
+			configMapData: map[string]string{
+				types.TimeoutKey:         timeoutValue,
+				types.TeardownTimeoutKey: "-1m",
+			},
+			expectedError: config.ErrTeardownTimeoutFieldIsIllegal.Error(),
+		},
+		{
+			description: "when retries field is negative",
+			rawEnv:      validRawEnv,
+			configMapData: map[string]string{
+				types.TimeoutKey: timeoutValue,
+				types.RetriesKey: "-1",
+			},
+			expectedError: config.ErrRetriesFieldIsIllegal.Error(),
+		},
+		{
+			description: "when retry backoff field is illegal",
+			rawEnv:      validRawEnv,
+			configMapData: map[string]string{
+				types.TimeoutKey:      timeoutValue,
+				types.RetryBackoffKey: "soon",
+			},
+			expectedError: config.ErrRetryBackoffFieldIsIllegal.Error(),
+		},
 		{
 			description: "when ConfigMap Data is nil", rawEnv: validRawEnv, configMapData: nil, expectedError: config.ErrConfigMapDataIsNil.Error()},
 		{
@@ -247,6 +446,138 @@ func TestConfigMapReadShouldFail(t *testing.T) {
 	}
 }
 
+func TestConfigMapReadShouldDescribeInvalidInput(t *testing.T) {
+	validRawEnv := map[string]string{
+		config.ConfigMapNamespaceEnvVarName: configMapNamespace,
+		config.ConfigMapNameEnvVarName:      configMapName,
+		config.PodNameEnvVarName:            podName,
+	}
+
+	t.Run("when a field is illegal", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newConfigMap(configMapNamespace, configMapName, map[string]string{types.TimeoutKey: "soon"}))
+
+		_, err := config.Read(fakeClient, validRawEnv)
+		assert.ErrorIs(t, err, config.ErrTimeoutFieldIsIllegal)
+		assert.True(t, failure.HasCode(err, failure.CodeInvalidInput))
+	})
+
+	t.Run("when the baseline ConfigMap is missing", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newConfigMap(configMapNamespace, configMapName, map[string]string{
+			types.TimeoutKey:           timeoutValue,
+			types.BaselineConfigMapKey: baselineName,
+		}))
+
+		_, err := config.Read(fakeClient, validRawEnv)
+		assert.True(t, failure.HasCode(err, failure.CodeInvalidInput))
+	})
+
+	t.Run("unless the ConfigMap is missing", func(t *testing.T) {
+		_, err := config.Read(fake.NewSimpleClientset(), validRawEnv)
+		assert.Error(t, err)
+		assert.False(t, failure.HasCode(err, failure.CodeInvalidInput))
+	})
+}
+
+func TestConfigMapReadShouldLoadBaseline(t *testing.T) {
+	const baselineNamespace = "baseline-ns"
+
+	baselineData := map[string]string{
+		types.StartTimestampKey:                   time.Now().Add(-time.Hour).Format(time.RFC3339),
+		types.CompletionTimestampKey:              time.Now().Format(time.RFC3339),
+		types.SucceededKey:                        "true",
+		types.ResultsPrefix + "maxLatencyNanoSec": "1000000",
+	}
+
+	for _, reference := range []string{baselineName, baselineNamespace + "/" + baselineName} {
+		t.Run(reference, func(t *testing.T) {
+			expectedNamespace := configMapNamespace
+			if strings.Contains(reference, "/") {
+				expectedNamespace = baselineNamespace
+			}
+			fakeClient := fake.NewSimpleClientset(
+				newConfigMap(configMapNamespace, configMapName, map[string]string{
+					types.TimeoutKey:                                    timeoutValue,
+					types.BaselineConfigMapKey:                          reference,
+					types.BaselineTolerancePrefix + "maxLatencyNanoSec": "+30%",
+				}),
+				newConfigMap(expectedNamespace, baselineName, baselineData),
+			)
+
+			actualConfig, err := config.Read(fakeClient, validRawEnv)
+			assert.NoError(t, err)
+
+			expectedBaseline := &baseline.Baseline{
+				Name:    expectedNamespace + "/" + baselineName,
+				Results: map[string]string{"maxLatencyNanoSec": "1000000"},
+				Tolerances: map[string]baseline.Tolerance{
+					"maxLatencyNanoSec": {Direction: baseline.DirectionIncrease, Limit: 30, Relative: true},
+				},
+			}
+			assert.Equal(t, expectedBaseline, actualConfig.Baseline)
+		})
+	}
+}
+
+func TestConfigMapReadShouldArchivePreviousRunOfRerunnableConfigMap(t *testing.T) {
+	startTimestamp := time.Now().Add(-time.Hour)
+	fakeClient := fake.NewSimpleClientset(newConfigMap(configMapNamespace, configMapName, map[string]string{
+		types.TimeoutKey:             timeoutValue,
+		types.RerunnableKey:          "true",
+		types.StartTimestampKey:      startTimestamp.Format(time.RFC3339),
+		types.CompletionTimestampKey: startTimestamp.Add(time.Minute).Format(time.RFC3339),
+		types.SucceededKey:           "true",
+		types.FailureReasonKey:       "",
+	}))
+
+	_, err := config.Read(fakeClient, validRawEnv)
+	assert.NoError(t, err)
+
+	configMap, err := configmap.Get(fakeClient, configMapNamespace, configMapName)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{types.TimeoutKey: timeoutValue, types.RerunnableKey: "true"}, configMap.Data)
+
+	entries, err := history.List(fakeClient, configMapNamespace, configMapName)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "true", entries[0].Data[types.SucceededKey])
+	assert.Equal(t, startTimestamp.Format(time.RFC3339), entries[0].Data[types.StartTimestampKey])
+}
+
+func TestConfigMapReadShouldKeepPreviousRunOfRerunnableConfigMapOnInvalidInput(t *testing.T) {
+	testCases := map[string]map[string]string{
+		"when a field is illegal":                {types.TimeoutKey: "soon"},
+		"when the baseline ConfigMap is missing": {types.TimeoutKey: timeoutValue, types.BaselineConfigMapKey: baselineName},
+	}
+
+	for description, specData := range testCases {
+		t.Run(description, func(t *testing.T) {
+			startTimestamp := time.Now().Add(-time.Hour)
+			previousRunData := map[string]string{
+				types.RerunnableKey:          "true",
+				types.StartTimestampKey:      startTimestamp.Format(time.RFC3339),
+				types.CompletionTimestampKey: startTimestamp.Add(time.Minute).Format(time.RFC3339),
+				types.SucceededKey:           "true",
+				types.FailureReasonKey:       "",
+			}
+			for k, v := range specData {
+				previousRunData[k] = v
+			}
+			fakeClient := fake.NewSimpleClientset(newConfigMap(configMapNamespace, configMapName, previousRunData))
+
+			_, err := config.Read(fakeClient, validRawEnv)
+			assert.True(t, failure.HasCode(err, failure.CodeInvalidInput))
+
+			configMap, err := configmap.Get(fakeClient, configMapNamespace, configMapName)
+			assert.NoError(t, err)
+			assert.Equal(t, previousRunData, configMap.Data)
+
+			entries, err := history.List(fakeClient, configMapNamespace, configMapName)
+			assert.NoError(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+}
+
 func newConfigMap(namespace, name string, data map[string]string) *corev1.ConfigMap {
 	return &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -266,3 +597,11 @@ func stringToDurationMustParse(rawDuration string) time.Duration {
 
 	return duration
 }
+
+func criteriaMustParse(rawCriteria string) *criteria.Expression {
+	expression, err := criteria.Parse(rawCriteria)
+	if err != nil {
+		panic(err)
+	}
+	return expression
+}
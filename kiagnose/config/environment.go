@@ -28,9 +28,18 @@ type environment struct {
 	PodUID             string
 }
 
+type checkupEnvironment struct {
+	CheckupNamespace string
+	CheckupName      string
+	PodName          string
+	PodUID           string
+}
+
 const (
 	ConfigMapNamespaceEnvVarName = "CONFIGMAP_NAMESPACE"
 	ConfigMapNameEnvVarName      = "CONFIGMAP_NAME"
+	CheckupNamespaceEnvVarName   = "CHECKUP_NAMESPACE"
+	CheckupNameEnvVarName        = "CHECKUP_NAME"
 	PodNameEnvVarName            = "HOSTNAME"
 	PodUIDEnvVarName             = "POD_UID"
 )
@@ -38,6 +47,8 @@ const (
 var (
 	ErrMissingConfigMapNamespace = fmt.Errorf("missing required environment variable: %q", ConfigMapNamespaceEnvVarName)
 	ErrMissingConfigMapName      = fmt.Errorf("missing required environment variable: %q", ConfigMapNameEnvVarName)
+	ErrMissingCheckupNamespace   = fmt.Errorf("missing required environment variable: %q", CheckupNamespaceEnvVarName)
+	ErrMissingCheckupName        = fmt.Errorf("missing required environment variable: %q", CheckupNameEnvVarName)
 	ErrMissingPodName            = fmt.Errorf("missing required environment variable: %q", PodNameEnvVarName)
 )
 
@@ -67,3 +78,30 @@ func (e environment) Validate() error {
 
 	return nil
 }
+
+func newCheckupEnvironment(rawEnv map[string]string) checkupEnvironment {
+	return checkupEnvironment{
+		CheckupNamespace: rawEnv[CheckupNamespaceEnvVarName],
+		CheckupName:      rawEnv[CheckupNameEnvVarName],
+		PodName:          rawEnv[PodNameEnvVarName],
+		PodUID:           rawEnv[PodUIDEnvVarName],
+	}
+}
+
+func (e checkupEnvironment) Validate() error {
+	if e.CheckupNamespace == "" {
+		return ErrMissingCheckupNamespace
+	}
+
+	if e.CheckupName == "" {
+		return ErrMissingCheckupName
+	}
+
+	if e.PodName == "" {
+		return ErrMissingPodName
+	}
+
+	// PodUID field is optional, thus not validated
+
+	return nil
+}
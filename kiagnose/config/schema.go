@@ -0,0 +1,428 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+type ParamType string
+
+const (
+	ParamTypeString   ParamType = "string"
+	ParamTypeInt      ParamType = "int"
+	ParamTypeBool     ParamType = "bool"
+	ParamTypeDuration ParamType = "duration"
+	ParamTypeList     ParamType = "list"
+	ParamTypeQuantity ParamType = "quantity"
+)
+
+var ErrInvalidParam = errors.New("parameter is invalid")
+
+// UnknownParamsWarningResultKey is the result key under which checkups using a lenient
+// schema report the unknown parameters they were given.
+const UnknownParamsWarningResultKey = "paramsWarning"
+
+// ParamSpec declares a single checkup parameter.
+type ParamSpec struct {
+	Name     string
+	Type     ParamType
+	Required bool
+	// Default is the raw value used when the parameter is not set.
+	// It is parsed and validated like a user supplied value.
+	Default string
+	// DeprecatedNames are older names the parameter is still accepted under.
+	// The current name is preferred when both are set.
+	DeprecatedNames []string
+	// Min and Max optionally bound int, duration and quantity parameters (inclusive).
+	// They are given in the same raw form as the parameter value.
+	Min string
+	Max string
+}
+
+// Schema describes the parameters a checkup accepts.
+// By default, parameters which are not declared in the schema fail the parsing.
+type Schema struct {
+	specs        []ParamSpec
+	allowUnknown bool
+}
+
+func NewSchema(specs ...ParamSpec) Schema {
+	return Schema{specs: specs}
+}
+
+// Lenient returns a copy of the schema which tolerates unknown parameters,
+// reporting them as warnings (see Params.Warnings) instead of errors.
+func (s Schema) Lenient() Schema {
+	s.allowUnknown = true
+	return s
+}
+
+// Parse validates the raw parameters against the schema and returns their typed values.
+// All invalid parameters are reported together in a single *ParamsError.
+func (s Schema) Parse(rawParams map[string]string) (Params, error) {
+	params := Params{
+		types:  map[string]ParamType{},
+		values: map[string]interface{}{},
+	}
+
+	paramsErr := &ParamsError{}
+	for _, spec := range s.specs {
+		params.types[spec.Name] = spec.Type
+
+		rawValue := lookupParam(rawParams, spec)
+		if rawValue == "" {
+			if spec.Required {
+				paramsErr.Errors = append(paramsErr.Errors, &ParamError{Name: spec.Name, Reason: "is required"})
+				continue
+			}
+			rawValue = spec.Default
+		}
+
+		if rawValue == "" {
+			continue
+		}
+
+		value, err := spec.parse(rawValue)
+		if err != nil {
+			paramsErr.Errors = append(paramsErr.Errors, &ParamError{Name: spec.Name, Reason: err.Error()})
+			continue
+		}
+		params.values[spec.Name] = value
+	}
+
+	for _, unknownErr := range s.unknownParamsErrors(rawParams) {
+		if s.allowUnknown {
+			log.Printf("warning: %v", unknownErr)
+			params.warnings = append(params.warnings, unknownErr.Error())
+		} else {
+			paramsErr.Errors = append(paramsErr.Errors, unknownErr)
+		}
+	}
+
+	if len(paramsErr.Errors) > 0 {
+		return Params{}, paramsErr
+	}
+
+	return params, nil
+}
+
+func (s Schema) unknownParamsErrors(rawParams map[string]string) []*ParamError {
+	knownNames := map[string]struct{}{}
+	for _, spec := range s.specs {
+		knownNames[spec.Name] = struct{}{}
+		for _, deprecatedName := range spec.DeprecatedNames {
+			knownNames[deprecatedName] = struct{}{}
+		}
+	}
+
+	var unknownNames []string
+	for name := range rawParams {
+		if _, known := knownNames[name]; !known {
+			unknownNames = append(unknownNames, name)
+		}
+	}
+	sort.Strings(unknownNames)
+
+	var unknownErrors []*ParamError
+	for _, name := range unknownNames {
+		reason := "is unknown"
+		if suggestion := s.suggest(name); suggestion != "" {
+			reason += fmt.Sprintf(", did you mean %q?", suggestion)
+		}
+		unknownErrors = append(unknownErrors, &ParamError{Name: name, Reason: reason})
+	}
+
+	return unknownErrors
+}
+
+// suggest returns the declared parameter name closest to the given unknown name,
+// or an empty string when none is close enough to be a likely typo.
+func (s Schema) suggest(unknownName string) string {
+	const (
+		minMaxDistance   = 2
+		maxDistanceRatio = 3
+	)
+
+	suggestion := ""
+	bestDistance := maxInt(minMaxDistance, len(unknownName)/maxDistanceRatio) + 1
+	for _, spec := range s.specs {
+		candidates := append([]string{spec.Name}, spec.DeprecatedNames...)
+		for _, candidate := range candidates {
+			distance := editDistance(strings.ToLower(unknownName), strings.ToLower(candidate))
+			if distance < bestDistance {
+				bestDistance = distance
+				suggestion = spec.Name
+			}
+		}
+	}
+
+	return suggestion
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			substitutionCost := 1
+			if a[i-1] == b[j-1] {
+				substitutionCost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+substitutionCost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(first int, others ...int) int {
+	result := first
+	for _, v := range others {
+		if v < result {
+			result = v
+		}
+	}
+	return result
+}
+
+func lookupParam(rawParams map[string]string, spec ParamSpec) string {
+	if value, exists := rawParams[spec.Name]; exists {
+		return value
+	}
+
+	for _, deprecatedName := range spec.DeprecatedNames {
+		if value, exists := rawParams[deprecatedName]; exists {
+			log.Printf("warning: %q parameter is DEPRECATED, please use the new form: %q", deprecatedName, spec.Name)
+			return value
+		}
+	}
+
+	return ""
+}
+
+func (spec ParamSpec) parse(rawValue string) (interface{}, error) {
+	value, err := parseValue(spec.Type, rawValue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := spec.checkRange(value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func parseValue(paramType ParamType, rawValue string) (interface{}, error) {
+	switch paramType {
+	case ParamTypeString:
+		return rawValue, nil
+	case ParamTypeInt:
+		return strconv.Atoi(rawValue)
+	case ParamTypeBool:
+		return strconv.ParseBool(rawValue)
+	case ParamTypeDuration:
+		return time.ParseDuration(rawValue)
+	case ParamTypeList:
+		return parseList(rawValue), nil
+	case ParamTypeQuantity:
+		return resource.ParseQuantity(rawValue)
+	default:
+		return nil, fmt.Errorf("unknown parameter type %q", paramType)
+	}
+}
+
+func parseList(rawValue string) []string {
+	var list []string
+	for _, item := range strings.Split(rawValue, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func (spec ParamSpec) checkRange(value interface{}) error {
+	if spec.Min != "" {
+		cmp, err := spec.compareToBound(value, spec.Min)
+		if err != nil {
+			return err
+		}
+		if cmp < 0 {
+			return fmt.Errorf("must be at least %s", spec.Min)
+		}
+	}
+
+	if spec.Max != "" {
+		cmp, err := spec.compareToBound(value, spec.Max)
+		if err != nil {
+			return err
+		}
+		if cmp > 0 {
+			return fmt.Errorf("must be at most %s", spec.Max)
+		}
+	}
+
+	return nil
+}
+
+func (spec ParamSpec) compareToBound(value interface{}, rawBound string) (int, error) {
+	bound, err := parseValue(spec.Type, rawBound)
+	if err != nil {
+		return 0, fmt.Errorf("range bound %q is invalid: %v", rawBound, err)
+	}
+
+	switch v := value.(type) {
+	case int:
+		return compareInt64(int64(v), int64(bound.(int))), nil
+	case time.Duration:
+		return compareInt64(int64(v), int64(bound.(time.Duration))), nil
+	case resource.Quantity:
+		return v.Cmp(bound.(resource.Quantity)), nil
+	default:
+		return 0, fmt.Errorf("range is not supported for %s parameters", spec.Type)
+	}
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Params holds the typed parameter values parsed by a Schema.
+// Accessing an undeclared parameter, or a parameter with a different type than
+// it was declared with, is a programming error and panics.
+type Params struct {
+	types    map[string]ParamType
+	values   map[string]interface{}
+	warnings []string
+}
+
+// Warnings returns the unknown parameters tolerated by a lenient schema.
+// Checkups are expected to expose them under the UnknownParamsWarningResultKey result.
+func (p Params) Warnings() []string {
+	return p.warnings
+}
+
+// IsSet reports whether the parameter was given a value, either explicitly or by default.
+func (p Params) IsSet(name string) bool {
+	_, exists := p.values[name]
+	return exists
+}
+
+func (p Params) String(name string) string {
+	v, _ := p.get(name, ParamTypeString).(string)
+	return v
+}
+
+func (p Params) Int(name string) int {
+	v, _ := p.get(name, ParamTypeInt).(int)
+	return v
+}
+
+func (p Params) Bool(name string) bool {
+	v, _ := p.get(name, ParamTypeBool).(bool)
+	return v
+}
+
+func (p Params) Duration(name string) time.Duration {
+	v, _ := p.get(name, ParamTypeDuration).(time.Duration)
+	return v
+}
+
+func (p Params) List(name string) []string {
+	v, _ := p.get(name, ParamTypeList).([]string)
+	return v
+}
+
+func (p Params) Quantity(name string) resource.Quantity {
+	v, _ := p.get(name, ParamTypeQuantity).(resource.Quantity)
+	return v
+}
+
+func (p Params) get(name string, paramType ParamType) interface{} {
+	declaredType, declared := p.types[name]
+	if !declared {
+		panic(fmt.Sprintf("parameter %q is not declared in the schema", name))
+	}
+	if declaredType != paramType {
+		panic(fmt.Sprintf("parameter %q is declared as %s, not %s", name, declaredType, paramType))
+	}
+	return p.values[name]
+}
+
+type ParamError struct {
+	Name   string
+	Reason string
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("%q parameter is invalid: %s", e.Name, e.Reason)
+}
+
+func (e *ParamError) Is(target error) bool {
+	return target == ErrInvalidParam
+}
+
+// ParamsError aggregates the errors of all invalid parameters.
+type ParamsError struct {
+	Errors []*ParamError
+}
+
+func (e *ParamsError) Error() string {
+	var messages []string
+	for _, paramErr := range e.Errors {
+		messages = append(messages, paramErr.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *ParamsError) Is(target error) bool {
+	return target == ErrInvalidParam
+}
@@ -0,0 +1,248 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/kiagnose/kiagnose/kiagnose/config"
+)
+
+const (
+	stringParamName   = "message"
+	intParamName      = "count"
+	boolParamName     = "verbose"
+	durationParamName = "interval"
+	listParamName     = "nodes"
+	quantityParamName = "memory"
+
+	deprecatedIntParamName = "old_count"
+)
+
+var testSchema = config.NewSchema(
+	config.ParamSpec{Name: stringParamName, Type: config.ParamTypeString, Required: true},
+	config.ParamSpec{
+		Name:            intParamName,
+		Type:            config.ParamTypeInt,
+		Default:         "5",
+		DeprecatedNames: []string{deprecatedIntParamName},
+		Min:             "1",
+		Max:             "10",
+	},
+	config.ParamSpec{Name: boolParamName, Type: config.ParamTypeBool},
+	config.ParamSpec{Name: durationParamName, Type: config.ParamTypeDuration, Default: "1m", Max: "1h"},
+	config.ParamSpec{Name: listParamName, Type: config.ParamTypeList},
+	config.ParamSpec{Name: quantityParamName, Type: config.ParamTypeQuantity, Min: "64Mi"},
+)
+
+func TestSchemaParseShouldSucceed(t *testing.T) {
+	t.Run("with all parameters set", func(t *testing.T) {
+		params, err := testSchema.Parse(map[string]string{
+			stringParamName:   "hello",
+			intParamName:      "7",
+			boolParamName:     "true",
+			durationParamName: "30s",
+			listParamName:     "node1, node2,,",
+			quantityParamName: "1Gi",
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "hello", params.String(stringParamName))
+		assert.Equal(t, 7, params.Int(intParamName))
+		assert.True(t, params.Bool(boolParamName))
+		assert.Equal(t, 30*time.Second, params.Duration(durationParamName))
+		assert.Equal(t, []string{"node1", "node2"}, params.List(listParamName))
+		assert.True(t, resource.MustParse("1Gi").Equal(params.Quantity(quantityParamName)))
+	})
+
+	t.Run("with defaults for missing optional parameters", func(t *testing.T) {
+		params, err := testSchema.Parse(map[string]string{stringParamName: "hello"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, 5, params.Int(intParamName))
+		assert.Equal(t, time.Minute, params.Duration(durationParamName))
+		assert.True(t, params.IsSet(intParamName))
+		assert.False(t, params.IsSet(boolParamName))
+		assert.False(t, params.Bool(boolParamName))
+		assert.Empty(t, params.List(listParamName))
+	})
+
+	t.Run("with a deprecated parameter name", func(t *testing.T) {
+		params, err := testSchema.Parse(map[string]string{stringParamName: "hello", deprecatedIntParamName: "3"})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, params.Int(intParamName))
+	})
+
+	t.Run("preferring the current name over a deprecated one", func(t *testing.T) {
+		params, err := testSchema.Parse(map[string]string{
+			stringParamName:        "hello",
+			intParamName:           "2",
+			deprecatedIntParamName: "3",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, params.Int(intParamName))
+	})
+}
+
+func TestSchemaParseShouldFailWhen(t *testing.T) {
+	type parseFailureTestCase struct {
+		description   string
+		params        map[string]string
+		expectedError string
+	}
+
+	testCases := []parseFailureTestCase{
+		{
+			description:   "required parameter is missing",
+			params:        map[string]string{},
+			expectedError: `"message" parameter is invalid: is required`,
+		},
+		{
+			description:   "required parameter is empty",
+			params:        map[string]string{stringParamName: ""},
+			expectedError: `"message" parameter is invalid: is required`,
+		},
+		{
+			description:   "int parameter is malformed",
+			params:        map[string]string{stringParamName: "hello", intParamName: "f1ve"},
+			expectedError: `"count" parameter is invalid: strconv.Atoi: parsing "f1ve": invalid syntax`,
+		},
+		{
+			description:   "int parameter is below minimum",
+			params:        map[string]string{stringParamName: "hello", intParamName: "0"},
+			expectedError: `"count" parameter is invalid: must be at least 1`,
+		},
+		{
+			description:   "int parameter is above maximum",
+			params:        map[string]string{stringParamName: "hello", intParamName: "11"},
+			expectedError: `"count" parameter is invalid: must be at most 10`,
+		},
+		{
+			description:   "bool parameter is malformed",
+			params:        map[string]string{stringParamName: "hello", boolParamName: "yes please"},
+			expectedError: `"verbose" parameter is invalid: strconv.ParseBool: parsing "yes please": invalid syntax`,
+		},
+		{
+			description:   "duration parameter is above maximum",
+			params:        map[string]string{stringParamName: "hello", durationParamName: "2h"},
+			expectedError: `"interval" parameter is invalid: must be at most 1h`,
+		},
+		{
+			description:   "quantity parameter is below minimum",
+			params:        map[string]string{stringParamName: "hello", quantityParamName: "1Mi"},
+			expectedError: `"memory" parameter is invalid: must be at least 64Mi`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			_, err := testSchema.Parse(testCase.params)
+			assert.ErrorIs(t, err, config.ErrInvalidParam)
+			assert.EqualError(t, err, testCase.expectedError)
+		})
+	}
+}
+
+func TestSchemaParseShouldReportAllInvalidParams(t *testing.T) {
+	_, err := testSchema.Parse(map[string]string{intParamName: "100", durationParamName: "soon"})
+
+	var paramsErr *config.ParamsError
+	assert.ErrorAs(t, err, &paramsErr)
+	assert.Len(t, paramsErr.Errors, 3)
+	assert.EqualError(t, err,
+		`"message" parameter is invalid: is required; `+
+			`"count" parameter is invalid: must be at most 10; `+
+			`"interval" parameter is invalid: time: invalid duration "soon"`,
+	)
+}
+
+func TestSchemaParseShouldFailOnInvalidDefault(t *testing.T) {
+	schema := config.NewSchema(config.ParamSpec{Name: intParamName, Type: config.ParamTypeInt, Default: "many"})
+
+	_, err := schema.Parse(nil)
+	assert.ErrorIs(t, err, config.ErrInvalidParam)
+}
+
+func TestParamsAccessorShouldPanicOnTypeMismatch(t *testing.T) {
+	params, err := testSchema.Parse(map[string]string{stringParamName: "hello"})
+	assert.NoError(t, err)
+
+	assert.Panics(t, func() { params.String(intParamName) })
+	assert.Panics(t, func() { params.Int("undeclared") })
+}
+
+func TestSchemaParseShouldFailOnUnknownParams(t *testing.T) {
+	type unknownParamTestCase struct {
+		description   string
+		unknownParam  string
+		expectedError string
+	}
+
+	testCases := []unknownParamTestCase{
+		{
+			description:   "with a suggestion when the name has a typo",
+			unknownParam:  "cuont",
+			expectedError: `"cuont" parameter is invalid: is unknown, did you mean "count"?`,
+		},
+		{
+			description:   "with a suggestion when the name differs in case only",
+			unknownParam:  "Verbose",
+			expectedError: `"Verbose" parameter is invalid: is unknown, did you mean "verbose"?`,
+		},
+		{
+			description:   "with the current name suggested for a deprecated name typo",
+			unknownParam:  "old_cont",
+			expectedError: `"old_cont" parameter is invalid: is unknown, did you mean "count"?`,
+		},
+		{
+			description:   "without a suggestion when no name is close",
+			unknownParam:  "timeoutSeconds",
+			expectedError: `"timeoutSeconds" parameter is invalid: is unknown`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			_, err := testSchema.Parse(map[string]string{stringParamName: "hello", testCase.unknownParam: "1"})
+			assert.ErrorIs(t, err, config.ErrInvalidParam)
+			assert.EqualError(t, err, testCase.expectedError)
+		})
+	}
+}
+
+func TestLenientSchemaParseShouldWarnOnUnknownParams(t *testing.T) {
+	params, err := testSchema.Lenient().Parse(map[string]string{
+		stringParamName: "hello",
+		"intervl":       "1s",
+		"color":         "blue",
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		`"color" parameter is invalid: is unknown`,
+		`"intervl" parameter is invalid: is unknown, did you mean "interval"?`,
+	}, params.Warnings())
+	assert.Equal(t, time.Minute, params.Duration(durationParamName))
+}
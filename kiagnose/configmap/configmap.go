@@ -24,6 +24,9 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -34,3 +37,21 @@ func Get(client kubernetes.Interface, namespace, name string) (*corev1.ConfigMap
 func Update(client kubernetes.Interface, configMap *corev1.ConfigMap) (*corev1.ConfigMap, error) {
 	return client.CoreV1().ConfigMaps(configMap.Namespace).Update(context.Background(), configMap, metav1.UpdateOptions{})
 }
+
+// Watch watches the changes of a single ConfigMap, starting after the given resource version.
+func Watch(ctx context.Context, client kubernetes.Interface, namespace, name, resourceVersion string) (watch.Interface, error) {
+	return client.CoreV1().ConfigMaps(namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector:   fields.OneTermEqualSelector("metadata.name", name).String(),
+		ResourceVersion: resourceVersion,
+	})
+}
+
+// FieldManager identifies kiagnose as the manager of the fields it writes.
+const FieldManager = "kiagnose"
+
+// Patch applies a JSON merge patch to the ConfigMap.
+func Patch(client kubernetes.Interface, namespace, name string, patch []byte) (*corev1.ConfigMap, error) {
+	return client.CoreV1().ConfigMaps(namespace).Patch(
+		context.Background(), name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager},
+	)
+}
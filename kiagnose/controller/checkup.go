@@ -0,0 +1,143 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package controller
+
+import (
+	"context"
+	"log"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	checkupv1alpha1 "github.com/kiagnose/kiagnose/kiagnose/apis/checkup/v1alpha1"
+	"github.com/kiagnose/kiagnose/kiagnose/checkup"
+	"github.com/kiagnose/kiagnose/kiagnose/job"
+	"github.com/kiagnose/kiagnose/kiagnose/reporter"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+// watchCheckups sets up the informer and the queue of the Checkup objects in the controller namespace.
+func (c *Controller) watchCheckups() {
+	checkups := c.checkupClient.KiagnoseV1alpha1().Checkups(c.namespace)
+	c.checkupInformer = cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return checkups.List(context.Background(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return checkups.Watch(context.Background(), options)
+			},
+		},
+		&checkupv1alpha1.Checkup{},
+		defaultResyncPeriod,
+		cache.Indexers{},
+	)
+	c.checkupQueue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "checkup-resources")
+	c.informersSynced = append(c.informersSynced, c.checkupInformer.HasSynced)
+
+	c.checkupInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueueCheckup,
+		UpdateFunc: func(_, newObj interface{}) { c.enqueueCheckup(newObj) },
+	})
+}
+
+func (c *Controller) enqueueCheckup(obj interface{}) {
+	if checkupObj, ok := obj.(*checkupv1alpha1.Checkup); ok && checkupObj.Annotations[types.CheckupImageAnnotation] != "" {
+		c.checkupQueue.Add(checkupObj.Name)
+	}
+}
+
+// reconcileCheckup launches the checkup Job of a Checkup object which has not been executed yet,
+// and marks the Checkup object as failed in case the Job terminates without reporting a completion.
+func (c *Controller) reconcileCheckup(checkupName string) error {
+	obj, exists, err := c.checkupInformer.GetStore().GetByKey(c.namespace + "/" + checkupName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+	checkupObj := obj.(*checkupv1alpha1.Checkup)
+
+	image := checkupObj.Annotations[types.CheckupImageAnnotation]
+	if image == "" {
+		return nil
+	}
+
+	checkupJob, err := c.jobLister.Get(job.NameForCheckup(checkupName))
+	if k8serrors.IsNotFound(err) {
+		if checkupObj.Status.StartTimestamp != nil || checkupObj.Status.CompletionTimestamp != nil {
+			// The checkup has already been executed, or is executed by other means.
+			return nil
+		}
+		return c.launchForCheckup(checkupObj, image)
+	}
+	if err != nil {
+		return err
+	}
+
+	if checkupObj.Status.CompletionTimestamp != nil {
+		return nil
+	}
+
+	if finished, failed := job.Finished(checkupJob); finished {
+		return c.markCheckupAsFailed(checkupName, jobFailureReason(checkupJob, failed))
+	}
+
+	return nil
+}
+
+func (c *Controller) launchForCheckup(checkupObj *checkupv1alpha1.Checkup, image string) error {
+	serviceAccountName := checkupObj.Annotations[types.CheckupServiceAccountAnnotation]
+	newJob := job.NewForCheckup(checkupObj, job.NameForCheckup(checkupObj.Name), image, serviceAccountName)
+
+	log.Printf("launching checkup Job %s/%s with image %q", newJob.Namespace, newJob.Name, image)
+	if _, err := job.Create(c.client, newJob); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	return nil
+}
+
+// markCheckupAsFailed reports a failed completion, unless the checkup has already reported its own.
+// The Checkup object is re-read from the cluster, as the cached object may not contain the checkup final report yet.
+func (c *Controller) markCheckupAsFailed(checkupName, reason string) error {
+	checkupObj, err := checkup.Get(c.checkupClient, c.namespace, checkupName)
+	if err != nil {
+		return err
+	}
+
+	if checkupObj.Status.CompletionTimestamp != nil {
+		return nil
+	}
+
+	log.Printf("marking Checkup %s/%s as failed: %s", c.namespace, checkupName, reason)
+	return reporter.NewCheckupReporter(c.checkupClient, c.namespace, checkupName).Report(status.Status{
+		CompletionTimestamp: time.Now(),
+		FailureReason:       []string{reason},
+		Phase:               status.PhaseCompleted,
+	})
+}
@@ -0,0 +1,548 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/kiagnose/kiagnose/kiagnose/client/clientset/versioned"
+	"github.com/kiagnose/kiagnose/kiagnose/configmap"
+	"github.com/kiagnose/kiagnose/kiagnose/events"
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/job"
+	"github.com/kiagnose/kiagnose/kiagnose/reporter"
+	"github.com/kiagnose/kiagnose/kiagnose/schedule"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+	"github.com/kiagnose/kiagnose/kiagnose/suite"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+var ErrCacheSyncFailed = errors.New("failed to wait for caches to sync")
+
+const defaultResyncPeriod = 10 * time.Minute
+
+// Controller launches a checkup Job for each ConfigMap annotated with a checkup image,
+// and marks the ConfigMap as failed in case the Job terminates without reporting a completion.
+// ConfigMaps which are also annotated with a schedule are templates, cloned into a new ConfigMap per scheduled run.
+// ConfigMaps annotated as suites are executed by creating a ConfigMap per member checkup, and aggregating their results.
+// Once given a Checkup client, the controller also launches a checkup Job for each Checkup object annotated with a checkup image.
+type Controller struct {
+	client          kubernetes.Interface
+	informerFactory informers.SharedInformerFactory
+	configMapLister corelisters.ConfigMapNamespaceLister
+	jobLister       batchlisters.JobNamespaceLister
+	informersSynced []cache.InformerSynced
+	queue           workqueue.RateLimitingInterface
+	namespace       string
+
+	checkupClient   versioned.Interface
+	checkupInformer cache.SharedIndexInformer
+	checkupQueue    workqueue.RateLimitingInterface
+}
+
+type Option func(*Controller)
+
+// WithCheckupClient makes the controller watch Checkup objects in addition to ConfigMaps.
+// It should be used only when the Checkup custom resource definition is installed.
+func WithCheckupClient(checkupClient versioned.Interface) Option {
+	return func(c *Controller) {
+		c.checkupClient = checkupClient
+	}
+}
+
+func New(client kubernetes.Interface, namespace string, options ...Option) *Controller {
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(client, defaultResyncPeriod, informers.WithNamespace(namespace))
+	configMapInformer := informerFactory.Core().V1().ConfigMaps()
+	jobInformer := informerFactory.Batch().V1().Jobs()
+
+	c := &Controller{
+		client:          client,
+		informerFactory: informerFactory,
+		configMapLister: configMapInformer.Lister().ConfigMaps(namespace),
+		jobLister:       jobInformer.Lister().Jobs(namespace),
+		informersSynced: []cache.InformerSynced{configMapInformer.Informer().HasSynced, jobInformer.Informer().HasSynced},
+		queue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "checkups"),
+		namespace:       namespace,
+	}
+
+	configMapInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueueConfigMap,
+		UpdateFunc: func(_, newObj interface{}) { c.enqueueConfigMap(newObj) },
+	})
+	jobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueueJobOwner,
+		UpdateFunc: func(_, newObj interface{}) { c.enqueueJobOwner(newObj) },
+		DeleteFunc: c.enqueueJobOwner,
+	})
+
+	for _, option := range options {
+		option(c)
+	}
+
+	if c.checkupClient != nil {
+		c.watchCheckups()
+	}
+
+	return c
+}
+
+func (c *Controller) Run(ctx context.Context, workers int) error {
+	defer c.queue.ShutDown()
+
+	c.informerFactory.Start(ctx.Done())
+	if c.checkupInformer != nil {
+		defer c.checkupQueue.ShutDown()
+		go c.checkupInformer.Run(ctx.Done())
+	}
+	if !cache.WaitForCacheSync(ctx.Done(), c.informersSynced...) {
+		return ErrCacheSyncFailed
+	}
+
+	log.Printf("watching checkup ConfigMaps in namespace %q", c.namespace)
+	for i := 0; i < workers; i++ {
+		go wait.UntilWithContext(ctx, c.runWorker, time.Second)
+	}
+
+	if c.checkupInformer != nil {
+		log.Printf("watching Checkup objects in namespace %q", c.namespace)
+		for i := 0; i < workers; i++ {
+			go wait.UntilWithContext(ctx, c.runCheckupWorker, time.Second)
+		}
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+func (c *Controller) enqueueConfigMap(obj interface{}) {
+	configMap, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		return
+	}
+
+	if configMap.Annotations[types.CheckupImageAnnotation] != "" || isSuite(configMap) {
+		c.queue.Add(configMap.Name)
+	}
+
+	// The schedule template keeps track of its runs, e.g. to summarize them once completed.
+	if templateName := configMap.Labels[types.ScheduledByLabel]; templateName != "" {
+		c.queue.Add(templateName)
+	}
+
+	if suiteName := configMap.Labels[types.SuiteOfLabel]; suiteName != "" {
+		c.queue.Add(suiteName)
+	}
+}
+
+func (c *Controller) enqueueJobOwner(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	checkupJob, ok := obj.(*batchv1.Job)
+	if !ok {
+		return
+	}
+
+	if configMapName := checkupJob.Labels[types.CheckupConfigMapLabel]; configMapName != "" {
+		c.queue.Add(configMapName)
+	}
+
+	if checkupName := checkupJob.Labels[types.CheckupResourceLabel]; checkupName != "" && c.checkupQueue != nil {
+		c.checkupQueue.Add(checkupName)
+	}
+}
+
+func (c *Controller) runWorker(_ context.Context) {
+	for processNextItem(c.queue, c.reconcile, "ConfigMap", c.namespace) {
+	}
+}
+
+func (c *Controller) runCheckupWorker(_ context.Context) {
+	for processNextItem(c.checkupQueue, c.reconcileCheckup, "Checkup", c.namespace) {
+	}
+}
+
+func processNextItem(queue workqueue.RateLimitingInterface, reconcile func(string) error, kind, namespace string) bool {
+	key, quit := queue.Get()
+	if quit {
+		return false
+	}
+	defer queue.Done(key)
+
+	if err := reconcile(key.(string)); err != nil {
+		log.Printf("failed to reconcile %s %s/%s: %v", kind, namespace, key, err)
+		queue.AddRateLimited(key)
+		return true
+	}
+
+	queue.Forget(key)
+	return true
+}
+
+func (c *Controller) reconcile(configMapName string) error {
+	configMap, err := c.configMapLister.Get(configMapName)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	image := configMap.Annotations[types.CheckupImageAnnotation]
+	if image == "" && !isSuite(configMap) {
+		return nil
+	}
+
+	if rawSchedule, exists := configMap.Annotations[types.ScheduleAnnotation]; exists {
+		return c.reconcileSchedule(configMap, rawSchedule)
+	}
+
+	if isSuite(configMap) {
+		return c.reconcileSuite(configMap)
+	}
+
+	checkupJob, err := c.jobLister.Get(job.NameFor(configMapName))
+	if k8serrors.IsNotFound(err) {
+		if isCompleted(configMap) && !isRerunnable(configMap) {
+			return nil
+		}
+		return c.launch(configMap, image)
+	}
+	if err != nil {
+		return err
+	}
+
+	if isCompleted(configMap) {
+		return nil
+	}
+
+	if finished, failed := job.Finished(checkupJob); finished {
+		return c.markAsFailed(configMapName, jobFailureReason(checkupJob, failed))
+	}
+
+	return nil
+}
+
+func (c *Controller) launch(configMap *corev1.ConfigMap, image string) error {
+	if _, started := configMap.Data[types.StartTimestampKey]; started && !isCompleted(configMap) {
+		// The checkup is executed by other means, there is nothing to launch.
+		return nil
+	}
+
+	serviceAccountName := configMap.Annotations[types.CheckupServiceAccountAnnotation]
+	newJob := job.New(configMap, job.NameFor(configMap.Name), image, serviceAccountName)
+
+	log.Printf("launching checkup Job %s/%s with image %q", newJob.Namespace, newJob.Name, image)
+	if _, err := job.Create(c.client, newJob); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	return nil
+}
+
+// markAsFailed reports a failed completion, unless the checkup has already reported its own.
+// The ConfigMap is re-read from the cluster, as the cached object may not contain the checkup final report yet.
+func (c *Controller) markAsFailed(configMapName, reason string) error {
+	configMap, err := configmap.Get(c.client, c.namespace, configMapName)
+	if err != nil {
+		return err
+	}
+
+	if isCompleted(configMap) {
+		return nil
+	}
+
+	log.Printf("marking ConfigMap %s/%s as failed: %s", c.namespace, configMapName, reason)
+	err = reporter.New(c.client, c.namespace, configMapName).Report(status.Status{
+		CompletionTimestamp: time.Now(),
+		FailureReason:       []string{reason},
+		FailureDetails:      []failure.Failure{{Code: failure.CodeJobFailed, Message: reason}},
+		Phase:               status.PhaseCompleted,
+	})
+	if err != nil {
+		return err
+	}
+
+	events.NewRecorder(c.client, c.namespace, configMapName, string(configMap.UID)).
+		Event(corev1.EventTypeWarning, events.ReasonFailed, reason)
+	return nil
+}
+
+// reconcileSuite launches the suite members which are due, cancels the running ones once the suite stops,
+// and reports the aggregated suite status to its ConfigMap.
+// The suite ConfigMap is re-read from the cluster, as the cached object may not contain the last reported status yet.
+func (c *Controller) reconcileSuite(cachedSuiteConfigMap *corev1.ConfigMap) error {
+	suiteConfigMap, err := configmap.Get(c.client, c.namespace, cachedSuiteConfigMap.Name)
+	if err != nil {
+		return err
+	}
+
+	if isCompleted(suiteConfigMap) {
+		return nil
+	}
+
+	recorder := events.NewRecorder(c.client, c.namespace, suiteConfigMap.Name, string(suiteConfigMap.UID))
+
+	checkupSuite, err := suite.Parse(suiteConfigMap)
+	if err != nil {
+		return c.reportSuiteStatus(recorder, suiteConfigMap, status.Status{
+			StartTimestamp:      time.Now(),
+			CompletionTimestamp: time.Now(),
+			FailureReason:       []string{err.Error()},
+			FailureDetails:      []failure.Failure{{Code: failure.CodeInvalidInput, Message: err.Error()}},
+			Phase:               status.PhaseCompleted,
+		})
+	}
+
+	memberConfigMaps, err := suite.ListMembers(c.client, c.namespace, suiteConfigMap.Name)
+	if err != nil {
+		return err
+	}
+
+	progress := checkupSuite.Evaluate(memberConfigMaps)
+
+	for _, member := range progress.Launch {
+		memberConfigMap := suite.NewMemberConfigMap(suiteConfigMap, member)
+		log.Printf("launching suite member ConfigMap %s/%s", memberConfigMap.Namespace, memberConfigMap.Name)
+		_, err := c.client.CoreV1().ConfigMaps(c.namespace).Create(context.Background(), memberConfigMap, metav1.CreateOptions{})
+		if err != nil && !k8serrors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+
+	for _, member := range progress.Cancel {
+		if err := c.cancelSuiteMember(suiteConfigMap.Name, member.Name); err != nil {
+			return err
+		}
+	}
+
+	startTimestamp, err := time.Parse(time.RFC3339, suiteConfigMap.Data[types.StartTimestampKey])
+	if err != nil {
+		startTimestamp = time.Now()
+		recorder.Event(corev1.EventTypeNormal, events.ReasonStarted, "Suite started")
+	}
+
+	return c.reportSuiteStatus(recorder, suiteConfigMap, progress.Status(suiteConfigMap, startTimestamp, time.Now()))
+}
+
+// reportSuiteStatus reports the suite status, unless it has not changed since it was last reported.
+func (c *Controller) reportSuiteStatus(recorder *events.Recorder, suiteConfigMap *corev1.ConfigMap, suiteStatus status.Status) error {
+	data, err := status.ToConfigMapData(suiteStatus)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for k, v := range data {
+		if suiteConfigMap.Data[k] != v {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := reporter.New(c.client, c.namespace, suiteConfigMap.Name).Report(suiteStatus); err != nil {
+		return err
+	}
+
+	if suiteStatus.Phase != status.PhaseCompleted {
+		return nil
+	}
+	log.Printf("suite ConfigMap %s/%s has completed, succeeded: %v", c.namespace, suiteConfigMap.Name, suiteStatus.Succeeded)
+	if suiteStatus.Succeeded {
+		recorder.Event(corev1.EventTypeNormal, events.ReasonSucceeded, "Suite succeeded")
+	} else {
+		recorder.Event(corev1.EventTypeWarning, events.ReasonFailed, strings.Join(suiteStatus.FailureReason, ", "))
+	}
+	return nil
+}
+
+func (c *Controller) cancelSuiteMember(suiteName, memberName string) error {
+	memberConfigMapName := suite.MemberNameFor(suiteName, memberName)
+	log.Printf("cancelling suite member ConfigMap %s/%s", c.namespace, memberConfigMapName)
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{types.CancelAnnotation: "true"},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = configmap.Patch(c.client, c.namespace, memberConfigMapName, patch)
+	return err
+}
+
+func isSuite(configMap *corev1.ConfigMap) bool {
+	_, exists := configMap.Annotations[types.SuiteAnnotation]
+	return exists
+}
+
+// isRerunnable reports whether a completed checkup should be executed again once its Job is deleted.
+func isRerunnable(configMap *corev1.ConfigMap) bool {
+	rerunnable, err := strconv.ParseBool(configMap.Data[types.RerunnableKey])
+	return err == nil && rerunnable
+}
+
+func isCompleted(configMap *corev1.ConfigMap) bool {
+	_, exists := configMap.Data[types.CompletionTimestampKey]
+	return exists
+}
+
+func jobFailureReason(checkupJob *batchv1.Job, failed bool) string {
+	if failed {
+		return fmt.Sprintf("checkup job %q failed without reporting a completion: %s", checkupJob.Name, job.FailureMessage(checkupJob))
+	}
+	return fmt.Sprintf("checkup job %q completed without reporting a completion", checkupJob.Name)
+}
+
+// reconcileSchedule creates the run of the latest scheduled time which has passed, unless a previous run is still active,
+// prunes the completed runs beyond the retention, and summarizes the remaining ones.
+// The template is requeued for the next scheduled time.
+func (c *Controller) reconcileSchedule(template *corev1.ConfigMap, rawSchedule string) error {
+	recorder := events.NewRecorder(c.client, c.namespace, template.Name, string(template.UID))
+
+	checkupSchedule, err := schedule.Parse(rawSchedule)
+	if err != nil {
+		return c.invalidSchedule(recorder, template, err)
+	}
+	retention, err := schedule.Retention(template)
+	if err != nil {
+		return c.invalidSchedule(recorder, template, err)
+	}
+
+	runs, err := schedule.ListRuns(c.client, c.namespace, template.Name)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if scheduledTime := latestScheduledTime(checkupSchedule, lastScheduleTime(template), now); !scheduledTime.IsZero() {
+		if err := c.scheduleRun(recorder, template, runs, scheduledTime); err != nil {
+			return err
+		}
+		if runs, err = schedule.ListRuns(c.client, c.namespace, template.Name); err != nil {
+			return err
+		}
+	}
+
+	if runs, err = schedule.Prune(c.client, runs, retention); err != nil {
+		return err
+	}
+
+	if err := schedule.ApplySummary(c.client, template, runs); err != nil {
+		return err
+	}
+
+	if next := checkupSchedule.Next(now); !next.IsZero() {
+		c.queue.AddAfter(template.Name, next.Sub(now))
+	}
+	return nil
+}
+
+func (c *Controller) scheduleRun(
+	recorder *events.Recorder, template *corev1.ConfigMap, runs []corev1.ConfigMap, scheduledTime time.Time,
+) error {
+	if activeRun := activeRun(runs); activeRun != "" {
+		log.Printf("skipping the run of ConfigMap %s/%s scheduled at %s: run %q is still active",
+			c.namespace, template.Name, scheduledTime.UTC().Format(time.RFC3339), activeRun)
+		recorder.Event(corev1.EventTypeNormal, events.ReasonScheduleSkipped,
+			fmt.Sprintf("Skipped the run scheduled at %s, as run %q is still active", scheduledTime.UTC().Format(time.RFC3339), activeRun))
+	} else {
+		run := schedule.NewRun(template, scheduledTime)
+		log.Printf("creating scheduled run ConfigMap %s/%s", run.Namespace, run.Name)
+		_, err := c.client.CoreV1().ConfigMaps(c.namespace).Create(context.Background(), run, metav1.CreateOptions{})
+		if err != nil && !k8serrors.IsAlreadyExists(err) {
+			return err
+		}
+		recorder.Event(corev1.EventTypeNormal, events.ReasonScheduled, fmt.Sprintf("Created run %q", run.Name))
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{types.LastScheduleTimeAnnotation: scheduledTime.UTC().Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = configmap.Patch(c.client, c.namespace, template.Name, patch)
+	return err
+}
+
+// invalidSchedule reports a schedule which cannot be acted upon. It is not retried until the template is updated.
+func (c *Controller) invalidSchedule(recorder *events.Recorder, template *corev1.ConfigMap, err error) error {
+	log.Printf("ConfigMap %s/%s has an invalid schedule: %v", c.namespace, template.Name, err)
+	recorder.Event(corev1.EventTypeWarning, events.ReasonInvalidSchedule, err.Error())
+	return nil
+}
+
+// lastScheduleTime returns the last time a run was scheduled at, or the template creation time in case none was.
+func lastScheduleTime(template *corev1.ConfigMap) time.Time {
+	if lastTime, err := time.Parse(time.RFC3339, template.Annotations[types.LastScheduleTimeAnnotation]); err == nil {
+		return lastTime
+	}
+	return template.CreationTimestamp.Time
+}
+
+// latestScheduledTime returns the latest scheduled time after the last one and until now.
+// Missed scheduled times before it are not run.
+// It returns the zero time in case no run is due yet.
+func latestScheduledTime(checkupSchedule schedule.Schedule, lastTime, now time.Time) time.Time {
+	if lastTime.IsZero() {
+		lastTime = now
+	}
+
+	var latest time.Time
+	for next := checkupSchedule.Next(lastTime); !next.IsZero() && !next.After(now); next = checkupSchedule.Next(next) {
+		latest = next
+	}
+	return latest
+}
+
+func activeRun(runs []corev1.ConfigMap) string {
+	for i := range runs {
+		if !schedule.IsCompleted(&runs[i]) {
+			return runs[i].Name
+		}
+	}
+	return ""
+}
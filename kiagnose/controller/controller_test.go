@@ -0,0 +1,553 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package controller_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	checkupv1alpha1 "github.com/kiagnose/kiagnose/kiagnose/apis/checkup/v1alpha1"
+	"github.com/kiagnose/kiagnose/kiagnose/checkup"
+	checkupfake "github.com/kiagnose/kiagnose/kiagnose/client/clientset/versioned/fake"
+	"github.com/kiagnose/kiagnose/kiagnose/config"
+	"github.com/kiagnose/kiagnose/kiagnose/configmap"
+	"github.com/kiagnose/kiagnose/kiagnose/controller"
+	"github.com/kiagnose/kiagnose/kiagnose/events"
+	"github.com/kiagnose/kiagnose/kiagnose/job"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const (
+	testNamespace          = "target-ns"
+	testConfigMapName      = "checkup1"
+	testCheckupName        = "checkup2"
+	testImage              = "my-registry/example-checkup:main"
+	testServiceAccountName = "example-sa"
+
+	waitTimeout  = 5 * time.Second
+	pollInterval = 10 * time.Millisecond
+)
+
+func TestControllerShouldLaunchJob(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(newAnnotatedConfigMap(map[string]string{
+		types.TimeoutKey:         "1m",
+		types.TeardownTimeoutKey: "5m",
+	}))
+	runController(t, fakeClient)
+
+	checkupJob := waitForJob(t, fakeClient)
+
+	assert.Equal(t, testServiceAccountName, checkupJob.Spec.Template.Spec.ServiceAccountName)
+	assert.Len(t, checkupJob.Spec.Template.Spec.Containers, 1)
+	container := checkupJob.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, testImage, container.Image)
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: config.ConfigMapNamespaceEnvVarName, Value: testNamespace})
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: config.ConfigMapNameEnvVarName, Value: testConfigMapName})
+	assert.Equal(t, testConfigMapName, checkupJob.OwnerReferences[0].Name)
+
+	// The teardown timeout, and the time to store the artifacts and to report the completion.
+	const expectedTerminationGracePeriodSeconds = int64(390)
+	assert.Equal(t, expectedTerminationGracePeriodSeconds, *checkupJob.Spec.Template.Spec.TerminationGracePeriodSeconds)
+}
+
+func TestControllerShouldRelaunchJobOfRerunnableConfigMap(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(newAnnotatedConfigMap(map[string]string{
+		types.TimeoutKey:             "1m",
+		types.RerunnableKey:          "true",
+		types.StartTimestampKey:      time.Now().Format(time.RFC3339),
+		types.CompletionTimestampKey: time.Now().Format(time.RFC3339),
+	}))
+	runController(t, fakeClient)
+
+	waitForJob(t, fakeClient)
+}
+
+func TestControllerShouldNotLaunchJob(t *testing.T) {
+	t.Run("when ConfigMap is not annotated with a checkup image", func(t *testing.T) {
+		configMap := newAnnotatedConfigMap(map[string]string{types.TimeoutKey: "1m"})
+		configMap.Annotations = nil
+		fakeClient := fake.NewSimpleClientset(configMap)
+		runController(t, fakeClient)
+
+		assertNoJobs(t, fakeClient)
+	})
+
+	t.Run("when ConfigMap is already in use", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newAnnotatedConfigMap(map[string]string{
+			types.TimeoutKey:        "1m",
+			types.StartTimestampKey: time.Now().Format(time.RFC3339),
+		}))
+		runController(t, fakeClient)
+
+		assertNoJobs(t, fakeClient)
+	})
+
+	t.Run("when ConfigMap has completed and is not re-runnable", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newAnnotatedConfigMap(map[string]string{
+			types.TimeoutKey:             "1m",
+			types.StartTimestampKey:      time.Now().Format(time.RFC3339),
+			types.CompletionTimestampKey: time.Now().Format(time.RFC3339),
+		}))
+		runController(t, fakeClient)
+
+		assertNoJobs(t, fakeClient)
+	})
+}
+
+func TestControllerShouldMarkConfigMapAsFailed(t *testing.T) {
+	t.Run("when Job fails without reporting a completion", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newAnnotatedConfigMap(map[string]string{types.TimeoutKey: "1m"}))
+		runController(t, fakeClient)
+
+		checkupJob := waitForJob(t, fakeClient)
+		setJobCondition(t, fakeClient, checkupJob, batchv1.JobFailed, "BackoffLimitExceeded")
+
+		data := waitForCompletion(t, fakeClient)
+		assert.Equal(t, "false", data[types.SucceededKey])
+		assert.Contains(t, data[types.FailureReasonKey], "BackoffLimitExceeded")
+		assert.Contains(t, data[types.FailureDetailsKey], `"code":"JobFailed"`)
+
+		waitForEvent(t, fakeClient, events.ReasonFailed)
+	})
+
+	t.Run("when Job completes without reporting a completion", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newAnnotatedConfigMap(map[string]string{types.TimeoutKey: "1m"}))
+		runController(t, fakeClient)
+
+		checkupJob := waitForJob(t, fakeClient)
+		setJobCondition(t, fakeClient, checkupJob, batchv1.JobComplete, "")
+
+		data := waitForCompletion(t, fakeClient)
+		assert.Equal(t, "false", data[types.SucceededKey])
+		assert.Contains(t, data[types.FailureReasonKey], "without reporting a completion")
+	})
+}
+
+func TestControllerShouldNotOverrideCheckupReport(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(newAnnotatedConfigMap(map[string]string{types.TimeoutKey: "1m"}))
+	runController(t, fakeClient)
+
+	checkupJob := waitForJob(t, fakeClient)
+
+	configMap, err := configmap.Get(fakeClient, testNamespace, testConfigMapName)
+	assert.NoError(t, err)
+	configMap.Data[types.CompletionTimestampKey] = time.Now().Format(time.RFC3339)
+	configMap.Data[types.SucceededKey] = "true"
+	_, err = configmap.Update(fakeClient, configMap)
+	assert.NoError(t, err)
+
+	setJobCondition(t, fakeClient, checkupJob, batchv1.JobComplete, "")
+
+	assert.Never(t, func() bool {
+		actualConfigMap, err := configmap.Get(fakeClient, testNamespace, testConfigMapName)
+		return err != nil || actualConfigMap.Data[types.SucceededKey] != "true"
+	}, time.Second, pollInterval)
+}
+
+func TestControllerShouldLaunchJobForCheckup(t *testing.T) {
+	checkupObj := newAnnotatedCheckup()
+	checkupObj.Spec.TeardownTimeout = &metav1.Duration{Duration: 5 * time.Minute}
+	fakeClient := fake.NewSimpleClientset()
+	fakeCheckupClient := checkupfake.NewSimpleClientset(checkupObj)
+	runController(t, fakeClient, controller.WithCheckupClient(fakeCheckupClient))
+
+	checkupJob := waitForCheckupJob(t, fakeClient)
+
+	assert.Equal(t, testServiceAccountName, checkupJob.Spec.Template.Spec.ServiceAccountName)
+	container := checkupJob.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, testImage, container.Image)
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: config.CheckupNamespaceEnvVarName, Value: testNamespace})
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: config.CheckupNameEnvVarName, Value: testCheckupName})
+	assert.Equal(t, "Checkup", checkupJob.OwnerReferences[0].Kind)
+	assert.Equal(t, testCheckupName, checkupJob.OwnerReferences[0].Name)
+
+	// The teardown timeout, and the time to store the artifacts and to report the completion.
+	const expectedTerminationGracePeriodSeconds = int64(390)
+	assert.Equal(t, expectedTerminationGracePeriodSeconds, *checkupJob.Spec.Template.Spec.TerminationGracePeriodSeconds)
+}
+
+func TestControllerShouldNotLaunchJobForCompletedCheckup(t *testing.T) {
+	checkupObj := newAnnotatedCheckup()
+	now := metav1.Now()
+	checkupObj.Status.StartTimestamp = &now
+	checkupObj.Status.CompletionTimestamp = &now
+	fakeClient := fake.NewSimpleClientset()
+	runController(t, fakeClient, controller.WithCheckupClient(checkupfake.NewSimpleClientset(checkupObj)))
+
+	assertNoJobs(t, fakeClient)
+}
+
+func TestControllerShouldMarkCheckupAsFailed(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	fakeCheckupClient := checkupfake.NewSimpleClientset(newAnnotatedCheckup())
+	runController(t, fakeClient, controller.WithCheckupClient(fakeCheckupClient))
+
+	checkupJob := waitForCheckupJob(t, fakeClient)
+	setJobCondition(t, fakeClient, checkupJob, batchv1.JobFailed, "BackoffLimitExceeded")
+
+	var checkupObj *checkupv1alpha1.Checkup
+	assert.Eventually(t, func() bool {
+		var err error
+		checkupObj, err = checkup.Get(fakeCheckupClient, testNamespace, testCheckupName)
+		return err == nil && checkupObj.Status.CompletionTimestamp != nil
+	}, waitTimeout, pollInterval)
+
+	succeeded := meta.FindStatusCondition(checkupObj.Status.Conditions, checkupv1alpha1.ConditionSucceeded)
+	assert.NotNil(t, succeeded)
+	assert.Equal(t, metav1.ConditionFalse, succeeded.Status)
+	assert.Contains(t, succeeded.Message, "BackoffLimitExceeded")
+}
+
+func TestControllerShouldScheduleRuns(t *testing.T) {
+	template := newScheduleTemplate("@every 1s")
+	template.Annotations[types.ScheduleRetentionAnnotation] = "1"
+	fakeClient := fake.NewSimpleClientset(template)
+	runController(t, fakeClient)
+
+	firstRun := waitForScheduledRun(t, fakeClient, "")
+	assert.Equal(t, testImage, firstRun.Annotations[types.CheckupImageAnnotation])
+	assert.NotContains(t, firstRun.Annotations, types.ScheduleAnnotation)
+	assert.Equal(t, "1m", firstRun.Data[types.TimeoutKey])
+	assert.Equal(t, testConfigMapName, firstRun.OwnerReferences[0].Name)
+	waitForEvent(t, fakeClient, events.ReasonScheduled)
+
+	assert.Eventually(t, func() bool {
+		_, err := fakeClient.BatchV1().Jobs(testNamespace).Get(context.Background(), job.NameFor(firstRun.Name), metav1.GetOptions{})
+		return err == nil
+	}, waitTimeout, pollInterval)
+	_, err := fakeClient.BatchV1().Jobs(testNamespace).Get(context.Background(), job.NameFor(testConfigMapName), metav1.GetOptions{})
+	assert.Error(t, err, "the template should not be launched")
+
+	completeRun(t, fakeClient, firstRun.Name, "true", "10")
+	secondRun := waitForScheduledRun(t, fakeClient, firstRun.Name)
+	completeRun(t, fakeClient, secondRun.Name, "false", "20")
+
+	assert.Eventually(t, func() bool {
+		summary, err := configmap.Get(fakeClient, testNamespace, testConfigMapName+"-summary")
+		return err == nil && summary.Data[types.SummaryLastRunKey] == secondRun.Name
+	}, waitTimeout, pollInterval)
+	summary, err := configmap.Get(fakeClient, testNamespace, testConfigMapName+"-summary")
+	assert.NoError(t, err)
+	assert.Equal(t, "0", summary.Data[types.SummarySucceededKey])
+	assert.Equal(t, "1", summary.Data[types.SummaryFailedKey])
+	assert.Equal(t, "20", summary.Data[types.SummaryResultsKeyPrefix+"latency.last"])
+
+	assert.Eventually(t, func() bool {
+		_, err := configmap.Get(fakeClient, testNamespace, firstRun.Name)
+		return err != nil
+	}, waitTimeout, pollInterval, "the first run should be pruned")
+}
+
+func TestControllerShouldSkipScheduledRunWhileRunIsActive(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(newScheduleTemplate("@every 1s"))
+	runController(t, fakeClient)
+
+	run := waitForScheduledRun(t, fakeClient, "")
+	waitForEvent(t, fakeClient, events.ReasonScheduleSkipped)
+
+	runs, err := fakeClient.CoreV1().ConfigMaps(testNamespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: types.ScheduledByLabel + "=" + testConfigMapName,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, runs.Items, 1)
+	assert.Equal(t, run.Name, runs.Items[0].Name)
+}
+
+func TestControllerShouldReportInvalidSchedule(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(newScheduleTemplate("every day"))
+	runController(t, fakeClient)
+
+	waitForEvent(t, fakeClient, events.ReasonInvalidSchedule)
+	assertNoJobs(t, fakeClient)
+}
+
+func TestControllerShouldRunSuiteMembersSerially(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(newSuiteConfigMap(map[string]string{types.SuiteModeKey: "serial"}))
+	runController(t, fakeClient)
+
+	first := waitForConfigMap(t, fakeClient, testConfigMapName+"-first")
+	assert.Equal(t, testImage, first.Annotations[types.CheckupImageAnnotation])
+	assert.Equal(t, map[string]string{types.TimeoutKey: "1m"}, first.Data)
+	assert.Eventually(t, func() bool {
+		_, err := fakeClient.BatchV1().Jobs(testNamespace).Get(context.Background(), job.NameFor(first.Name), metav1.GetOptions{})
+		return err == nil
+	}, waitTimeout, pollInterval)
+	assertNoConfigMap(t, fakeClient, testConfigMapName+"-second")
+
+	completeRun(t, fakeClient, first.Name, "true", "10")
+	second := waitForConfigMap(t, fakeClient, testConfigMapName+"-second")
+	completeRun(t, fakeClient, second.Name, "true", "20")
+
+	data := waitForCompletion(t, fakeClient)
+	assert.Equal(t, "true", data[types.SucceededKey])
+	assert.Equal(t, "Succeeded", data[types.ResultsPrefix+"first"])
+	assert.Equal(t, "Succeeded", data[types.ResultsPrefix+"second"])
+	waitForEvent(t, fakeClient, events.ReasonSucceeded)
+}
+
+func TestControllerShouldStopSuiteOnFailure(t *testing.T) {
+	t.Run("by skipping the following members of a serial suite", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newSuiteConfigMap(map[string]string{types.SuiteStopOnFailureKey: "true"}))
+		runController(t, fakeClient)
+
+		first := waitForConfigMap(t, fakeClient, testConfigMapName+"-first")
+		completeRun(t, fakeClient, first.Name, "false", "10")
+
+		data := waitForCompletion(t, fakeClient)
+		assert.Equal(t, "false", data[types.SucceededKey])
+		assert.Equal(t, "Skipped", data[types.ResultsPrefix+"second"])
+		assertNoConfigMap(t, fakeClient, testConfigMapName+"-second")
+		waitForEvent(t, fakeClient, events.ReasonFailed)
+	})
+
+	t.Run("by cancelling the running members of a parallel suite", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newSuiteConfigMap(map[string]string{
+			types.SuiteModeKey:          "parallel",
+			types.SuiteStopOnFailureKey: "true",
+		}))
+		runController(t, fakeClient)
+
+		first := waitForConfigMap(t, fakeClient, testConfigMapName+"-first")
+		second := waitForConfigMap(t, fakeClient, testConfigMapName+"-second")
+		completeRun(t, fakeClient, first.Name, "false", "10")
+
+		assert.Eventually(t, func() bool {
+			configMap, err := configmap.Get(fakeClient, testNamespace, second.Name)
+			return err == nil && configMap.Annotations[types.CancelAnnotation] == "true"
+		}, waitTimeout, pollInterval)
+		completeRun(t, fakeClient, second.Name, "false", "20")
+
+		data := waitForCompletion(t, fakeClient)
+		assert.Equal(t, "false", data[types.SucceededKey])
+		assert.Equal(t, "Failed", data[types.ResultsPrefix+"first"])
+		assert.Equal(t, "Cancelled", data[types.ResultsPrefix+"second"])
+	})
+}
+
+func TestControllerShouldFailInvalidSuite(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(newSuiteConfigMap(map[string]string{types.SuiteModeKey: "random"}))
+	runController(t, fakeClient)
+
+	data := waitForCompletion(t, fakeClient)
+	assert.Equal(t, "false", data[types.SucceededKey])
+	assert.Contains(t, data[types.FailureReasonKey], "suite mode field is illegal")
+}
+
+func runController(t *testing.T, client kubernetes.Interface, options ...controller.Option) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go func() {
+		_ = controller.New(client, testNamespace, options...).Run(ctx, 1)
+	}()
+}
+
+func waitForJob(t *testing.T, client kubernetes.Interface) *batchv1.Job {
+	var checkupJob *batchv1.Job
+	assert.Eventually(t, func() bool {
+		var err error
+		checkupJob, err = client.BatchV1().Jobs(testNamespace).Get(
+			context.Background(), job.NameFor(testConfigMapName), metav1.GetOptions{})
+		return err == nil
+	}, waitTimeout, pollInterval)
+
+	return checkupJob
+}
+
+func waitForCheckupJob(t *testing.T, client kubernetes.Interface) *batchv1.Job {
+	var checkupJob *batchv1.Job
+	assert.Eventually(t, func() bool {
+		var err error
+		checkupJob, err = client.BatchV1().Jobs(testNamespace).Get(
+			context.Background(), job.NameForCheckup(testCheckupName), metav1.GetOptions{})
+		return err == nil
+	}, waitTimeout, pollInterval)
+
+	return checkupJob
+}
+
+func assertNoJobs(t *testing.T, client kubernetes.Interface) {
+	assert.Never(t, func() bool {
+		jobs, err := client.BatchV1().Jobs(testNamespace).List(context.Background(), metav1.ListOptions{})
+		return err != nil || len(jobs.Items) > 0
+	}, time.Second, pollInterval)
+}
+
+func waitForCompletion(t *testing.T, client kubernetes.Interface) map[string]string {
+	var data map[string]string
+	assert.Eventually(t, func() bool {
+		configMap, err := configmap.Get(client, testNamespace, testConfigMapName)
+		if err != nil {
+			return false
+		}
+		data = configMap.Data
+		_, completed := data[types.CompletionTimestampKey]
+		return completed
+	}, waitTimeout, pollInterval)
+
+	return data
+}
+
+func waitForEvent(t *testing.T, client kubernetes.Interface, reason string) {
+	assert.Eventually(t, func() bool {
+		eventList, err := client.CoreV1().Events(testNamespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return false
+		}
+		for _, event := range eventList.Items {
+			if event.Reason == reason && event.InvolvedObject.Name == testConfigMapName {
+				return true
+			}
+		}
+		return false
+	}, waitTimeout, pollInterval)
+}
+
+func setJobCondition(t *testing.T, client kubernetes.Interface, checkupJob *batchv1.Job, conditionType batchv1.JobConditionType, message string) {
+	checkupJob.Status.Conditions = append(checkupJob.Status.Conditions, batchv1.JobCondition{
+		Type:    conditionType,
+		Status:  corev1.ConditionTrue,
+		Message: message,
+	})
+	_, err := client.BatchV1().Jobs(testNamespace).UpdateStatus(context.Background(), checkupJob, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+}
+
+// waitForScheduledRun waits for a run of the test template other than the given one.
+func waitForScheduledRun(t *testing.T, client kubernetes.Interface, previousRunName string) *corev1.ConfigMap {
+	var run *corev1.ConfigMap
+	assert.Eventually(t, func() bool {
+		runs, err := client.CoreV1().ConfigMaps(testNamespace).List(context.Background(), metav1.ListOptions{
+			LabelSelector: types.ScheduledByLabel + "=" + testConfigMapName,
+		})
+		if err != nil {
+			return false
+		}
+		for i := range runs.Items {
+			if runs.Items[i].Name != previousRunName {
+				run = &runs.Items[i]
+				return true
+			}
+		}
+		return false
+	}, waitTimeout, pollInterval)
+
+	return run
+}
+
+func completeRun(t *testing.T, client kubernetes.Interface, runName, succeeded, latency string) {
+	run, err := configmap.Get(client, testNamespace, runName)
+	assert.NoError(t, err)
+
+	if run.Data == nil {
+		run.Data = map[string]string{}
+	}
+	now := time.Now().Format(time.RFC3339)
+	run.Data[types.StartTimestampKey] = now
+	run.Data[types.CompletionTimestampKey] = now
+	run.Data[types.SucceededKey] = succeeded
+	run.Data[types.ResultsPrefix+"latency"] = latency
+	_, err = configmap.Update(client, run)
+	assert.NoError(t, err)
+}
+
+func waitForConfigMap(t *testing.T, client kubernetes.Interface, name string) *corev1.ConfigMap {
+	var configMap *corev1.ConfigMap
+	assert.Eventually(t, func() bool {
+		var err error
+		configMap, err = configmap.Get(client, testNamespace, name)
+		return err == nil
+	}, waitTimeout, pollInterval)
+
+	return configMap
+}
+
+func assertNoConfigMap(t *testing.T, client kubernetes.Interface, name string) {
+	assert.Never(t, func() bool {
+		_, err := configmap.Get(client, testNamespace, name)
+		return err == nil
+	}, time.Second, pollInterval)
+}
+
+func newSuiteConfigMap(data map[string]string) *corev1.ConfigMap {
+	suiteData := map[string]string{
+		types.SuiteMembersKey:       "first,second",
+		"spec.member.first.image":   testImage,
+		"spec.member.first.timeout": "1m",
+		"spec.member.second.image":  testImage,
+	}
+	for k, v := range data {
+		suiteData[k] = v
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        testConfigMapName,
+			Namespace:   testNamespace,
+			Annotations: map[string]string{types.SuiteAnnotation: "true"},
+		},
+		Data: suiteData,
+	}
+}
+
+func newScheduleTemplate(rawSchedule string) *corev1.ConfigMap {
+	template := newAnnotatedConfigMap(map[string]string{types.TimeoutKey: "1m"})
+	template.Annotations[types.ScheduleAnnotation] = rawSchedule
+	template.CreationTimestamp = metav1.Now()
+	return template
+}
+
+func newAnnotatedConfigMap(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testConfigMapName,
+			Namespace: testNamespace,
+			Annotations: map[string]string{
+				types.CheckupImageAnnotation:          testImage,
+				types.CheckupServiceAccountAnnotation: testServiceAccountName,
+			},
+		},
+		Data: data,
+	}
+}
+
+func newAnnotatedCheckup() *checkupv1alpha1.Checkup {
+	return &checkupv1alpha1.Checkup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testCheckupName,
+			Namespace: testNamespace,
+			Annotations: map[string]string{
+				types.CheckupImageAnnotation:          testImage,
+				types.CheckupServiceAccountAnnotation: testServiceAccountName,
+			},
+		},
+		Spec: checkupv1alpha1.CheckupSpec{Timeout: metav1.Duration{Duration: time.Minute}},
+	}
+}
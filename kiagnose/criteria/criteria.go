@@ -0,0 +1,274 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+// Package criteria implements a small expression language, used to decide whether a checkup succeeded based on its results.
+//
+// Expressions combine the checkup results, referenced by name, with number, string and boolean literals using
+// the operators `||`, `&&`, `!`, `==`, `!=`, `<`, `<=`, `>`, `>=`, `+`, `-`, `*` and `/`, and parentheses.
+// Results are typed by their value: numbers, "true" and "false" are numbers and booleans, other values are strings.
+package criteria
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrMissingResult = errors.New("result is missing")
+	ErrTypeMismatch  = errors.New("type mismatch")
+)
+
+// Expression is a parsed success criteria expression.
+type Expression struct {
+	source string
+	root   node
+}
+
+// Parse parses the expression, which should evaluate to a boolean.
+func Parse(source string) (*Expression, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, errors.New("expression is empty")
+	}
+
+	root, err := parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Expression{source: source, root: root}, nil
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Evaluate reports whether the results meet the expression.
+func (e *Expression) Evaluate(results map[string]string) (bool, error) {
+	v, err := e.root.evaluate(results)
+	if err != nil {
+		return false, err
+	}
+
+	if v.kind != kindBool {
+		return false, fmt.Errorf("%w: expression evaluates to a %s, not a bool", ErrTypeMismatch, v.kind)
+	}
+	return v.boolean, nil
+}
+
+// Identifiers returns the names of the results the expression references, sorted.
+func (e *Expression) Identifiers() []string {
+	names := map[string]struct{}{}
+	collectIdentifiers(e.root, names)
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+func collectIdentifiers(n node, names map[string]struct{}) {
+	switch n := n.(type) {
+	case identifier:
+		names[n.name] = struct{}{}
+	case unary:
+		collectIdentifiers(n.operand, names)
+	case binary:
+		collectIdentifiers(n.left, names)
+		collectIdentifiers(n.right, names)
+	}
+}
+
+// floatBitSize is the precision numbers are parsed with.
+const floatBitSize = 64
+
+type kind string
+
+const (
+	kindNumber kind = "number"
+	kindString kind = "string"
+	kindBool   kind = "bool"
+)
+
+type value struct {
+	kind    kind
+	number  float64
+	text    string
+	boolean bool
+}
+
+func numberValue(number float64) value {
+	return value{kind: kindNumber, number: number}
+}
+
+func stringValue(text string) value {
+	return value{kind: kindString, text: text}
+}
+
+func boolValue(boolean bool) value {
+	return value{kind: kindBool, boolean: boolean}
+}
+
+// resultValue types a raw result value.
+func resultValue(raw string) value {
+	if number, err := strconv.ParseFloat(raw, floatBitSize); err == nil {
+		return numberValue(number)
+	}
+	if boolean, err := strconv.ParseBool(raw); err == nil && (raw == "true" || raw == "false") {
+		return boolValue(boolean)
+	}
+	return stringValue(raw)
+}
+
+func (n literal) evaluate(map[string]string) (value, error) {
+	return n.value, nil
+}
+
+func (n identifier) evaluate(results map[string]string) (value, error) {
+	raw, exists := results[n.name]
+	if !exists {
+		return value{}, fmt.Errorf("%w: %q", ErrMissingResult, n.name)
+	}
+	return resultValue(raw), nil
+}
+
+func (n unary) evaluate(results map[string]string) (value, error) {
+	operand, err := n.operand.evaluate(results)
+	if err != nil {
+		return value{}, err
+	}
+
+	switch {
+	case n.operator == "!" && operand.kind == kindBool:
+		return boolValue(!operand.boolean), nil
+	case n.operator == "-" && operand.kind == kindNumber:
+		return numberValue(-operand.number), nil
+	}
+	return value{}, fmt.Errorf("%w: %s%s", ErrTypeMismatch, n.operator, operand.kind)
+}
+
+func (n binary) evaluate(results map[string]string) (value, error) {
+	left, err := n.left.evaluate(results)
+	if err != nil {
+		return value{}, err
+	}
+
+	// Short-circuit the logical operators, so e.g. a missing result may be guarded.
+	if left.kind == kindBool && (n.operator == "&&" && !left.boolean || n.operator == "||" && left.boolean) {
+		return left, nil
+	}
+
+	right, err := n.right.evaluate(results)
+	if err != nil {
+		return value{}, err
+	}
+
+	if left.kind != right.kind {
+		return value{}, n.mismatch(left, right)
+	}
+
+	var result value
+	var supported bool
+	switch left.kind {
+	case kindBool:
+		result, supported = n.evaluateBool(left.boolean, right.boolean)
+	case kindNumber:
+		if n.operator == "/" && right.number == 0 {
+			return value{}, errors.New("division by zero")
+		}
+		result, supported = n.evaluateNumber(left.number, right.number)
+	default:
+		result, supported = n.evaluateString(left.text, right.text)
+	}
+
+	if !supported {
+		return value{}, n.mismatch(left, right)
+	}
+	return result, nil
+}
+
+// evaluateBool reports false when the operator is not supported for bool operands.
+func (n binary) evaluateBool(left, right bool) (value, bool) {
+	switch n.operator {
+	case "&&", "||":
+		// The left operand did not short-circuit the evaluation.
+		return boolValue(right), true
+	case "==":
+		return boolValue(left == right), true
+	case "!=":
+		return boolValue(left != right), true
+	}
+	return value{}, false
+}
+
+// evaluateNumber reports false when the operator is not supported for number operands.
+func (n binary) evaluateNumber(left, right float64) (value, bool) {
+	switch n.operator {
+	case "==":
+		return boolValue(left == right), true
+	case "!=":
+		return boolValue(left != right), true
+	case "<":
+		return boolValue(left < right), true
+	case "<=":
+		return boolValue(left <= right), true
+	case ">":
+		return boolValue(left > right), true
+	case ">=":
+		return boolValue(left >= right), true
+	case "+":
+		return numberValue(left + right), true
+	case "-":
+		return numberValue(left - right), true
+	case "*":
+		return numberValue(left * right), true
+	case "/":
+		return numberValue(left / right), true
+	}
+	return value{}, false
+}
+
+// evaluateString reports false when the operator is not supported for string operands.
+func (n binary) evaluateString(left, right string) (value, bool) {
+	switch n.operator {
+	case "==":
+		return boolValue(left == right), true
+	case "!=":
+		return boolValue(left != right), true
+	case "<":
+		return boolValue(left < right), true
+	case "<=":
+		return boolValue(left <= right), true
+	case ">":
+		return boolValue(left > right), true
+	case ">=":
+		return boolValue(left >= right), true
+	case "+":
+		return stringValue(left + right), true
+	}
+	return value{}, false
+}
+
+func (n binary) mismatch(left, right value) error {
+	return fmt.Errorf("%w: %s %s %s", ErrTypeMismatch, left.kind, n.operator, right.kind)
+}
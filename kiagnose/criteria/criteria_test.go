@@ -0,0 +1,138 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package criteria_test
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/kiagnose/kiagnose/kiagnose/criteria"
+)
+
+var results = map[string]string{
+	"avgLatencyNanoSec": "400000",
+	"maxLatencyNanoSec": "1500000",
+	"sourceNode":        "node1",
+	"targetNode":        "node2",
+	"ratio":             "0.25",
+	"converged":         "true",
+}
+
+func TestEvaluateShouldSucceed(t *testing.T) {
+	type evaluateTestCase struct {
+		expression string
+		expected   bool
+	}
+
+	testCases := []evaluateTestCase{
+		{"avgLatencyNanoSec < 500000 && maxLatencyNanoSec < 2000000", true},
+		{"avgLatencyNanoSec < 500000 && maxLatencyNanoSec < 1000000", false},
+		{"avgLatencyNanoSec > 500000 || maxLatencyNanoSec <= 1500000", true},
+		{"!(avgLatencyNanoSec >= 400000)", false},
+		{"maxLatencyNanoSec / avgLatencyNanoSec < 4", true},
+		{"maxLatencyNanoSec - avgLatencyNanoSec == 1.1e6", true},
+		{"avgLatencyNanoSec * 2 + 1 > 800000", true},
+		{"-ratio < 0 && ratio == .25", true},
+		{"sourceNode != targetNode", true},
+		{`sourceNode == "node1"`, true},
+		{`sourceNode + "/" + targetNode == "node1/node2"`, true},
+		{"converged", true},
+		{"converged == false", false},
+		{"(true || false) && !false", true},
+		{"false && missingResult > 0", false},
+		{"true || missingResult > 0", true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.expression, func(t *testing.T) {
+			expression, err := criteria.Parse(testCase.expression)
+			assert.NoError(t, err)
+
+			actual, err := expression.Evaluate(results)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
+
+func TestParseShouldFail(t *testing.T) {
+	type parseFailureTestCase struct {
+		expression    string
+		expectedError string
+	}
+
+	testCases := []parseFailureTestCase{
+		{"", "expression is empty"},
+		{"avgLatencyNanoSec <", "unexpected end of expression"},
+		{"avgLatencyNanoSec < 5 5", `unexpected "5" at position 22`},
+		{"(avgLatencyNanoSec < 5", "unexpected end of expression"},
+		{"avgLatencyNanoSec = 5", `unexpected character '=' at position 18`},
+		{`sourceNode == "node1`, "unterminated string at position 14"},
+		{"1.2.3 > 0", `illegal number "1.2.3" at position 0`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.expression, func(t *testing.T) {
+			_, err := criteria.Parse(testCase.expression)
+			assert.ErrorContains(t, err, testCase.expectedError)
+		})
+	}
+}
+
+func TestEvaluateShouldFail(t *testing.T) {
+	type evaluateFailureTestCase struct {
+		expression    string
+		expectedError error
+	}
+
+	testCases := []evaluateFailureTestCase{
+		{"missingResult > 0", criteria.ErrMissingResult},
+		{"sourceNode > 0", criteria.ErrTypeMismatch},
+		{"avgLatencyNanoSec && true", criteria.ErrTypeMismatch},
+		{"!sourceNode", criteria.ErrTypeMismatch},
+		{"avgLatencyNanoSec + 1", criteria.ErrTypeMismatch},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.expression, func(t *testing.T) {
+			expression, err := criteria.Parse(testCase.expression)
+			assert.NoError(t, err)
+
+			_, err = expression.Evaluate(results)
+			assert.ErrorIs(t, err, testCase.expectedError)
+		})
+	}
+
+	t.Run("on division by zero", func(t *testing.T) {
+		expression, err := criteria.Parse("avgLatencyNanoSec / 0 > 1")
+		assert.NoError(t, err)
+
+		_, err = expression.Evaluate(results)
+		assert.ErrorContains(t, err, "division by zero")
+	})
+}
+
+func TestIdentifiersShouldListReferencedResults(t *testing.T) {
+	expression, err := criteria.Parse("maxLatencyNanoSec < 2 * avgLatencyNanoSec && avgLatencyNanoSec < 500000")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"avgLatencyNanoSec", "maxLatencyNanoSec"}, expression.Identifiers())
+}
@@ -0,0 +1,149 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package criteria
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenNumber
+	tokenString
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+)
+
+type token struct {
+	kind     tokenKind
+	text     string
+	position int
+}
+
+// operators are ordered so that two-character operators are matched before their one-character prefixes.
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/"}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+
+	for position := 0; position < len(source); {
+		c := rune(source[position])
+
+		switch {
+		case unicode.IsSpace(c):
+			position++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, text: "(", position: position})
+			position++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, text: ")", position: position})
+			position++
+		case c == '"':
+			end, err := stringEnd(source, position)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: source[position:end], position: position})
+			position = end
+		case isDigit(c) || c == '.' && position+1 < len(source) && isDigit(rune(source[position+1])):
+			end := numberEnd(source, position)
+			tokens = append(tokens, token{kind: tokenNumber, text: source[position:end], position: position})
+			position = end
+		case isIdentifierStart(c):
+			end := position + 1
+			for end < len(source) && isIdentifierPart(rune(source[end])) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, text: source[position:end], position: position})
+			position = end
+		default:
+			operator := matchOperator(source[position:])
+			if operator == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, position)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: operator, position: position})
+			position += len(operator)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, position: len(source)}), nil
+}
+
+func stringEnd(source string, start int) (int, error) {
+	for position := start + 1; position < len(source); position++ {
+		switch source[position] {
+		case '\\':
+			position++
+		case '"':
+			return position + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated string at position %d", start)
+}
+
+func numberEnd(source string, start int) int {
+	end := start
+	for end < len(source) && (isDigit(rune(source[end])) || source[end] == '.') {
+		end++
+	}
+
+	// Exponent, e.g. 1e6 or 2.5E-3.
+	if end < len(source) && (source[end] == 'e' || source[end] == 'E') {
+		exponentEnd := end + 1
+		if exponentEnd < len(source) && (source[exponentEnd] == '+' || source[exponentEnd] == '-') {
+			exponentEnd++
+		}
+		if exponentEnd < len(source) && isDigit(rune(source[exponentEnd])) {
+			for exponentEnd < len(source) && isDigit(rune(source[exponentEnd])) {
+				exponentEnd++
+			}
+			end = exponentEnd
+		}
+	}
+
+	return end
+}
+
+func matchOperator(source string) string {
+	for _, operator := range operators {
+		if strings.HasPrefix(source, operator) {
+			return operator
+		}
+	}
+	return ""
+}
+
+func isDigit(c rune) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentifierStart(c rune) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentifierPart(c rune) bool {
+	return isIdentifierStart(c) || isDigit(c) || c == '.'
+}
@@ -0,0 +1,181 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package criteria
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// node is an expression tree node.
+type node interface {
+	evaluate(results map[string]string) (value, error)
+}
+
+type literal struct {
+	value value
+}
+
+type identifier struct {
+	name string
+}
+
+type unary struct {
+	operator string
+	operand  node
+}
+
+type binary struct {
+	operator    string
+	left, right node
+}
+
+// precedences lists the binary operators, from the lowest precedence to the highest.
+var precedences = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/"},
+}
+
+type parser struct {
+	tokens   []token
+	position int
+}
+
+func parse(source string) (node, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, unexpected(next)
+	}
+	return root, nil
+}
+
+func (p *parser) parseBinary(level int) (node, error) {
+	if level == len(precedences) {
+		return p.parseUnary()
+	}
+
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOperator && contains(precedences[level], p.peek().text) {
+		operator := p.next().text
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = binary{operator: operator, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if next := p.peek(); next.kind == tokenOperator && (next.text == "!" || next.text == "-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unary{operator: next.text, operand: operand}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenNumber:
+		number, err := strconv.ParseFloat(t.text, floatBitSize)
+		if err != nil {
+			return nil, fmt.Errorf("illegal number %q at position %d", t.text, t.position)
+		}
+		return literal{value: numberValue(number)}, nil
+	case tokenString:
+		text, err := strconv.Unquote(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("illegal string %s at position %d", t.text, t.position)
+		}
+		return literal{value: stringValue(text)}, nil
+	case tokenIdentifier:
+		switch t.text {
+		case "true":
+			return literal{value: boolValue(true)}, nil
+		case "false":
+			return literal{value: boolValue(false)}, nil
+		}
+		return identifier{name: t.text}, nil
+	case tokenLeftParen:
+		inner, err := p.parseBinary(0)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRightParen {
+			return nil, unexpected(closing)
+		}
+		return inner, nil
+	default:
+		return nil, unexpected(t)
+	}
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.position]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.position]
+	if t.kind != tokenEOF {
+		p.position++
+	}
+	return t
+}
+
+func unexpected(t token) error {
+	if t.kind == tokenEOF {
+		return fmt.Errorf("unexpected end of expression")
+	}
+	return fmt.Errorf("unexpected %q at position %d", t.text, t.position)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	ReasonStarted              = "Started"
+	ReasonSetupFailed          = "SetupFailed"
+	ReasonSucceeded            = "Succeeded"
+	ReasonFailed               = "Failed"
+	ReasonTeardownFailed       = "TeardownFailed"
+	ReasonArtifactsStoreFailed = "ArtifactsStoreFailed"
+	ReasonRetrying             = "Retrying"
+	ReasonScheduled            = "Scheduled"
+	ReasonScheduleSkipped      = "ScheduleSkipped"
+	ReasonInvalidSchedule      = "InvalidSchedule"
+	ReasonInvalidInput         = "InvalidInput"
+)
+
+const Component = "kiagnose"
+
+// Recorder records checkup lifecycle events against the user ConfigMap
+// and against the objects the checkup created.
+// Recording is best-effort: failures are logged and do not affect the checkup.
+type Recorder struct {
+	client  kubernetes.Interface
+	mu      sync.Mutex
+	objects []corev1.ObjectReference
+}
+
+func NewRecorder(client kubernetes.Interface, configMapNamespace, configMapName, configMapUID string) *Recorder {
+	return NewObjectRecorder(client, corev1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Namespace:  configMapNamespace,
+		Name:       configMapName,
+		UID:        types.UID(configMapUID),
+	})
+}
+
+// NewObjectRecorder returns a Recorder which records events against the given object,
+// e.g. a Checkup object configuring the checkup instead of a ConfigMap.
+func NewObjectRecorder(client kubernetes.Interface, ref corev1.ObjectReference) *Recorder {
+	return &Recorder{
+		client:  client,
+		objects: []corev1.ObjectReference{ref},
+	}
+}
+
+// AddObject adds an object to those the following events are recorded against.
+func (r *Recorder) AddObject(ref corev1.ObjectReference) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.objects = append(r.objects, ref)
+}
+
+func (r *Recorder) Event(eventType, reason, message string) {
+	r.mu.Lock()
+	objects := append([]corev1.ObjectReference{}, r.objects...)
+	r.mu.Unlock()
+
+	for _, ref := range objects {
+		if err := create(r.client, newEvent(ref, eventType, reason, message)); err != nil {
+			log.Printf("failed to record %q event on %s %s/%s: %v", reason, ref.Kind, ref.Namespace, ref.Name, err)
+		}
+	}
+}
+
+func newEvent(ref corev1.ObjectReference, eventType, reason, message string) *corev1.Event {
+	now := time.Now()
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			// Same naming scheme as the client-go event recorder.
+			Name:      fmt.Sprintf("%v.%x", ref.Name, now.UnixNano()),
+			Namespace: ref.Namespace,
+		},
+		InvolvedObject:      ref,
+		Type:                eventType,
+		Reason:              reason,
+		Message:             message,
+		Source:              corev1.EventSource{Component: Component},
+		ReportingController: Component,
+		FirstTimestamp:      metav1.NewTime(now),
+		LastTimestamp:       metav1.NewTime(now),
+		Count:               1,
+	}
+}
+
+func create(client kubernetes.Interface, event *corev1.Event) error {
+	_, err := client.CoreV1().Events(event.Namespace).Create(context.Background(), event, metav1.CreateOptions{})
+	return err
+}
@@ -0,0 +1,128 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package events_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/kiagnose/kiagnose/kiagnose/events"
+)
+
+const (
+	configMapNamespace = "kiagnose"
+	configMapName      = "checkup1"
+	configMapUID       = "0123456789"
+)
+
+func TestRecorderShouldRecordEvent(t *testing.T) {
+	t.Run("on the ConfigMap", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset()
+		recorder := events.NewRecorder(fakeClient, configMapNamespace, configMapName, configMapUID)
+
+		recorder.Event(corev1.EventTypeNormal, events.ReasonStarted, "checkup started")
+
+		recordedEvents := listEvents(t, fakeClient)
+		assert.Len(t, recordedEvents, 1)
+
+		event := recordedEvents[0]
+		assert.Equal(t, corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Namespace:  configMapNamespace,
+			Name:       configMapName,
+			UID:        types.UID(configMapUID),
+		}, event.InvolvedObject)
+		assert.Equal(t, corev1.EventTypeNormal, event.Type)
+		assert.Equal(t, events.ReasonStarted, event.Reason)
+		assert.Equal(t, "checkup started", event.Message)
+		assert.Equal(t, events.Component, event.Source.Component)
+	})
+
+	t.Run("on the ConfigMap and added objects", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset()
+		recorder := events.NewRecorder(fakeClient, configMapNamespace, configMapName, configMapUID)
+		vmiRef := corev1.ObjectReference{
+			APIVersion: "kubevirt.io/v1",
+			Kind:       "VirtualMachineInstance",
+			Namespace:  configMapNamespace,
+			Name:       "vmi1",
+		}
+		recorder.AddObject(vmiRef)
+
+		recorder.Event(corev1.EventTypeWarning, events.ReasonSetupFailed, "VMI failed to boot")
+
+		recordedEvents := listEvents(t, fakeClient)
+		assert.Len(t, recordedEvents, 2)
+
+		var involvedKinds []string
+		for _, event := range recordedEvents {
+			involvedKinds = append(involvedKinds, event.InvolvedObject.Kind)
+			assert.Equal(t, events.ReasonSetupFailed, event.Reason)
+		}
+		assert.ElementsMatch(t, []string{"ConfigMap", "VirtualMachineInstance"}, involvedKinds)
+	})
+
+	t.Run("on the given object", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset()
+		checkupRef := corev1.ObjectReference{
+			APIVersion: "kiagnose.io/v1alpha1",
+			Kind:       "Checkup",
+			Namespace:  configMapNamespace,
+			Name:       "checkup1",
+			UID:        types.UID(configMapUID),
+		}
+		recorder := events.NewObjectRecorder(fakeClient, checkupRef)
+
+		recorder.Event(corev1.EventTypeNormal, events.ReasonStarted, "checkup started")
+
+		recordedEvents := listEvents(t, fakeClient)
+		assert.Len(t, recordedEvents, 1)
+		assert.Equal(t, checkupRef, recordedEvents[0].InvolvedObject)
+	})
+}
+
+func TestRecorderShouldTolerateEventCreationFailure(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	fakeClient.PrependReactor("create", "events", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("create failed")
+	})
+	recorder := events.NewRecorder(fakeClient, configMapNamespace, configMapName, configMapUID)
+
+	assert.NotPanics(t, func() {
+		recorder.Event(corev1.EventTypeNormal, events.ReasonStarted, "checkup started")
+	})
+}
+
+func listEvents(t *testing.T, client *fake.Clientset) []corev1.Event {
+	eventList, err := client.CoreV1().Events(configMapNamespace).List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	return eventList.Items
+}
@@ -0,0 +1,132 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package failure
+
+import (
+	"context"
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Code identifies the cause of a checkup failure in a machine-readable form.
+// Checkups may define their own codes in addition to the generic ones below.
+type Code string
+
+const (
+	CodeUnknown      Code = "Unknown"
+	CodeInvalidInput Code = "InvalidInput"
+	CodeTimeout      Code = "Timeout"
+	CodeJobFailed    Code = "JobFailed"
+	CodeCancelled    Code = "Cancelled"
+	CodePreflight    Code = "Preflight"
+	// CodeSuccessCriteriaNotMet describes checkup results which do not meet the user success criteria.
+	CodeSuccessCriteriaNotMet Code = "SuccessCriteriaNotMet"
+	// CodeBaselineRegression describes checkup results which changed from their baseline beyond the user tolerance.
+	CodeBaselineRegression Code = "BaselineRegression"
+	// CodeSuiteMemberFailed describes a checkup suite member which has failed.
+	CodeSuiteMemberFailed Code = "SuiteMemberFailed"
+)
+
+// Failure is the machine-readable description of a checkup failure, as reported under the failureDetails status key.
+type Failure struct {
+	Code      Code                    `json:"code"`
+	Phase     string                  `json:"phase,omitempty"`
+	Message   string                  `json:"message"`
+	Object    *corev1.ObjectReference `json:"object,omitempty"`
+	Retryable bool                    `json:"retryable"`
+}
+
+// Error is an error carrying a failure code.
+// Checkups return it, possibly wrapped by other errors, to describe why they failed.
+type Error struct {
+	Code      Code
+	Message   string
+	Object    *corev1.ObjectReference
+	Retryable bool
+	Err       error
+}
+
+type Option func(*Error)
+
+// WithObject references the object the failure relates to.
+func WithObject(ref corev1.ObjectReference) Option {
+	return func(e *Error) {
+		e.Object = &ref
+	}
+}
+
+// AsRetryable marks the failure as transient, so running the checkup again may succeed.
+func AsRetryable() Option {
+	return func(e *Error) {
+		e.Retryable = true
+	}
+}
+
+func New(code Code, message string, options ...Option) *Error {
+	e := &Error{Code: code, Message: message}
+
+	for _, option := range options {
+		option(e)
+	}
+
+	return e
+}
+
+// Wrap attaches a failure code to an existing error, keeping its message.
+func Wrap(code Code, err error, options ...Option) *Error {
+	e := New(code, err.Error(), options...)
+	e.Err = err
+	return e
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// From describes an error returned during the given checkup phase.
+// The message is the full error string, so the context added by wrapping errors is kept.
+// Errors which do not carry a failure code are described as timeouts when caused by an expired
+// context deadline, and as unknown otherwise.
+func From(err error, phase string) Failure {
+	f := Failure{Code: CodeUnknown, Phase: phase, Message: err.Error()}
+
+	var failureErr *Error
+	switch {
+	case errors.As(err, &failureErr):
+		f.Code = failureErr.Code
+		f.Object = failureErr.Object
+		f.Retryable = failureErr.Retryable
+	case errors.Is(err, context.DeadlineExceeded):
+		f.Code = CodeTimeout
+	}
+
+	return f
+}
+
+// HasCode reports whether the error, or an error it wraps, carries the given failure code.
+func HasCode(err error, code Code) bool {
+	var failureErr *Error
+	return errors.As(err, &failureErr) && failureErr.Code == code
+}
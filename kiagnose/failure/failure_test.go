@@ -0,0 +1,83 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package failure_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+)
+
+const (
+	testPhase                  = "Running"
+	testCode      failure.Code = "SomethingBroke"
+	testMessage                = "something broke"
+	wrapperPrefix              = "run"
+)
+
+func TestFromShould(t *testing.T) {
+	t.Run("describe an untyped error as unknown", func(t *testing.T) {
+		err := errors.New(testMessage)
+
+		assert.Equal(t,
+			failure.Failure{Code: failure.CodeUnknown, Phase: testPhase, Message: testMessage},
+			failure.From(err, testPhase),
+		)
+	})
+
+	t.Run("describe an expired deadline as a timeout", func(t *testing.T) {
+		err := fmt.Errorf("%s: %w", wrapperPrefix, context.DeadlineExceeded)
+
+		actualFailure := failure.From(err, testPhase)
+		assert.Equal(t, failure.CodeTimeout, actualFailure.Code)
+		assert.Equal(t, err.Error(), actualFailure.Message)
+	})
+
+	t.Run("keep the details of a wrapped typed error", func(t *testing.T) {
+		ref := corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "test-pod"}
+		err := fmt.Errorf("%s: %w", wrapperPrefix, failure.New(testCode, testMessage, failure.WithObject(ref), failure.AsRetryable()))
+
+		assert.Equal(t,
+			failure.Failure{
+				Code:      testCode,
+				Phase:     testPhase,
+				Message:   wrapperPrefix + ": " + testMessage,
+				Object:    &ref,
+				Retryable: true,
+			},
+			failure.From(err, testPhase),
+		)
+	})
+
+	t.Run("prefer the typed error code over the wrapped deadline", func(t *testing.T) {
+		err := failure.Wrap(testCode, context.DeadlineExceeded)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, context.DeadlineExceeded.Error(), err.Error())
+		assert.Equal(t, testCode, failure.From(err, testPhase).Code)
+	})
+}
@@ -0,0 +1,210 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kiagnose/kiagnose/kiagnose/artifacts"
+	"github.com/kiagnose/kiagnose/kiagnose/configmap"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const DefaultLimit = 3
+
+// NameFor returns the name of the ConfigMap holding the status of the given run.
+func NameFor(configMapName string, runNumber int) string {
+	return fmt.Sprintf("%s-run-%d", configMapName, runNumber)
+}
+
+// Archive moves the status of the previous run of the given ConfigMap into a new history ConfigMap owned by it,
+// and clears the status keys so the ConfigMap can be used for a new run.
+// History ConfigMaps beyond the given limit are deleted, oldest first, along with the artifacts of their runs.
+func Archive(client kubernetes.Interface, configMap *corev1.ConfigMap, limit int) (*corev1.ConfigMap, error) {
+	entries, err := List(client, configMap.Namespace, configMap.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	runNumber := 1
+	if len(entries) > 0 {
+		runNumber = RunNumber(&entries[len(entries)-1]) + 1
+	}
+
+	entry, err := create(client, newEntry(configMap, runNumber))
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("archived the previous run status of ConfigMap %s/%s into %q", configMap.Namespace, configMap.Name, entry.Name)
+
+	entries = append(entries, *entry)
+	for i := 0; i < len(entries)-limit; i++ {
+		removeArtifacts(client, &entries[i])
+		if err := remove(client, entries[i].Namespace, entries[i].Name); err != nil {
+			return nil, err
+		}
+	}
+
+	return clearStatus(client, configMap)
+}
+
+// List returns the history ConfigMaps of the given ConfigMap, ordered from the oldest run to the latest.
+func List(client kubernetes.Interface, namespace, configMapName string) ([]corev1.ConfigMap, error) {
+	selector := labels.SelectorFromSet(labels.Set{types.HistoryOfLabel: configMapName})
+	configMapList, err := client.CoreV1().ConfigMaps(namespace).List(
+		context.Background(), metav1.ListOptions{LabelSelector: selector.String()},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := configMapList.Items
+	sort.Slice(entries, func(i, j int) bool {
+		return RunNumber(&entries[i]) < RunNumber(&entries[j])
+	})
+
+	return entries, nil
+}
+
+// RunNumber returns the run number of a history ConfigMap.
+func RunNumber(entry *corev1.ConfigMap) int {
+	runNumber, err := strconv.Atoi(entry.Labels[types.RunNumberLabel])
+	if err != nil {
+		return 0
+	}
+	return runNumber
+}
+
+func newEntry(configMap *corev1.ConfigMap, runNumber int) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      NameFor(configMap.Name, runNumber),
+			Namespace: configMap.Namespace,
+			Labels: map[string]string{
+				types.HistoryOfLabel: configMap.Name,
+				types.RunNumberLabel: strconv.Itoa(runNumber),
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "v1",
+					Kind:       "ConfigMap",
+					Name:       configMap.Name,
+					UID:        configMap.UID,
+				},
+			},
+		},
+		Data:       statusData(configMap.Data),
+		BinaryData: statusBinaryData(configMap.BinaryData),
+	}
+}
+
+func statusData(data map[string]string) map[string]string {
+	archived := map[string]string{}
+	for k, v := range data {
+		if strings.HasPrefix(k, types.StatusKeyPrefix) {
+			archived[k] = v
+		}
+	}
+	return archived
+}
+
+func statusBinaryData(binaryData map[string][]byte) map[string][]byte {
+	var archived map[string][]byte
+	for k, v := range binaryData {
+		if strings.HasPrefix(k, types.StatusKeyPrefix) {
+			if archived == nil {
+				archived = map[string][]byte{}
+			}
+			archived[k] = v
+		}
+	}
+	return archived
+}
+
+func clearStatus(client kubernetes.Interface, configMap *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	removedKeys := map[string]interface{}{}
+	for k := range statusData(configMap.Data) {
+		removedKeys[k] = nil
+	}
+
+	// A cancellation request applies to the archived run only.
+	if _, exists := configMap.Data[types.CancelKey]; exists {
+		removedKeys[types.CancelKey] = nil
+	}
+
+	patchData := map[string]interface{}{"data": removedKeys}
+	if binaryData := statusBinaryData(configMap.BinaryData); len(binaryData) > 0 {
+		removedBinaryKeys := map[string]interface{}{}
+		for k := range binaryData {
+			removedBinaryKeys[k] = nil
+		}
+		patchData["binaryData"] = removedBinaryKeys
+	}
+	if _, exists := configMap.Annotations[types.CancelAnnotation]; exists {
+		patchData["metadata"] = map[string]interface{}{
+			"annotations": map[string]interface{}{types.CancelAnnotation: nil},
+		}
+	}
+
+	patch, err := json.Marshal(patchData)
+	if err != nil {
+		return nil, err
+	}
+
+	return configmap.Patch(client, configMap.Namespace, configMap.Name, patch)
+}
+
+// removeArtifacts deletes the artifacts and the overflow ConfigMaps referenced by a history ConfigMap.
+// They are owned by the checkup ConfigMap, so failing to delete them is only logged.
+func removeArtifacts(client kubernetes.Interface, entry *corev1.ConfigMap) {
+	for k, v := range entry.Data {
+		if k == types.OverflowKey {
+			if err := artifacts.Delete(context.Background(), client, entry.Namespace, v); err != nil {
+				log.Printf("failed to delete the overflow of %q: %v", entry.Name, err)
+			}
+			continue
+		}
+		if !strings.HasPrefix(k, types.ArtifactsPrefix) {
+			continue
+		}
+		if err := artifacts.Delete(context.Background(), client, entry.Namespace, v); err != nil {
+			log.Printf("failed to delete artifact %q of %q: %v", strings.TrimPrefix(k, types.ArtifactsPrefix), entry.Name, err)
+		}
+	}
+}
+
+func create(client kubernetes.Interface, entry *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	return client.CoreV1().ConfigMaps(entry.Namespace).Create(context.Background(), entry, metav1.CreateOptions{})
+}
+
+func remove(client kubernetes.Interface, namespace, name string) error {
+	return client.CoreV1().ConfigMaps(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
+}
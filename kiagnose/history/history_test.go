@@ -0,0 +1,224 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package history_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kiagnose/kiagnose/kiagnose/artifacts"
+	"github.com/kiagnose/kiagnose/kiagnose/configmap"
+	"github.com/kiagnose/kiagnose/kiagnose/history"
+	"github.com/kiagnose/kiagnose/kiagnose/internal/generatename"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const (
+	configMapNamespace = "kiagnose"
+	configMapName      = "checkup1"
+	configMapUID       = "0123456789"
+)
+
+func TestArchiveShould(t *testing.T) {
+	t.Run("move the previous run status into a history ConfigMap", func(t *testing.T) {
+		configMap := newConfigMap(completedRunData("true"))
+		fakeClient := fake.NewSimpleClientset(configMap)
+
+		updatedConfigMap, err := history.Archive(fakeClient, configMap, history.DefaultLimit)
+		assert.NoError(t, err)
+
+		assert.Equal(t, map[string]string{types.TimeoutKey: "1m"}, updatedConfigMap.Data)
+
+		entries, err := history.List(fakeClient, configMapNamespace, configMapName)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+
+		entry := entries[0]
+		assert.Equal(t, history.NameFor(configMapName, 1), entry.Name)
+		assert.Equal(t, 1, history.RunNumber(&entry))
+		assert.Equal(t, statusOnly(completedRunData("true")), entry.Data)
+		assert.Equal(t, []metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: configMapName, UID: configMapUID}},
+			entry.OwnerReferences)
+	})
+
+	t.Run("move the compressed status keys of the previous run", func(t *testing.T) {
+		configMap := newConfigMap(completedRunData("true"))
+		configMap.BinaryData = map[string][]byte{types.ResultsPrefix + "samples": []byte("compressed")}
+		fakeClient := fake.NewSimpleClientset(configMap)
+
+		updatedConfigMap, err := history.Archive(fakeClient, configMap, history.DefaultLimit)
+		assert.NoError(t, err)
+		assert.Empty(t, updatedConfigMap.BinaryData)
+
+		entries, err := history.List(fakeClient, configMapNamespace, configMapName)
+		assert.NoError(t, err)
+		assert.Equal(t, configMap.BinaryData, entries[0].BinaryData)
+	})
+
+	t.Run("clear the cancellation request of the previous run", func(t *testing.T) {
+		configMap := newConfigMap(mergeData(completedRunData("false"), map[string]string{types.CancelKey: "true"}))
+		configMap.Annotations = map[string]string{types.CancelAnnotation: "true"}
+		fakeClient := fake.NewSimpleClientset(configMap)
+
+		updatedConfigMap, err := history.Archive(fakeClient, configMap, history.DefaultLimit)
+		assert.NoError(t, err)
+
+		assert.Equal(t, map[string]string{types.TimeoutKey: "1m"}, updatedConfigMap.Data)
+		assert.NotContains(t, updatedConfigMap.Annotations, types.CancelAnnotation)
+	})
+
+	t.Run("keep only the latest runs up to the limit", func(t *testing.T) {
+		const limit = 2
+		fakeClient := fake.NewSimpleClientset(newConfigMap(nil))
+
+		for run := 1; run <= 4; run++ {
+			configMap := getConfigMap(t, fakeClient)
+			configMap.Data = completedRunData(fmt.Sprint(run%2 == 0))
+			_, err := configmap.Update(fakeClient, configMap)
+			assert.NoError(t, err)
+
+			_, err = history.Archive(fakeClient, configMap, limit)
+			assert.NoError(t, err)
+		}
+
+		entries, err := history.List(fakeClient, configMapNamespace, configMapName)
+		assert.NoError(t, err)
+
+		var runNumbers []int
+		for i := range entries {
+			runNumbers = append(runNumbers, history.RunNumber(&entries[i]))
+		}
+		assert.Equal(t, []int{3, 4}, runNumbers)
+	})
+
+	t.Run("delete the artifacts of the removed runs", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newConfigMap(nil))
+		generatename.Emulate(fakeClient)
+		store := artifacts.NewStore(fakeClient, configMapNamespace, configMapName, configMapUID)
+
+		var references []string
+		for run := 1; run <= 2; run++ {
+			stored, err := store.Store(context.Background(), []artifacts.Artifact{{Name: "console.log", Content: []byte("login: ")}})
+			assert.NoError(t, err)
+			references = append(references, stored["console.log"])
+
+			configMap := getConfigMap(t, fakeClient)
+			configMap.Data = mergeData(completedRunData("true"), map[string]string{types.ArtifactsPrefix + "console.log": stored["console.log"]})
+			_, err = configmap.Update(fakeClient, configMap)
+			assert.NoError(t, err)
+
+			_, err = history.Archive(fakeClient, configMap, 1)
+			assert.NoError(t, err)
+		}
+
+		_, err := artifacts.Read(context.Background(), fakeClient, configMapNamespace, references[0])
+		assert.Error(t, err)
+		content, err := artifacts.Read(context.Background(), fakeClient, configMapNamespace, references[1])
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("login: "), content)
+	})
+}
+
+func TestListShouldOrderRunsNumerically(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		newHistoryEntry(10),
+		newHistoryEntry(9),
+		newHistoryEntry(11),
+	)
+
+	entries, err := history.List(fakeClient, configMapNamespace, configMapName)
+	assert.NoError(t, err)
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	assert.Equal(t, []string{
+		history.NameFor(configMapName, 9),
+		history.NameFor(configMapName, 10),
+		history.NameFor(configMapName, 11),
+	}, names)
+}
+
+func completedRunData(succeeded string) map[string]string {
+	now := time.Now()
+	return map[string]string{
+		types.TimeoutKey:             "1m",
+		types.StartTimestampKey:      now.Format(time.RFC3339),
+		types.CompletionTimestampKey: now.Add(time.Minute).Format(time.RFC3339),
+		types.SucceededKey:           succeeded,
+		types.FailureReasonKey:       "",
+		types.ResultsPrefix + "key1": "value1",
+	}
+}
+
+func statusOnly(data map[string]string) map[string]string {
+	delete(data, types.TimeoutKey)
+	return data
+}
+
+func mergeData(data1, data2 map[string]string) map[string]string {
+	merged := map[string]string{}
+	for k, v := range data1 {
+		merged[k] = v
+	}
+	for k, v := range data2 {
+		merged[k] = v
+	}
+	return merged
+}
+
+func newConfigMap(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapName,
+			Namespace: configMapNamespace,
+			UID:       configMapUID,
+		},
+		Data: data,
+	}
+}
+
+func newHistoryEntry(runNumber int) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      history.NameFor(configMapName, runNumber),
+			Namespace: configMapNamespace,
+			Labels: map[string]string{
+				types.HistoryOfLabel: configMapName,
+				types.RunNumberLabel: fmt.Sprint(runNumber),
+			},
+		},
+	}
+}
+
+func getConfigMap(t *testing.T, client *fake.Clientset) *corev1.ConfigMap {
+	configMap, err := configmap.Get(client, configMapNamespace, configMapName)
+	assert.NoError(t, err)
+	return configMap
+}
@@ -0,0 +1,47 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package generatename
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+const suffixLength = 5
+
+// Emulate makes the fake client generate the names of the created objects which set GenerateName,
+// as the API server does.
+func Emulate(client *fake.Clientset) {
+	client.PrependReactor("create", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		objectMeta, err := meta.Accessor(action.(clienttesting.CreateAction).GetObject())
+		if err != nil {
+			return false, nil, nil
+		}
+
+		if objectMeta.GetName() == "" && objectMeta.GetGenerateName() != "" {
+			objectMeta.SetName(objectMeta.GetGenerateName() + utilrand.String(suffixLength))
+		}
+
+		return false, nil, nil
+	})
+}
@@ -0,0 +1,195 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package job
+
+import (
+	"context"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	checkupv1alpha1 "github.com/kiagnose/kiagnose/kiagnose/apis/checkup/v1alpha1"
+	"github.com/kiagnose/kiagnose/kiagnose/config"
+	"github.com/kiagnose/kiagnose/kiagnose/launcher"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const containerName = "checkup"
+
+// NameFor returns the name of the Job executing the checkup configured by the given ConfigMap.
+func NameFor(configMapName string) string {
+	return configMapName + "-checkup"
+}
+
+// NameForCheckup returns the name of the Job executing the checkup configured by the given Checkup object.
+func NameForCheckup(checkupName string) string {
+	return checkupName + "-checkup-resource"
+}
+
+// New composes a Job which executes the checkup image against the given user ConfigMap.
+// The Job is owned by the ConfigMap, so it is garbage-collected once the ConfigMap is deleted.
+func New(configMap *corev1.ConfigMap, name, image, serviceAccountName string) *batchv1.Job {
+	owner := metav1.OwnerReference{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Name:       configMap.Name,
+		UID:        configMap.UID,
+	}
+	env := []corev1.EnvVar{
+		{Name: config.ConfigMapNamespaceEnvVarName, Value: configMap.Namespace},
+		{Name: config.ConfigMapNameEnvVarName, Value: configMap.Name},
+	}
+
+	return newJob(
+		configMap.Namespace, name, image, serviceAccountName, owner, types.CheckupConfigMapLabel, env, teardownTimeout(configMap),
+	)
+}
+
+// NewForCheckup composes a Job which executes the checkup image against the given Checkup object.
+// The Job is owned by the Checkup object, so it is garbage-collected once the Checkup object is deleted.
+func NewForCheckup(checkupObj *checkupv1alpha1.Checkup, name, image, serviceAccountName string) *batchv1.Job {
+	owner := metav1.OwnerReference{
+		APIVersion: checkupv1alpha1.SchemeGroupVersion.String(),
+		Kind:       "Checkup",
+		Name:       checkupObj.Name,
+		UID:        checkupObj.UID,
+	}
+	env := []corev1.EnvVar{
+		{Name: config.CheckupNamespaceEnvVarName, Value: checkupObj.Namespace},
+		{Name: config.CheckupNameEnvVarName, Value: checkupObj.Name},
+	}
+
+	return newJob(
+		checkupObj.Namespace, name, image, serviceAccountName, owner, types.CheckupResourceLabel, env, checkupTeardownTimeout(checkupObj),
+	)
+}
+
+// newJob composes a Job owned by the given object, which is pointed to by the given environment variables
+// and by the given label of the Job and of its pod.
+func newJob(
+	namespace, name, image, serviceAccountName string,
+	owner metav1.OwnerReference,
+	ownerLabel string,
+	env []corev1.EnvVar,
+	teardownTimeout time.Duration,
+) *batchv1.Job {
+	var (
+		backoffLimit             int32 = 0
+		allowPrivilegeEscalation       = false
+		runAsNonRoot                   = true
+		// The checkup is cancelled, torn down and reports its completion when its pod is terminated, e.g. when the Job is deleted.
+		terminationGracePeriodSeconds = int64(launcher.TerminationGracePeriod(teardownTimeout).Seconds())
+	)
+
+	env = append(env, corev1.EnvVar{
+		Name: config.PodUIDEnvVarName,
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.uid"},
+		},
+	})
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       namespace,
+			Labels:          map[string]string{ownerLabel: owner.Name},
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{ownerLabel: owner.Name},
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName:            serviceAccountName,
+					RestartPolicy:                 corev1.RestartPolicyNever,
+					TerminationGracePeriodSeconds: &terminationGracePeriodSeconds,
+					Containers: []corev1.Container{
+						{
+							Name:            containerName,
+							Image:           image,
+							ImagePullPolicy: corev1.PullAlways,
+							SecurityContext: &corev1.SecurityContext{
+								AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+								Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+								RunAsNonRoot:             &runAsNonRoot,
+								SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+							},
+							Env: env,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// teardownTimeout returns the teardown timeout the checkup is configured with.
+// An invalid value is reported by the checkup itself, so the default is assumed for it.
+func teardownTimeout(configMap *corev1.ConfigMap) time.Duration {
+	timeout, err := time.ParseDuration(configMap.Data[types.TeardownTimeoutKey])
+	if err != nil || timeout <= 0 {
+		return launcher.DefaultTeardownTimeout
+	}
+	return timeout
+}
+
+// checkupTeardownTimeout returns the teardown timeout the Checkup object is configured with.
+// An invalid value is reported by the checkup itself, so the default is assumed for it.
+func checkupTeardownTimeout(checkupObj *checkupv1alpha1.Checkup) time.Duration {
+	if checkupObj.Spec.TeardownTimeout == nil || checkupObj.Spec.TeardownTimeout.Duration <= 0 {
+		return launcher.DefaultTeardownTimeout
+	}
+	return checkupObj.Spec.TeardownTimeout.Duration
+}
+
+func Create(client kubernetes.Interface, job *batchv1.Job) (*batchv1.Job, error) {
+	return client.BatchV1().Jobs(job.Namespace).Create(context.Background(), job, metav1.CreateOptions{})
+}
+
+// Finished returns whether the Job has reached a terminal state, and whether it has failed.
+func Finished(job *batchv1.Job) (finished, failed bool) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		if condition.Type == batchv1.JobComplete {
+			return true, false
+		}
+		if condition.Type == batchv1.JobFailed {
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// FailureMessage returns the reason the Job has failed, if any.
+func FailureMessage(job *batchv1.Job) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return condition.Message
+		}
+	}
+	return ""
+}
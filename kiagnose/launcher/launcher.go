@@ -0,0 +1,561 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package launcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kiagnose/kiagnose/kiagnose/artifacts"
+	"github.com/kiagnose/kiagnose/kiagnose/baseline"
+	"github.com/kiagnose/kiagnose/kiagnose/config"
+	"github.com/kiagnose/kiagnose/kiagnose/criteria"
+	"github.com/kiagnose/kiagnose/kiagnose/events"
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/preflight"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+)
+
+// ResultsEncoder converts checkup results to the flat key-value form reported to the user.
+type ResultsEncoder interface {
+	Encode() map[string]string
+}
+
+// Results is a ResultsEncoder for checkups which already produce plain string results.
+type Results map[string]string
+
+func (r Results) Encode() map[string]string {
+	return r
+}
+
+type Checkup interface {
+	Setup(ctx context.Context) error
+	Run(ctx context.Context) error
+	Teardown(ctx context.Context) error
+	Results() ResultsEncoder
+}
+
+// Validator may be implemented by checkups which validate their input before any resource is set up.
+type Validator interface {
+	Validate(ctx context.Context) error
+}
+
+type Reporter interface {
+	Report(status.Status) error
+}
+
+// EventRecorder records the checkup lifecycle transitions as Kubernetes Events.
+type EventRecorder interface {
+	Event(eventType, reason, message string)
+	AddObject(ref corev1.ObjectReference)
+}
+
+// CancelWatcher watches for a user request to cancel the run.
+type CancelWatcher interface {
+	// Watch calls cancel once a cancellation is requested, and returns when the context is done.
+	Watch(ctx context.Context, cancel context.CancelFunc)
+}
+
+// ArtifactStore stores the artifacts added by the checkup, and returns their references keyed by the artifacts names.
+type ArtifactStore interface {
+	Store(ctx context.Context, artifacts []artifacts.Artifact) (map[string]string, error)
+}
+
+const (
+	DefaultHeartbeatInterval = 30 * time.Second
+	DefaultTeardownTimeout   = 2 * time.Minute
+	DefaultRetryBackoff      = 30 * time.Second
+
+	artifactsStoreTimeout = time.Minute
+	// completionReportGracePeriod is the time given to the checkup to report its completion once torn down.
+	completionReportGracePeriod = 30 * time.Second
+)
+
+// TerminationGracePeriod returns how long a checkup may take to complete once it is cancelled, e.g. by SIGTERM:
+// the ongoing phase is aborted through its context, the checkup is torn down within the teardown timeout,
+// its artifacts are stored, and its completion is reported.
+// It bounds checkups whose phases return promptly once their context is done.
+func TerminationGracePeriod(teardownTimeout time.Duration) time.Duration {
+	return teardownTimeout + artifactsStoreTimeout + completionReportGracePeriod
+}
+
+// DefaultCancelSignals are sent to the checkup process when its pod is terminated,
+// e.g. when the checkup Job is deleted or its node is drained.
+var DefaultCancelSignals = []os.Signal{syscall.SIGTERM, os.Interrupt}
+
+type Option func(*Launcher)
+
+// WithHeartbeatInterval sets how often the run is reported as alive while no other report occurs.
+func WithHeartbeatInterval(interval time.Duration) Option {
+	return func(l *Launcher) {
+		l.heartbeatInterval = interval
+	}
+}
+
+// WithEventRecorder records the checkup lifecycle transitions using the given recorder.
+func WithEventRecorder(recorder EventRecorder) Option {
+	return func(l *Launcher) {
+		l.eventRecorder = recorder
+	}
+}
+
+// InputValidation validates the checkup input, e.g. its parameters against their schema.
+// It returns the warnings about input which is tolerated, e.g. the unknown parameters of a lenient schema.
+type InputValidation func() (warnings []string, err error)
+
+// WithInputValidation validates the checkup input before its prerequisites are verified.
+// Invalid input fails the run with an InvalidInput failure,
+// and the warnings are reported under the config.UnknownParamsWarningResultKey result.
+func WithInputValidation(validation InputValidation) Option {
+	return func(l *Launcher) {
+		l.inputValidation = validation
+	}
+}
+
+// WithPreflightChecks verifies the checkup prerequisites before it is validated and set up.
+// Unmet prerequisites fail the run with a Preflight failure.
+func WithPreflightChecks(checks ...preflight.Check) Option {
+	return func(l *Launcher) {
+		l.preflightChecks = checks
+	}
+}
+
+// WithSetupTimeout limits how long the checkup setup may take, within the run context deadline.
+// A zero timeout sets no limit.
+func WithSetupTimeout(timeout time.Duration) Option {
+	return func(l *Launcher) {
+		l.setupTimeout = timeout
+	}
+}
+
+// WithRunTimeout limits how long the checkup run may take, within the run context deadline.
+// A zero timeout sets no limit.
+func WithRunTimeout(timeout time.Duration) Option {
+	return func(l *Launcher) {
+		l.runTimeout = timeout
+	}
+}
+
+// WithTeardownTimeout sets how long the checkup teardown may take.
+// Teardown is given a fresh budget, independent of the run context deadline, so the checkup resources are cleaned up
+// after a timeout or a cancellation.
+// A zero timeout keeps the default.
+func WithTeardownTimeout(timeout time.Duration) Option {
+	return func(l *Launcher) {
+		if timeout > 0 {
+			l.teardownTimeout = timeout
+		}
+	}
+}
+
+// WithCancelSignals sets the OS signals which cancel the run.
+func WithCancelSignals(signals ...os.Signal) Option {
+	return func(l *Launcher) {
+		l.cancelSignals = signals
+	}
+}
+
+// WithCancelWatcher cancels the run once the given watcher reports a cancellation request.
+func WithCancelWatcher(watcher CancelWatcher) Option {
+	return func(l *Launcher) {
+		l.cancelWatcher = watcher
+	}
+}
+
+// WithArtifactStore stores the artifacts added by the checkup once it is torn down,
+// and reports their references along with the results.
+func WithArtifactStore(store ArtifactStore) Option {
+	return func(l *Launcher) {
+		l.artifactStore = store
+	}
+}
+
+// WithSuccessCriteria fails the checkup when its results do not meet the given expression, once it has run successfully.
+// A nil expression sets no criteria.
+func WithSuccessCriteria(expression *criteria.Expression) Option {
+	return func(l *Launcher) {
+		l.successCriteria = expression
+	}
+}
+
+// WithBaseline compares the checkup results with the given baseline once it has run successfully,
+// reporting their deltas as additional results, and fails the checkup on regressions.
+// The comparison precedes the success criteria evaluation, so the criteria may refer to the deltas.
+// A nil baseline sets no comparison.
+func WithBaseline(b *baseline.Baseline) Option {
+	return func(l *Launcher) {
+		l.baseline = b
+	}
+}
+
+// WithRetries executes the checkup setup, run and teardown again, up to the given number of times,
+// as long as all the failures of an attempt are retryable.
+// Each attempt is delayed by the given backoff, doubled on each retry. A zero backoff keeps the default.
+// The outcome of every attempt is reported, and the final attempt is the checkup verdict.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(l *Launcher) {
+		l.retries = retries
+		if backoff > 0 {
+			l.retryBackoff = backoff
+		}
+	}
+}
+
+type Launcher struct {
+	checkup           Checkup
+	reporter          Reporter
+	heartbeatInterval time.Duration
+	eventRecorder     EventRecorder
+	inputValidation   InputValidation
+	preflightChecks   []preflight.Check
+	setupTimeout      time.Duration
+	runTimeout        time.Duration
+	teardownTimeout   time.Duration
+	cancelSignals     []os.Signal
+	cancelWatcher     CancelWatcher
+	artifactStore     ArtifactStore
+	successCriteria   *criteria.Expression
+	baseline          *baseline.Baseline
+	retries           int
+	retryBackoff      time.Duration
+}
+
+func New(checkup Checkup, reporter Reporter, options ...Option) Launcher {
+	l := Launcher{
+		checkup:           checkup,
+		reporter:          reporter,
+		heartbeatInterval: DefaultHeartbeatInterval,
+		eventRecorder:     nopEventRecorder{},
+		teardownTimeout:   DefaultTeardownTimeout,
+		cancelSignals:     DefaultCancelSignals,
+		retryBackoff:      DefaultRetryBackoff,
+	}
+
+	for _, option := range options {
+		option(&l)
+	}
+
+	return l
+}
+
+// Run executes the checkup and reports its status.
+// The run is cancelled once one of the cancel signals is received or the cancel watcher reports a request;
+// the checkup is then torn down and a completion is reported with a Cancelled failure.
+// Attempts failing with retryable failures only are retried as set by WithRetries, and the final one is reported as the verdict.
+func (l Launcher) Run(ctx context.Context) (runErr error) {
+	run := &runReporter{reporter: l.reporter}
+
+	if len(l.cancelSignals) > 0 {
+		var stopSignals context.CancelFunc
+		ctx, stopSignals = signal.NotifyContext(ctx, l.cancelSignals...)
+		defer stopSignals()
+	}
+
+	if l.cancelWatcher != nil {
+		var cancelRun context.CancelFunc
+		ctx, cancelRun = context.WithCancel(ctx)
+		watcherDone := make(chan struct{})
+		go func(watchCtx context.Context) {
+			defer close(watcherDone)
+			l.cancelWatcher.Watch(watchCtx, cancelRun)
+		}(ctx)
+		defer func() {
+			cancelRun()
+			<-watcherDone
+		}()
+	}
+
+	if err := run.start(); err != nil {
+		return err
+	}
+	l.eventRecorder.Event(corev1.EventTypeNormal, events.ReasonStarted, "Checkup started")
+
+	heartbeatCtx, stopHeartbeat := context.WithCancel(context.Background())
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		run.heartbeat(heartbeatCtx, l.heartbeatInterval)
+	}()
+
+	collector := artifacts.NewCollector()
+	ctx = artifacts.WithCollector(ctx, collector)
+
+	defer func() {
+		stopHeartbeat()
+		<-heartbeatDone
+		run.setArtifacts(l.storeArtifacts(ctx, collector.Artifacts()))
+		runErr = run.complete(encodeResults(l.checkup.Results()))
+		l.recordCompletion(runErr)
+	}()
+
+	ctx = withProgressReporter(ctx, run.progress)
+	ctx = withObjectTracker(ctx, l.eventRecorder.AddObject)
+
+	if err := l.validate(ctx, run); err != nil {
+		err = cancellationAware(ctx, err)
+		run.fail(err)
+		return err
+	}
+
+	backoff := l.retryBackoff
+	for attempt := 1; ; attempt++ {
+		run.startAttempt()
+		l.attempt(ctx, run)
+
+		if attempt > l.retries || !run.retryable() || ctx.Err() != nil {
+			return nil
+		}
+
+		failureReason := run.retry(encodeResults(l.checkup.Results()))
+		log.Printf("attempt %d of %d has failed, retrying in %s", attempt, l.retries+1, backoff)
+		l.eventRecorder.Event(corev1.EventTypeWarning, events.ReasonRetrying,
+			fmt.Sprintf("Attempt %d failed, retrying in %s: %s", attempt, backoff, strings.Join(failureReason, ", ")))
+		run.progress(fmt.Sprintf("attempt %d has failed, retrying in %s", attempt, backoff))
+
+		select {
+		case <-ctx.Done():
+			run.fail(cancellationAware(ctx, fmt.Errorf("waiting to retry: %w", ctx.Err())))
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// attempt executes the checkup setup, run and teardown, recording their failures in the run status.
+// The run outcome is reported on completion, from the failures of the final attempt.
+func (l Launcher) attempt(ctx context.Context, run *runReporter) {
+	run.setPhase(status.PhaseSettingUp)
+	if err := l.setup(ctx); err != nil {
+		err = cancellationAware(ctx, err)
+		run.fail(err)
+		l.eventRecorder.Event(corev1.EventTypeWarning, events.ReasonSetupFailed, err.Error())
+		return
+	}
+
+	defer l.teardown(ctx, run)
+
+	run.setPhase(status.PhaseRunning)
+	if err := l.run(ctx); err != nil {
+		run.fail(cancellationAware(ctx, err))
+		return
+	}
+
+	if err := l.evaluateResults(run); err != nil {
+		run.fail(err)
+	}
+}
+
+// validate validates the checkup input, then verifies the checkup prerequisites, followed by the checkup own validation.
+func (l Launcher) validate(ctx context.Context, run *runReporter) error {
+	if l.inputValidation != nil {
+		warnings, err := l.inputValidation()
+		if len(warnings) > 0 {
+			run.setInputWarnings(map[string]string{config.UnknownParamsWarningResultKey: strings.Join(warnings, "; ")})
+		}
+		if err != nil {
+			return failure.Wrap(failure.CodeInvalidInput, err)
+		}
+	}
+
+	if len(l.preflightChecks) > 0 {
+		if err := preflight.Run(ctx, l.preflightChecks...); err != nil {
+			return err
+		}
+	}
+
+	if validator, ok := l.checkup.(Validator); ok {
+		return validator.Validate(ctx)
+	}
+	return nil
+}
+
+func (l Launcher) setup(ctx context.Context) error {
+	setupCtx, cancel := withOptionalTimeout(ctx, l.setupTimeout)
+	defer cancel()
+
+	return l.checkup.Setup(setupCtx)
+}
+
+func (l Launcher) run(ctx context.Context) error {
+	runCtx, cancel := withOptionalTimeout(ctx, l.runTimeout)
+	defer cancel()
+
+	return l.checkup.Run(runCtx)
+}
+
+// evaluateResults compares the checkup results with the baseline, followed by the success criteria evaluation.
+func (l Launcher) evaluateResults(run *runReporter) error {
+	results := encodeResults(l.checkup.Results())
+
+	if l.baseline != nil {
+		deltas, err := l.baseline.Compare(results)
+		run.addResults(deltas)
+		if err != nil {
+			return baselineFailure(err)
+		}
+
+		merged := map[string]string{}
+		for k, v := range results {
+			merged[k] = v
+		}
+		for k, v := range deltas {
+			merged[k] = v
+		}
+		results = merged
+	}
+
+	return l.evaluateSuccessCriteria(results)
+}
+
+func baselineFailure(err error) error {
+	var regressionErr *baseline.RegressionError
+	if errors.As(err, &regressionErr) {
+		return failure.Wrap(failure.CodeBaselineRegression, err)
+	}
+	return failure.Wrap(failure.CodeInvalidInput, err)
+}
+
+func (l Launcher) evaluateSuccessCriteria(results map[string]string) error {
+	if l.successCriteria == nil {
+		return nil
+	}
+
+	met, err := l.successCriteria.Evaluate(results)
+	if err != nil {
+		return failure.Wrap(failure.CodeInvalidInput, fmt.Errorf("success criteria %q: %w", l.successCriteria, err))
+	}
+
+	if !met {
+		var values []string
+		for _, name := range l.successCriteria.Identifiers() {
+			values = append(values, fmt.Sprintf("%s=%s", name, results[name]))
+		}
+		return failure.New(failure.CodeSuccessCriteriaNotMet,
+			fmt.Sprintf("success criteria %q is not met by %s", l.successCriteria, strings.Join(values, ", ")))
+	}
+
+	return nil
+}
+
+// teardown runs the checkup teardown with its own timeout, detached from the run context cancellation.
+func (l Launcher) teardown(ctx context.Context, run *runReporter) {
+	run.setPhase(status.PhaseTearingDown)
+
+	teardownCtx, cancel := context.WithTimeout(detachedContext{parent: ctx}, l.teardownTimeout)
+	defer cancel()
+
+	if err := l.checkup.Teardown(teardownCtx); err != nil {
+		run.fail(err)
+		l.eventRecorder.Event(corev1.EventTypeWarning, events.ReasonTeardownFailed, err.Error())
+	}
+}
+
+// storeArtifacts stores the collected artifacts with their own timeout, detached from the run context cancellation.
+// Failing to store artifacts is not considered a checkup failure.
+func (l Launcher) storeArtifacts(ctx context.Context, collected []artifacts.Artifact) map[string]string {
+	if l.artifactStore == nil || len(collected) == 0 {
+		return nil
+	}
+
+	storeCtx, cancel := context.WithTimeout(detachedContext{parent: ctx}, artifactsStoreTimeout)
+	defer cancel()
+
+	references, err := l.artifactStore.Store(storeCtx, collected)
+	if err != nil {
+		log.Printf("failed to store artifacts: %v", err)
+		l.eventRecorder.Event(corev1.EventTypeWarning, events.ReasonArtifactsStoreFailed, err.Error())
+	}
+	return references
+}
+
+func withOptionalTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// cancellationAware marks an error as caused by the run cancellation, when the run context was cancelled.
+func cancellationAware(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		log.Printf("checkup was cancelled")
+		return failure.Wrap(failure.CodeCancelled, fmt.Errorf("cancelled: %w", err))
+	}
+	return err
+}
+
+// detachedContext keeps the values of its parent, without being cancelled along with it.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (deadline time.Time, ok bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+func (l Launcher) recordCompletion(runErr error) {
+	if runErr != nil {
+		l.eventRecorder.Event(corev1.EventTypeWarning, events.ReasonFailed, runErr.Error())
+		return
+	}
+	l.eventRecorder.Event(corev1.EventTypeNormal, events.ReasonSucceeded, "Checkup succeeded")
+}
+
+type nopEventRecorder struct{}
+
+func (nopEventRecorder) Event(_, _, _ string) {}
+
+func (nopEventRecorder) AddObject(_ corev1.ObjectReference) {}
+
+func encodeResults(results ResultsEncoder) map[string]string {
+	if results == nil {
+		return nil
+	}
+	return results.Encode()
+}
+
+func failureReason(sts status.Status) error {
+	if len(sts.FailureReason) > 0 {
+		return errors.New(strings.Join(sts.FailureReason, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,922 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package launcher_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kiagnose/kiagnose/kiagnose/artifacts"
+	"github.com/kiagnose/kiagnose/kiagnose/baseline"
+	"github.com/kiagnose/kiagnose/kiagnose/config"
+	"github.com/kiagnose/kiagnose/kiagnose/criteria"
+	"github.com/kiagnose/kiagnose/kiagnose/events"
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/launcher"
+	"github.com/kiagnose/kiagnose/kiagnose/preflight"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+)
+
+func TestLauncherShould(t *testing.T) {
+	t.Run("run successfully", func(t *testing.T) {
+		testLauncher := launcher.New(checkupStub{}, &reporterStub{})
+		assert.NoError(t, testLauncher.Run(context.Background()))
+	})
+
+	t.Run("fail when report is failing", func(t *testing.T) {
+		testLauncher := launcher.New(checkupStub{}, &reporterStub{failReport: errorReport})
+		assert.ErrorContains(t, testLauncher.Run(context.Background()), errorReport.Error())
+	})
+
+	t.Run("fail when setup is failing", func(t *testing.T) {
+		testLauncher := launcher.New(checkupStub{failSetup: errorSetup}, &reporterStub{})
+		assert.ErrorContains(t, testLauncher.Run(context.Background()), errorSetup.Error())
+	})
+
+	t.Run("fail when setup and 2nd report are failing", func(t *testing.T) {
+		testLauncher := launcher.New(
+			checkupStub{failSetup: errorSetup},
+			&reporterStub{failReport: errorReport, failOnCompletionReport: true},
+		)
+		err := testLauncher.Run(context.Background())
+		assert.ErrorContains(t, err, errorSetup.Error())
+		assert.ErrorContains(t, err, errorReport.Error())
+	})
+
+	t.Run("fail without setup when validation is failing", func(t *testing.T) {
+		testCheckup := &validatingCheckupStub{failValidate: errorValidate}
+		testLauncher := launcher.New(testCheckup, &reporterStub{})
+		assert.ErrorContains(t, testLauncher.Run(context.Background()), errorValidate.Error())
+		assert.False(t, testCheckup.setupCalled)
+	})
+
+	t.Run("fail without validation and setup when a preflight check is failing", func(t *testing.T) {
+		testCheckup := &validatingCheckupStub{}
+		testReporter := &reporterStub{}
+		failingCheck := preflight.Check{Name: "some prerequisite", Verify: func(context.Context) error { return errorValidate }}
+		testLauncher := launcher.New(testCheckup, testReporter, launcher.WithPreflightChecks(failingCheck))
+
+		assert.ErrorContains(t, testLauncher.Run(context.Background()), errorValidate.Error())
+		assert.False(t, testCheckup.validateCalled)
+		assert.False(t, testCheckup.setupCalled)
+
+		finalReport := testReporter.reports[len(testReporter.reports)-1]
+		assert.Equal(t, failure.CodePreflight, finalReport.FailureDetails[0].Code)
+		assert.Equal(t, string(status.PhaseValidating), finalReport.FailureDetails[0].Phase)
+	})
+
+	t.Run("fail without preflight checks and setup when input is invalid", func(t *testing.T) {
+		testCheckup := &validatingCheckupStub{}
+		testReporter := &reporterStub{}
+		preflightCalled := false
+		check := preflight.Check{Name: "some prerequisite", Verify: func(context.Context) error {
+			preflightCalled = true
+			return nil
+		}}
+		testLauncher := launcher.New(testCheckup, testReporter,
+			launcher.WithInputValidation(func() ([]string, error) { return nil, errorValidate }),
+			launcher.WithPreflightChecks(check),
+		)
+
+		assert.ErrorContains(t, testLauncher.Run(context.Background()), errorValidate.Error())
+		assert.False(t, preflightCalled)
+		assert.False(t, testCheckup.setupCalled)
+
+		finalReport := testReporter.reports[len(testReporter.reports)-1]
+		assert.Equal(t, failure.CodeInvalidInput, finalReport.FailureDetails[0].Code)
+		assert.Equal(t, string(status.PhaseValidating), finalReport.FailureDetails[0].Phase)
+	})
+
+	t.Run("fail when run is failing", func(t *testing.T) {
+		testLauncher := launcher.New(checkupStub{failRun: errorRun}, &reporterStub{})
+		assert.ErrorContains(t, testLauncher.Run(context.Background()), errorRun.Error())
+	})
+
+	t.Run("fail when teardown is failing", func(t *testing.T) {
+		testLauncher := launcher.New(checkupStub{failTeardown: errorTeardown}, &reporterStub{})
+		assert.ErrorContains(t, testLauncher.Run(context.Background()), errorTeardown.Error())
+	})
+
+	t.Run("fail when run and report are failing", func(t *testing.T) {
+		testLauncher := launcher.New(
+			checkupStub{failRun: errorRun},
+			&reporterStub{failReport: errorReport, failOnCompletionReport: true},
+		)
+		err := testLauncher.Run(context.Background())
+		assert.ErrorContains(t, err, errorRun.Error())
+		assert.ErrorContains(t, err, errorReport.Error())
+	})
+
+	t.Run("fail when teardown and report are failing", func(t *testing.T) {
+		testLauncher := launcher.New(
+			checkupStub{failTeardown: errorTeardown},
+			&reporterStub{failReport: errorReport, failOnCompletionReport: true},
+		)
+		err := testLauncher.Run(context.Background())
+		assert.ErrorContains(t, err, errorTeardown.Error())
+		assert.ErrorContains(t, err, errorReport.Error())
+	})
+
+	t.Run("fail when run, teardown and report are failing", func(t *testing.T) {
+		testLauncher := launcher.New(
+			checkupStub{failRun: errorRun, failTeardown: errorTeardown},
+			&reporterStub{failReport: errorReport, failOnCompletionReport: true},
+		)
+		err := testLauncher.Run(context.Background())
+		assert.ErrorContains(t, err, errorRun.Error())
+		assert.ErrorContains(t, err, errorTeardown.Error())
+		assert.ErrorContains(t, err, errorReport.Error())
+	})
+}
+
+func TestLauncherShouldReport(t *testing.T) {
+	t.Run("start timestamp on initial report", func(t *testing.T) {
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(checkupStub{}, testReporter)
+
+		assert.NoError(t, testLauncher.Run(context.Background()))
+
+		assert.False(t, testReporter.reports[0].StartTimestamp.IsZero())
+		assert.True(t, testReporter.reports[0].CompletionTimestamp.IsZero())
+	})
+
+	t.Run("phases in order", func(t *testing.T) {
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(checkupStub{}, testReporter)
+
+		assert.NoError(t, testLauncher.Run(context.Background()))
+
+		assert.Equal(t, []status.Phase{
+			status.PhaseValidating,
+			status.PhaseSettingUp,
+			status.PhaseRunning,
+			status.PhaseTearingDown,
+			status.PhaseCompleted,
+		}, testReporter.reportedPhases())
+	})
+
+	t.Run("phases without running when setup is failing", func(t *testing.T) {
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(checkupStub{failSetup: errorSetup}, testReporter)
+
+		assert.ErrorContains(t, testLauncher.Run(context.Background()), errorSetup.Error())
+
+		assert.Equal(t, []status.Phase{
+			status.PhaseValidating,
+			status.PhaseSettingUp,
+			status.PhaseCompleted,
+		}, testReporter.reportedPhases())
+	})
+
+	t.Run("progress reported by the checkup", func(t *testing.T) {
+		const progressMessage = "waiting for the moon to rise"
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(checkupStub{progress: progressMessage}, testReporter)
+
+		assert.NoError(t, testLauncher.Run(context.Background()))
+
+		var progressReport *status.Status
+		for i := range testReporter.reports {
+			if testReporter.reports[i].Progress == progressMessage {
+				progressReport = &testReporter.reports[i]
+			}
+		}
+		assert.NotNil(t, progressReport)
+		assert.Equal(t, status.PhaseRunning, progressReport.Phase)
+		assert.Empty(t, testReporter.reports[len(testReporter.reports)-1].Progress)
+	})
+
+	t.Run("heartbeats while the checkup is running", func(t *testing.T) {
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(
+			checkupStub{runDuration: 100 * time.Millisecond},
+			testReporter,
+			launcher.WithHeartbeatInterval(10*time.Millisecond),
+		)
+
+		assert.NoError(t, testLauncher.Run(context.Background()))
+
+		var runningReports []status.Status
+		for _, report := range testReporter.reports {
+			if report.Phase == status.PhaseRunning {
+				runningReports = append(runningReports, report)
+			}
+		}
+		assert.Greater(t, len(runningReports), 1)
+		lastRunningReport := runningReports[len(runningReports)-1]
+		assert.True(t, lastRunningReport.LastHeartbeat.After(runningReports[0].LastHeartbeat))
+	})
+
+	t.Run("results on successful completion", func(t *testing.T) {
+		testResults := launcher.Results{"key1": "value1", "key2": "value2"}
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(checkupStub{results: testResults}, testReporter)
+
+		assert.NoError(t, testLauncher.Run(context.Background()))
+
+		finalReport := testReporter.reports[len(testReporter.reports)-1]
+		assert.True(t, finalReport.Succeeded)
+		assert.Empty(t, finalReport.FailureReason)
+		assert.False(t, finalReport.CompletionTimestamp.IsZero())
+		assert.Equal(t, map[string]string(testResults), finalReport.Results)
+	})
+
+	t.Run("typed results encoded on failed completion", func(t *testing.T) {
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(checkupStub{failRun: errorRun, results: typedResults{Count: 3}}, testReporter)
+
+		assert.ErrorContains(t, testLauncher.Run(context.Background()), errorRun.Error())
+
+		finalReport := testReporter.reports[len(testReporter.reports)-1]
+		assert.False(t, finalReport.Succeeded)
+		assert.Equal(t, []string{errorRun.Error()}, finalReport.FailureReason)
+		assert.Equal(t, map[string]string{"count": "3"}, finalReport.Results)
+	})
+
+	t.Run("failure details on failed completion", func(t *testing.T) {
+		const runFailureCode failure.Code = "RunBroke"
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(checkupStub{
+			failRun:      failure.Wrap(runFailureCode, errorRun, failure.AsRetryable()),
+			failTeardown: errorTeardown,
+		}, testReporter)
+
+		assert.Error(t, testLauncher.Run(context.Background()))
+
+		finalReport := testReporter.reports[len(testReporter.reports)-1]
+		assert.Equal(t, []failure.Failure{
+			{Code: runFailureCode, Phase: string(status.PhaseRunning), Message: errorRun.Error(), Retryable: true},
+			{Code: failure.CodeUnknown, Phase: string(status.PhaseTearingDown), Message: errorTeardown.Error()},
+		}, finalReport.FailureDetails)
+	})
+}
+
+func TestLauncherShouldReportInputWarnings(t *testing.T) {
+	testReporter := &reporterStub{}
+	testLauncher := launcher.New(
+		checkupStub{results: launcher.Results{"latency": "5"}},
+		testReporter,
+		launcher.WithInputValidation(func() ([]string, error) {
+			return []string{`unknown parameter "sampleDurationSecond"`}, nil
+		}),
+	)
+
+	assert.NoError(t, testLauncher.Run(context.Background()))
+
+	finalReport := testReporter.reports[len(testReporter.reports)-1]
+	assert.True(t, finalReport.Succeeded)
+	assert.Equal(t, map[string]string{
+		"latency":                            "5",
+		config.UnknownParamsWarningResultKey: `unknown parameter "sampleDurationSecond"`,
+	}, finalReport.Results)
+}
+
+func TestLauncherShouldCancelRun(t *testing.T) {
+	const teardownTimeout = time.Minute
+
+	t.Run("when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		testCheckup := &cancellableCheckupStub{onRun: cancel}
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(testCheckup, testReporter, launcher.WithTeardownTimeout(teardownTimeout))
+
+		assert.ErrorContains(t, testLauncher.Run(ctx), "cancelled")
+
+		assertCancelledCompletion(t, testReporter)
+		assertTornDownWithTimeout(t, testCheckup, teardownTimeout)
+	})
+
+	t.Run("when a cancel signal is received", func(t *testing.T) {
+		testCheckup := &cancellableCheckupStub{onRun: func() {
+			assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+		}}
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(testCheckup, testReporter,
+			launcher.WithCancelSignals(syscall.SIGUSR1),
+			launcher.WithTeardownTimeout(teardownTimeout),
+		)
+
+		assert.ErrorContains(t, testLauncher.Run(context.Background()), "cancelled")
+
+		assertCancelledCompletion(t, testReporter)
+		assertTornDownWithTimeout(t, testCheckup, teardownTimeout)
+	})
+
+	t.Run("when the cancel watcher reports a request", func(t *testing.T) {
+		testWatcher := &cancelWatcherStub{requested: make(chan struct{})}
+		testCheckup := &cancellableCheckupStub{onRun: func() { close(testWatcher.requested) }}
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(testCheckup, testReporter,
+			launcher.WithCancelWatcher(testWatcher),
+			launcher.WithTeardownTimeout(teardownTimeout),
+		)
+
+		assert.ErrorContains(t, testLauncher.Run(context.Background()), "cancelled")
+
+		assertCancelledCompletion(t, testReporter)
+		assertTornDownWithTimeout(t, testCheckup, teardownTimeout)
+	})
+}
+
+func TestLauncherShouldStopCancelWatcherOnCompletion(t *testing.T) {
+	testWatcher := &cancelWatcherStub{requested: make(chan struct{})}
+	testLauncher := launcher.New(checkupStub{}, &reporterStub{}, launcher.WithCancelWatcher(testWatcher))
+
+	assert.NoError(t, testLauncher.Run(context.Background()))
+	assert.True(t, testWatcher.stopped)
+}
+
+func TestLauncherShouldTeardownAfterTimeout(t *testing.T) {
+	const teardownTimeout = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	testCheckup := &cancellableCheckupStub{}
+	testReporter := &reporterStub{}
+	testLauncher := launcher.New(testCheckup, testReporter, launcher.WithTeardownTimeout(teardownTimeout))
+
+	assert.ErrorContains(t, testLauncher.Run(ctx), context.DeadlineExceeded.Error())
+
+	finalReport := testReporter.reports[len(testReporter.reports)-1]
+	assert.Equal(t, failure.CodeTimeout, finalReport.FailureDetails[0].Code)
+	assertTornDownWithTimeout(t, testCheckup, teardownTimeout)
+}
+
+func TestLauncherShouldApplyPhaseTimeouts(t *testing.T) {
+	const (
+		setupTimeout    = time.Minute
+		teardownTimeout = 3 * time.Minute
+		runTimeout      = time.Millisecond
+	)
+
+	testCheckup := &cancellableCheckupStub{}
+	testReporter := &reporterStub{}
+	testLauncher := launcher.New(testCheckup, testReporter,
+		launcher.WithSetupTimeout(setupTimeout),
+		launcher.WithRunTimeout(runTimeout),
+		launcher.WithTeardownTimeout(teardownTimeout),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	assert.ErrorContains(t, testLauncher.Run(ctx), context.DeadlineExceeded.Error())
+
+	assert.WithinDuration(t, time.Now().Add(setupTimeout), testCheckup.setupDeadline, setupTimeout/2)
+	assert.WithinDuration(t, time.Now(), testCheckup.runDeadline, time.Second)
+
+	finalReport := testReporter.reports[len(testReporter.reports)-1]
+	assert.Equal(t, failure.CodeTimeout, finalReport.FailureDetails[0].Code)
+	assert.Equal(t, string(status.PhaseRunning), finalReport.FailureDetails[0].Phase)
+	assertTornDownWithTimeout(t, testCheckup, teardownTimeout)
+}
+
+func assertCancelledCompletion(t *testing.T, testReporter *reporterStub) {
+	finalReport := testReporter.reports[len(testReporter.reports)-1]
+	assert.False(t, finalReport.CompletionTimestamp.IsZero())
+	assert.False(t, finalReport.Succeeded)
+	assert.Equal(t, failure.CodeCancelled, finalReport.FailureDetails[0].Code)
+	assert.Equal(t, string(status.PhaseRunning), finalReport.FailureDetails[0].Phase)
+}
+
+func assertTornDownWithTimeout(t *testing.T, testCheckup *cancellableCheckupStub, timeout time.Duration) {
+	assert.True(t, testCheckup.tornDown)
+	assert.NoError(t, testCheckup.teardownCtxErr)
+	assert.WithinDuration(t, time.Now().Add(timeout), testCheckup.teardownDeadline, timeout/2)
+}
+
+func TestLauncherShouldRecordEvents(t *testing.T) {
+	type eventsTestCase struct {
+		description     string
+		checkup         checkupStub
+		expectedReasons []string
+	}
+
+	testCases := []eventsTestCase{
+		{
+			description:     "on successful run",
+			checkup:         checkupStub{},
+			expectedReasons: []string{events.ReasonStarted, events.ReasonSucceeded},
+		},
+		{
+			description:     "on setup failure",
+			checkup:         checkupStub{failSetup: errorSetup},
+			expectedReasons: []string{events.ReasonStarted, events.ReasonSetupFailed, events.ReasonFailed},
+		},
+		{
+			description:     "on run failure",
+			checkup:         checkupStub{failRun: errorRun},
+			expectedReasons: []string{events.ReasonStarted, events.ReasonFailed},
+		},
+		{
+			description:     "on teardown failure",
+			checkup:         checkupStub{failTeardown: errorTeardown},
+			expectedReasons: []string{events.ReasonStarted, events.ReasonTeardownFailed, events.ReasonFailed},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			testRecorder := &eventRecorderStub{}
+			testLauncher := launcher.New(testCase.checkup, &reporterStub{}, launcher.WithEventRecorder(testRecorder))
+
+			_ = testLauncher.Run(context.Background())
+
+			assert.Equal(t, testCase.expectedReasons, testRecorder.reasons)
+		})
+	}
+
+	t.Run("against objects tracked by the checkup", func(t *testing.T) {
+		trackedObject := corev1.ObjectReference{Kind: "Pod", Namespace: "ns1", Name: "pod1"}
+		testRecorder := &eventRecorderStub{}
+		testLauncher := launcher.New(
+			checkupStub{trackedObject: &trackedObject},
+			&reporterStub{},
+			launcher.WithEventRecorder(testRecorder),
+		)
+
+		assert.NoError(t, testLauncher.Run(context.Background()))
+
+		assert.Equal(t, []corev1.ObjectReference{trackedObject}, testRecorder.objects)
+	})
+}
+
+func TestLauncherShouldStoreArtifacts(t *testing.T) {
+	const artifactName = "console.log"
+	testCheckup := checkupStub{artifacts: map[string]string{artifactName: "login: "}}
+
+	t.Run("and report their references", func(t *testing.T) {
+		testStore := &artifactStoreStub{}
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(testCheckup, testReporter, launcher.WithArtifactStore(testStore))
+
+		assert.NoError(t, testLauncher.Run(context.Background()))
+
+		assert.Equal(t, []artifacts.Artifact{{Name: artifactName, Content: []byte("login: ")}}, testStore.stored)
+		finalReport := testReporter.reports[len(testReporter.reports)-1]
+		assert.Equal(t, map[string]string{artifactName: "ref-" + artifactName}, finalReport.Artifacts)
+	})
+
+	t.Run("without failing the checkup when storing fails", func(t *testing.T) {
+		testRecorder := &eventRecorderStub{}
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(testCheckup, testReporter,
+			launcher.WithArtifactStore(&artifactStoreStub{failStore: errors.New("store error")}),
+			launcher.WithEventRecorder(testRecorder),
+		)
+
+		assert.NoError(t, testLauncher.Run(context.Background()))
+
+		finalReport := testReporter.reports[len(testReporter.reports)-1]
+		assert.True(t, finalReport.Succeeded)
+		assert.Empty(t, finalReport.Artifacts)
+		assert.Contains(t, testRecorder.reasons, events.ReasonArtifactsStoreFailed)
+	})
+}
+
+func TestLauncherShouldEvaluateSuccessCriteria(t *testing.T) {
+	testCheckup := checkupStub{results: launcher.Results{"avgLatencyNanoSec": "400000", "maxLatencyNanoSec": "1500000"}}
+
+	type criteriaTestCase struct {
+		description     string
+		expression      string
+		expectedFailure failure.Failure
+	}
+
+	testCases := []criteriaTestCase{
+		{
+			description: "and succeed when the results meet the criteria",
+			expression:  "avgLatencyNanoSec < 500000 && maxLatencyNanoSec < 2000000",
+		},
+		{
+			description: "and fail when the results do not meet the criteria",
+			expression:  "avgLatencyNanoSec < 500000 && maxLatencyNanoSec < 1000000",
+			expectedFailure: failure.Failure{
+				Code:  failure.CodeSuccessCriteriaNotMet,
+				Phase: string(status.PhaseRunning),
+				Message: `success criteria "avgLatencyNanoSec < 500000 && maxLatencyNanoSec < 1000000" is not met by ` +
+					"avgLatencyNanoSec=400000, maxLatencyNanoSec=1500000",
+			},
+		},
+		{
+			description: "and fail when the criteria cannot be evaluated",
+			expression:  "minLatencyNanoSec < 500000",
+			expectedFailure: failure.Failure{
+				Code:    failure.CodeInvalidInput,
+				Phase:   string(status.PhaseRunning),
+				Message: `success criteria "minLatencyNanoSec < 500000": result is missing: "minLatencyNanoSec"`,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			expression, err := criteria.Parse(testCase.expression)
+			assert.NoError(t, err)
+			testReporter := &reporterStub{}
+			testLauncher := launcher.New(testCheckup, testReporter, launcher.WithSuccessCriteria(expression))
+
+			err = testLauncher.Run(context.Background())
+
+			finalReport := testReporter.reports[len(testReporter.reports)-1]
+			if testCase.expectedFailure.Code == "" {
+				assert.NoError(t, err)
+				assert.True(t, finalReport.Succeeded)
+				return
+			}
+			assert.Error(t, err)
+			assert.False(t, finalReport.Succeeded)
+			assert.Equal(t, []failure.Failure{testCase.expectedFailure}, finalReport.FailureDetails)
+		})
+	}
+}
+
+func TestLauncherShouldCompareWithBaseline(t *testing.T) {
+	testCheckup := checkupStub{results: launcher.Results{"maxLatencyNanoSec": "1450000"}}
+	newBaseline := func(rawTolerance string) *baseline.Baseline {
+		tolerance, err := baseline.ParseTolerance(rawTolerance)
+		assert.NoError(t, err)
+		return &baseline.Baseline{
+			Name:       "target-ns/checkup-baseline",
+			Results:    map[string]string{"maxLatencyNanoSec": "1000000"},
+			Tolerances: map[string]baseline.Tolerance{"maxLatencyNanoSec": tolerance},
+		}
+	}
+	expectedResults := map[string]string{
+		"maxLatencyNanoSec": "1450000",
+		baseline.DeltaResultPrefix + "maxLatencyNanoSec": "45.00",
+	}
+
+	t.Run("and report the deltas", func(t *testing.T) {
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(testCheckup, testReporter, launcher.WithBaseline(newBaseline("+50%")))
+
+		assert.NoError(t, testLauncher.Run(context.Background()))
+
+		finalReport := testReporter.reports[len(testReporter.reports)-1]
+		assert.True(t, finalReport.Succeeded)
+		assert.Equal(t, expectedResults, finalReport.Results)
+	})
+
+	t.Run("and fail on regressions", func(t *testing.T) {
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(testCheckup, testReporter, launcher.WithBaseline(newBaseline("+30%")))
+
+		assert.Error(t, testLauncher.Run(context.Background()))
+
+		finalReport := testReporter.reports[len(testReporter.reports)-1]
+		assert.False(t, finalReport.Succeeded)
+		assert.Equal(t, expectedResults, finalReport.Results)
+		assert.Len(t, finalReport.FailureDetails, 1)
+		assert.Equal(t, failure.CodeBaselineRegression, finalReport.FailureDetails[0].Code)
+	})
+
+	t.Run("before evaluating the success criteria", func(t *testing.T) {
+		expression, err := criteria.Parse("baselineDeltaPercent.maxLatencyNanoSec < 40")
+		assert.NoError(t, err)
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(testCheckup, testReporter,
+			launcher.WithBaseline(newBaseline("+50%")),
+			launcher.WithSuccessCriteria(expression),
+		)
+
+		assert.Error(t, testLauncher.Run(context.Background()))
+
+		finalReport := testReporter.reports[len(testReporter.reports)-1]
+		assert.Equal(t, failure.CodeSuccessCriteriaNotMet, finalReport.FailureDetails[0].Code)
+	})
+}
+
+func TestLauncherShouldRetry(t *testing.T) {
+	const retryBackoff = time.Millisecond
+	retryableErr := failure.New(failure.CodeUnknown, "console login failed", failure.AsRetryable())
+
+	t.Run("until an attempt succeeds", func(t *testing.T) {
+		testCheckup := &flakyCheckupStub{failures: []error{retryableErr, retryableErr}}
+		testReporter := &reporterStub{}
+		testRecorder := &eventRecorderStub{}
+		testLauncher := launcher.New(testCheckup, testReporter,
+			launcher.WithRetries(2, retryBackoff), launcher.WithEventRecorder(testRecorder))
+
+		assert.NoError(t, testLauncher.Run(context.Background()))
+
+		assert.Equal(t, 3, testCheckup.setups)
+		assert.Equal(t, 3, testCheckup.teardowns)
+		assert.Equal(t, []string{events.ReasonStarted, events.ReasonRetrying, events.ReasonRetrying, events.ReasonSucceeded},
+			testRecorder.reasons)
+
+		finalReport := testReporter.reports[len(testReporter.reports)-1]
+		assert.True(t, finalReport.Succeeded)
+		assert.Empty(t, finalReport.FailureReason)
+		assert.Equal(t, map[string]string{"attempt": "3"}, finalReport.Results)
+		assert.Len(t, finalReport.Attempts, 3)
+		for i, attempt := range finalReport.Attempts[:2] {
+			assert.False(t, attempt.Succeeded)
+			assert.Equal(t, []string{"console login failed"}, attempt.FailureReason)
+			assert.True(t, attempt.FailureDetails[0].Retryable)
+			assert.Equal(t, map[string]string{"attempt": fmt.Sprint(i + 1)}, attempt.Results)
+		}
+		assert.True(t, finalReport.Attempts[2].Succeeded)
+		assert.Equal(t, finalReport.Results, finalReport.Attempts[2].Results)
+	})
+
+	t.Run("and report the final attempt once retries are exhausted", func(t *testing.T) {
+		testCheckup := &flakyCheckupStub{failures: []error{retryableErr, retryableErr, retryableErr}}
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(testCheckup, testReporter, launcher.WithRetries(1, retryBackoff))
+
+		assert.Error(t, testLauncher.Run(context.Background()))
+
+		assert.Equal(t, 2, testCheckup.setups)
+		finalReport := testReporter.reports[len(testReporter.reports)-1]
+		assert.False(t, finalReport.Succeeded)
+		assert.Equal(t, []string{"console login failed"}, finalReport.FailureReason)
+		assert.Equal(t, map[string]string{"attempt": "2"}, finalReport.Results)
+		assert.Len(t, finalReport.Attempts, 2)
+	})
+
+	t.Run("only retryable failures", func(t *testing.T) {
+		testCheckup := &flakyCheckupStub{failures: []error{retryableErr, errorRun}}
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(testCheckup, testReporter, launcher.WithRetries(3, retryBackoff))
+
+		assert.Error(t, testLauncher.Run(context.Background()))
+
+		assert.Equal(t, 2, testCheckup.setups)
+		finalReport := testReporter.reports[len(testReporter.reports)-1]
+		assert.Equal(t, []string{errorRun.Error()}, finalReport.FailureReason)
+		assert.Len(t, finalReport.Attempts, 2)
+	})
+
+	t.Run("not without retries", func(t *testing.T) {
+		testCheckup := &flakyCheckupStub{failures: []error{retryableErr}}
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(testCheckup, testReporter)
+
+		assert.Error(t, testLauncher.Run(context.Background()))
+
+		assert.Equal(t, 1, testCheckup.setups)
+		assert.Empty(t, testReporter.reports[len(testReporter.reports)-1].Attempts)
+	})
+
+	t.Run("not once cancelled while waiting to retry", func(t *testing.T) {
+		testCheckup := &flakyCheckupStub{failures: []error{retryableErr}}
+		testReporter := &reporterStub{}
+		testLauncher := launcher.New(testCheckup, testReporter, launcher.WithRetries(1, time.Hour))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		assert.Error(t, testLauncher.Run(ctx))
+
+		assert.Equal(t, 1, testCheckup.setups)
+		finalReport := testReporter.reports[len(testReporter.reports)-1]
+		assert.False(t, finalReport.Succeeded)
+		assert.Equal(t, failure.CodeTimeout, finalReport.FailureDetails[0].Code)
+		assert.Len(t, finalReport.Attempts, 1)
+	})
+}
+
+var (
+	errorValidate = errors.New("validate error")
+	errorSetup    = errors.New("setup error")
+	errorRun      = errors.New("run error")
+	errorTeardown = errors.New("teardown error")
+	errorReport   = errors.New("report error")
+)
+
+type checkupStub struct {
+	failSetup     error
+	failRun       error
+	failTeardown  error
+	results       launcher.ResultsEncoder
+	progress      string
+	runDuration   time.Duration
+	trackedObject *corev1.ObjectReference
+	artifacts     map[string]string
+}
+
+func (s checkupStub) Setup(ctx context.Context) error {
+	if s.trackedObject != nil {
+		launcher.TrackObject(ctx, *s.trackedObject)
+	}
+	return s.failSetup
+}
+
+func (s checkupStub) Run(ctx context.Context) error {
+	if s.progress != "" {
+		launcher.ReportProgress(ctx, s.progress)
+	}
+	for name, content := range s.artifacts {
+		artifacts.Add(ctx, name, []byte(content))
+	}
+	time.Sleep(s.runDuration)
+	return s.failRun
+}
+
+func (s checkupStub) Teardown(_ context.Context) error {
+	return s.failTeardown
+}
+
+func (s checkupStub) Results() launcher.ResultsEncoder {
+	return s.results
+}
+
+// cancellableCheckupStub runs until its context is done.
+type cancellableCheckupStub struct {
+	checkupStub
+	onRun            func()
+	setupDeadline    time.Time
+	runDeadline      time.Time
+	tornDown         bool
+	teardownCtxErr   error
+	teardownDeadline time.Time
+}
+
+func (s *cancellableCheckupStub) Setup(ctx context.Context) error {
+	s.setupDeadline, _ = ctx.Deadline()
+	return nil
+}
+
+func (s *cancellableCheckupStub) Run(ctx context.Context) error {
+	const runTimeout = 10 * time.Second
+
+	s.runDeadline, _ = ctx.Deadline()
+
+	if s.onRun != nil {
+		s.onRun()
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(runTimeout):
+		return errors.New("run was not cancelled")
+	}
+}
+
+func (s *cancellableCheckupStub) Teardown(ctx context.Context) error {
+	s.tornDown = true
+	s.teardownCtxErr = ctx.Err()
+	s.teardownDeadline, _ = ctx.Deadline()
+	return nil
+}
+
+type cancelWatcherStub struct {
+	requested chan struct{}
+	stopped   bool
+}
+
+func (w *cancelWatcherStub) Watch(ctx context.Context, cancel context.CancelFunc) {
+	select {
+	case <-w.requested:
+		cancel()
+	case <-ctx.Done():
+		w.stopped = true
+	}
+}
+
+type validatingCheckupStub struct {
+	checkupStub
+	failValidate   error
+	validateCalled bool
+	setupCalled    bool
+}
+
+func (s *validatingCheckupStub) Validate(_ context.Context) error {
+	s.validateCalled = true
+	return s.failValidate
+}
+
+func (s *validatingCheckupStub) Setup(ctx context.Context) error {
+	s.setupCalled = true
+	return s.checkupStub.Setup(ctx)
+}
+
+type eventRecorderStub struct {
+	reasons []string
+	objects []corev1.ObjectReference
+}
+
+func (r *eventRecorderStub) Event(_, reason, _ string) {
+	r.reasons = append(r.reasons, reason)
+}
+
+func (r *eventRecorderStub) AddObject(ref corev1.ObjectReference) {
+	r.objects = append(r.objects, ref)
+}
+
+type artifactStoreStub struct {
+	stored    []artifacts.Artifact
+	failStore error
+}
+
+func (s *artifactStoreStub) Store(_ context.Context, collected []artifacts.Artifact) (map[string]string, error) {
+	if s.failStore != nil {
+		return nil, s.failStore
+	}
+
+	s.stored = collected
+	references := map[string]string{}
+	for _, artifact := range collected {
+		references[artifact.Name] = "ref-" + artifact.Name
+	}
+	return references, nil
+}
+
+// flakyCheckupStub fails its runs with the given errors, one per attempt, and succeeds once they are exhausted.
+type flakyCheckupStub struct {
+	failures  []error
+	setups    int
+	teardowns int
+}
+
+func (s *flakyCheckupStub) Setup(_ context.Context) error {
+	s.setups++
+	return nil
+}
+
+func (s *flakyCheckupStub) Run(_ context.Context) error {
+	if s.setups <= len(s.failures) {
+		return s.failures[s.setups-1]
+	}
+	return nil
+}
+
+func (s *flakyCheckupStub) Teardown(_ context.Context) error {
+	s.teardowns++
+	return nil
+}
+
+func (s *flakyCheckupStub) Results() launcher.ResultsEncoder {
+	return launcher.Results{"attempt": fmt.Sprint(s.setups)}
+}
+
+type typedResults struct {
+	Count int
+}
+
+func (r typedResults) Encode() map[string]string {
+	return map[string]string{"count": fmt.Sprint(r.Count)}
+}
+
+type reporterStub struct {
+	mu         sync.Mutex
+	reports    []status.Status
+	failReport error
+	// The launcher reports the start timestamp, the run progress and finally
+	// the checkup results with the completion timestamp.
+	// Use this flag to cause only the completion report to fail.
+	failOnCompletionReport bool
+}
+
+func (r *reporterStub) Report(s status.Status) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reports = append(r.reports, s)
+	if r.failOnCompletionReport && !s.CompletionTimestamp.IsZero() {
+		return r.failReport
+	} else if !r.failOnCompletionReport {
+		return r.failReport
+	}
+	return nil
+}
+
+func (r *reporterStub) reportedPhases() []status.Phase {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var phases []status.Phase
+	for _, report := range r.reports {
+		if len(phases) == 0 || phases[len(phases)-1] != report.Phase {
+			phases = append(phases, report.Phase)
+		}
+	}
+	return phases
+}
@@ -0,0 +1,251 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package launcher
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+)
+
+type (
+	progressReporterKey struct{}
+	objectTrackerKey    struct{}
+)
+
+// ReportProgress updates the progress message of the checkup run the context belongs to.
+// It has no effect when the context does not originate from a launcher run.
+func ReportProgress(ctx context.Context, message string) {
+	if reportProgress, ok := ctx.Value(progressReporterKey{}).(func(string)); ok {
+		reportProgress(message)
+	}
+}
+
+func withProgressReporter(ctx context.Context, reportProgress func(string)) context.Context {
+	return context.WithValue(ctx, progressReporterKey{}, reportProgress)
+}
+
+// TrackObject marks an object created by the checkup, so the lifecycle events of the
+// run the context belongs to are also recorded against it.
+// It has no effect when the context does not originate from a launcher run.
+func TrackObject(ctx context.Context, ref corev1.ObjectReference) {
+	if trackObject, ok := ctx.Value(objectTrackerKey{}).(func(corev1.ObjectReference)); ok {
+		trackObject(ref)
+	}
+}
+
+func withObjectTracker(ctx context.Context, trackObject func(corev1.ObjectReference)) context.Context {
+	return context.WithValue(ctx, objectTrackerKey{}, trackObject)
+}
+
+// runReporter serializes the reports of a single run, which are issued
+// both by the launcher flow and by the heartbeat.
+type runReporter struct {
+	mu       sync.Mutex
+	reporter Reporter
+	status   status.Status
+	// inputWarnings are reported along with the results of every attempt, while extraResults belong to the current one.
+	inputWarnings map[string]string
+	extraResults  map[string]string
+	attemptStart  time.Time
+}
+
+func (r *runReporter) start() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.status.StartTimestamp = time.Now()
+	r.status.Phase = status.PhaseValidating
+	return r.report()
+}
+
+func (r *runReporter) setPhase(phase status.Phase) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.status.Phase = phase
+	r.status.Progress = ""
+	if err := r.report(); err != nil {
+		log.Printf("failed to report %s phase: %v", phase, err)
+	}
+}
+
+func (r *runReporter) progress(message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.status.Progress = message
+	if err := r.report(); err != nil {
+		log.Printf("failed to report progress: %v", err)
+	}
+}
+
+func (r *runReporter) heartbeat(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.mu.Lock()
+			if err := r.report(); err != nil {
+				log.Printf("failed to report heartbeat: %v", err)
+			}
+			r.mu.Unlock()
+		}
+	}
+}
+
+func (r *runReporter) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.status.FailureReason = append(r.status.FailureReason, err.Error())
+	r.status.FailureDetails = append(r.status.FailureDetails, failure.From(err, string(r.status.Phase)))
+}
+
+func (r *runReporter) setArtifacts(references map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.status.Artifacts = references
+}
+
+// addResults adds results which are reported on completion along with the checkup results.
+func (r *runReporter) addResults(results map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.extraResults == nil {
+		r.extraResults = map[string]string{}
+	}
+	for k, v := range results {
+		r.extraResults[k] = v
+	}
+}
+
+func (r *runReporter) setInputWarnings(warnings map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.inputWarnings = warnings
+}
+
+func (r *runReporter) startAttempt() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.attemptStart = time.Now()
+}
+
+// retryable reports whether the current attempt has failed, and all of its failures are retryable.
+func (r *runReporter) retryable() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, f := range r.status.FailureDetails {
+		if !f.Retryable {
+			return false
+		}
+	}
+	return len(r.status.FailureDetails) > 0
+}
+
+// retry records the outcome of the current attempt, and clears its failures and results for the next attempt.
+// It returns the failure reason of the recorded attempt.
+func (r *runReporter) retry(results map[string]string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.recordAttempt(results)
+	failureReason := r.status.FailureReason
+	r.status.FailureReason = nil
+	r.status.FailureDetails = nil
+	r.extraResults = nil
+	r.attemptStart = time.Time{}
+
+	return failureReason
+}
+
+func (r *runReporter) complete(results map[string]string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The final attempt is recorded along with the previous ones, once the checkup was retried.
+	if len(r.status.Attempts) > 0 && !r.attemptStart.IsZero() {
+		r.recordAttempt(results)
+	}
+
+	r.status.CompletionTimestamp = time.Now()
+	r.status.Succeeded = len(r.status.FailureReason) == 0
+	r.status.Phase = status.PhaseCompleted
+	r.status.Progress = ""
+	r.status.Results = r.mergedResults(results)
+	if raw, err := json.MarshalIndent(r.status.Results, "", " "); err == nil {
+		log.Printf("reporting status:\n%s\n", string(raw))
+	}
+	if err := r.report(); err != nil {
+		r.status.FailureReason = append(r.status.FailureReason, err.Error())
+	}
+
+	return failureReason(r.status)
+}
+
+// recordAttempt must be called while holding the lock.
+func (r *runReporter) recordAttempt(results map[string]string) {
+	r.status.Attempts = append(r.status.Attempts, status.Attempt{
+		StartTimestamp:      r.attemptStart.Truncate(time.Second),
+		CompletionTimestamp: time.Now().Truncate(time.Second),
+		Succeeded:           len(r.status.FailureReason) == 0,
+		FailureReason:       r.status.FailureReason,
+		FailureDetails:      r.status.FailureDetails,
+		Results:             r.mergedResults(results),
+	})
+}
+
+// mergedResults returns the checkup results along with the added ones. It must be called while holding the lock.
+func (r *runReporter) mergedResults(results map[string]string) map[string]string {
+	if len(r.extraResults) == 0 && len(r.inputWarnings) == 0 {
+		return results
+	}
+
+	merged := map[string]string{}
+	for _, m := range []map[string]string{results, r.inputWarnings, r.extraResults} {
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// report must be called while holding the lock.
+func (r *runReporter) report() error {
+	r.status.LastHeartbeat = time.Now()
+	return r.reporter.Report(r.status)
+}
@@ -0,0 +1,216 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+)
+
+var errNotFound = errors.New("not found")
+
+// Check verifies a single prerequisite of a checkup.
+type Check struct {
+	// Name describes the verified prerequisite.
+	Name string
+	// Object references the object the prerequisite relates to, if any.
+	Object *corev1.ObjectReference
+	Verify func(ctx context.Context) error
+}
+
+// Run verifies all the prerequisites, and returns a Preflight failure describing those which are not met.
+func Run(ctx context.Context, checks ...Check) error {
+	var (
+		unmetPrerequisites []string
+		failedObjects      []*corev1.ObjectReference
+	)
+
+	for _, check := range checks {
+		if err := check.Verify(ctx); err != nil {
+			log.Printf("preflight check %q failed: %v", check.Name, err)
+			unmetPrerequisites = append(unmetPrerequisites, fmt.Sprintf("%s: %v", check.Name, err))
+			if check.Object != nil {
+				failedObjects = append(failedObjects, check.Object)
+			}
+		}
+	}
+
+	if len(unmetPrerequisites) == 0 {
+		return nil
+	}
+
+	var options []failure.Option
+	if len(unmetPrerequisites) == 1 && len(failedObjects) == 1 {
+		options = append(options, failure.WithObject(*failedObjects[0]))
+	}
+
+	return failure.New(failure.CodePreflight, "preflight: "+strings.Join(unmetPrerequisites, ", "), options...)
+}
+
+// APIResource verifies that the cluster serves the given resource, e.g. one defined by a CRD.
+func APIResource(client discovery.DiscoveryInterface, groupVersion, resource string) Check {
+	return Check{
+		Name: fmt.Sprintf("API resource %s %s", groupVersion, resource),
+		Verify: func(_ context.Context) error {
+			resources, err := client.ServerResourcesForGroupVersion(groupVersion)
+			if err != nil {
+				if k8serrors.IsNotFound(err) {
+					return fmt.Errorf("API group version %q is not served", groupVersion)
+				}
+				return err
+			}
+
+			for i := range resources.APIResources {
+				if resources.APIResources[i].Name == resource {
+					return nil
+				}
+			}
+			return fmt.Errorf("resource %q is not served", resource)
+		},
+	}
+}
+
+// Access verifies that the checkup is allowed to perform the given action, using a SelfSubjectAccessReview.
+func Access(client kubernetes.Interface, attributes authorizationv1.ResourceAttributes) Check {
+	return Check{
+		Name: "access to " + describeAttributes(attributes),
+		Verify: func(ctx context.Context) error {
+			review, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+				Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attributes},
+			}, metav1.CreateOptions{})
+			if err != nil {
+				return err
+			}
+
+			if !review.Status.Allowed {
+				if review.Status.Reason != "" {
+					return fmt.Errorf("not allowed: %s", review.Status.Reason)
+				}
+				return errors.New("not allowed")
+			}
+			return nil
+		},
+	}
+}
+
+// Object verifies that an object the checkup depends on exists, using the given getter.
+func Object(ref corev1.ObjectReference, get func(ctx context.Context) error) Check {
+	return Check{
+		Name:   fmt.Sprintf("%s %s/%s", ref.Kind, ref.Namespace, ref.Name),
+		Object: &ref,
+		Verify: func(ctx context.Context) error {
+			if err := get(ctx); err != nil {
+				if k8serrors.IsNotFound(err) {
+					return errNotFound
+				}
+				return err
+			}
+			return nil
+		},
+	}
+}
+
+// Node verifies that the given node exists and can have new pods scheduled to it.
+func Node(client kubernetes.Interface, name string) Check {
+	return Check{
+		Name:   "node " + name,
+		Object: &corev1.ObjectReference{APIVersion: "v1", Kind: "Node", Name: name},
+		Verify: func(ctx context.Context) error {
+			node, err := client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				if k8serrors.IsNotFound(err) {
+					return errNotFound
+				}
+				return err
+			}
+
+			if !isSchedulable(node) {
+				return errors.New("not ready or not schedulable")
+			}
+			return nil
+		},
+	}
+}
+
+// Nodes verifies that at least the given number of nodes can have new pods scheduled to them.
+func Nodes(client kubernetes.Interface, minSchedulable int) Check {
+	return Check{
+		Name: fmt.Sprintf("at least %d schedulable nodes", minSchedulable),
+		Verify: func(ctx context.Context) error {
+			nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+
+			schedulable := 0
+			for i := range nodes.Items {
+				if isSchedulable(&nodes.Items[i]) {
+					schedulable++
+				}
+			}
+
+			if schedulable < minSchedulable {
+				return fmt.Errorf("only %d nodes are schedulable", schedulable)
+			}
+			return nil
+		},
+	}
+}
+
+func isSchedulable(node *corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func describeAttributes(attributes authorizationv1.ResourceAttributes) string {
+	resource := attributes.Resource
+	if attributes.Subresource != "" {
+		resource += "/" + attributes.Subresource
+	}
+	if attributes.Group != "" {
+		resource += "." + attributes.Group
+	}
+
+	description := attributes.Verb + " " + resource
+	if attributes.Namespace != "" {
+		description += " in namespace " + attributes.Namespace
+	}
+	return description
+}
@@ -0,0 +1,160 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package preflight_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/preflight"
+)
+
+const (
+	testNamespace    = "default"
+	testGroupVersion = "kubevirt.io/v1"
+	testResource     = "virtualmachineinstances"
+	testNodeName     = "worker1"
+)
+
+func TestRunShouldSucceedWhenAllChecksPass(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(newNode(testNodeName, true), newNode("worker2", true))
+	setServedResources(fakeClient, testGroupVersion, testResource)
+	allowAccess(fakeClient, true)
+
+	assert.NoError(t, preflight.Run(context.Background(),
+		preflight.APIResource(fakeClient.Discovery(), testGroupVersion, testResource),
+		preflight.Access(fakeClient, authorizationv1.ResourceAttributes{Verb: "create", Resource: testResource}),
+		preflight.Node(fakeClient, testNodeName),
+		preflight.Nodes(fakeClient, 2),
+		preflight.Object(corev1.ObjectReference{Kind: "Thing", Name: "thing1"}, func(context.Context) error { return nil }),
+	))
+}
+
+func TestRunShouldFailWithPreflightFailure(t *testing.T) {
+	t.Run("when API group version is not served", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset()
+
+		err := preflight.Run(context.Background(), preflight.APIResource(fakeClient.Discovery(), testGroupVersion, testResource))
+		assertPreflightFailure(t, err, `API group version "kubevirt.io/v1" is not served`)
+	})
+
+	t.Run("when API resource is not served", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset()
+		setServedResources(fakeClient, testGroupVersion, "virtualmachines")
+
+		err := preflight.Run(context.Background(), preflight.APIResource(fakeClient.Discovery(), testGroupVersion, testResource))
+		assertPreflightFailure(t, err, `resource "virtualmachineinstances" is not served`)
+	})
+
+	t.Run("when access is not allowed", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset()
+		allowAccess(fakeClient, false)
+
+		err := preflight.Run(context.Background(), preflight.Access(fakeClient, authorizationv1.ResourceAttributes{
+			Namespace: testNamespace,
+			Verb:      "create",
+			Group:     "kubevirt.io",
+			Resource:  testResource,
+		}))
+		assertPreflightFailure(t, err, "access to create virtualmachineinstances.kubevirt.io in namespace default: not allowed")
+	})
+
+	t.Run("when object does not exist", func(t *testing.T) {
+		ref := corev1.ObjectReference{Kind: "NetworkAttachmentDefinition", Namespace: testNamespace, Name: "blue-net"}
+		notFound := k8serrors.NewNotFound(schema.GroupResource{Resource: "network-attachment-definitions"}, ref.Name)
+
+		err := preflight.Run(context.Background(), preflight.Object(ref, func(context.Context) error { return notFound }))
+		assertPreflightFailure(t, err, "NetworkAttachmentDefinition default/blue-net: not found")
+		assert.Equal(t, &ref, failure.From(err, "").Object)
+	})
+
+	t.Run("when node is not schedulable", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newNode(testNodeName, false))
+
+		err := preflight.Run(context.Background(), preflight.Node(fakeClient, testNodeName))
+		assertPreflightFailure(t, err, "node worker1: not ready or not schedulable")
+	})
+
+	t.Run("when there are not enough schedulable nodes", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newNode(testNodeName, true), newNode("worker2", false))
+
+		err := preflight.Run(context.Background(), preflight.Nodes(fakeClient, 2))
+		assertPreflightFailure(t, err, "only 1 nodes are schedulable")
+	})
+
+	t.Run("with all the unmet prerequisites", func(t *testing.T) {
+		failingCheck := func(name string) preflight.Check {
+			return preflight.Check{Name: name, Verify: func(context.Context) error { return errors.New("failed") }}
+		}
+
+		err := preflight.Run(context.Background(), failingCheck("first"), failingCheck("second"))
+		assertPreflightFailure(t, err, "preflight: first: failed, second: failed")
+	})
+}
+
+func assertPreflightFailure(t *testing.T, err error, expectedMessage string) {
+	assert.ErrorContains(t, err, expectedMessage)
+	assert.Equal(t, failure.CodePreflight, failure.From(err, "").Code)
+}
+
+func setServedResources(client *fake.Clientset, groupVersion string, resources ...string) {
+	resourceList := &metav1.APIResourceList{GroupVersion: groupVersion}
+	for _, resource := range resources {
+		resourceList.APIResources = append(resourceList.APIResources, metav1.APIResource{Name: resource})
+	}
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{resourceList}
+}
+
+func allowAccess(client *fake.Clientset, allowed bool) {
+	client.PrependReactor("create", "selfsubjectaccessreviews",
+		func(action clienttesting.Action) (bool, runtime.Object, error) {
+			review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+			review.Status.Allowed = allowed
+			return true, review, nil
+		})
+}
+
+func newNode(name string, ready bool) *corev1.Node {
+	readyStatus := corev1.ConditionFalse
+	if ready {
+		readyStatus = corev1.ConditionTrue
+	}
+
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: readyStatus}},
+		},
+	}
+}
@@ -0,0 +1,281 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package rbac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+var ErrMissingPermissions = errors.New("missing permissions")
+
+// Role is a set of namespaced permissions required by a checkup, bound to its ServiceAccount.
+type Role struct {
+	Name  string
+	Rules []rbacv1.PolicyRule
+}
+
+// FrameworkRole returns the permissions required by the kiagnose framework itself,
+// in order to read the checkup ConfigMap, report the results, store the artifacts and record events.
+func FrameworkRole() Role {
+	return Role{
+		Name: "kiagnose-configmap-access",
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Resources: []string{"configmaps"},
+				Verbs:     []string{"get", "patch", "list", "watch", "create", "delete"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"secrets"},
+				Verbs:     []string{"get", "create", "delete"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"events"},
+				Verbs:     []string{"create"},
+			},
+		},
+	}
+}
+
+// CheckupRole returns the permissions required by the kiagnose framework for a checkup configured by a Checkup object,
+// in order to read the Checkup object, watch it for a cancellation request and report the results to its status.
+func CheckupRole() Role {
+	return Role{
+		Name: "kiagnose-checkup-access",
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{"kiagnose.io"},
+				Resources: []string{"checkups"},
+				Verbs:     []string{"get", "watch"},
+			},
+			{
+				APIGroups: []string{"kiagnose.io"},
+				Resources: []string{"checkups/status"},
+				Verbs:     []string{"get", "update"},
+			},
+		},
+	}
+}
+
+// Manifests returns the ServiceAccount, and a Role and RoleBinding per role, granting the roles to the ServiceAccount.
+// The objects namespace is left unset when namespace is empty.
+func Manifests(namespace, serviceAccountName string, roles ...Role) []runtime.Object {
+	objects := []runtime.Object{
+		&corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "ServiceAccount"},
+			ObjectMeta: metav1.ObjectMeta{Name: serviceAccountName, Namespace: namespace},
+		},
+	}
+
+	for _, role := range roles {
+		objects = append(objects,
+			&rbacv1.Role{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+				ObjectMeta: metav1.ObjectMeta{Name: role.Name, Namespace: namespace},
+				Rules:      role.Rules,
+			},
+			&rbacv1.RoleBinding{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+				ObjectMeta: metav1.ObjectMeta{Name: role.Name, Namespace: namespace},
+				Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: serviceAccountName}},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: role.Name},
+			},
+		)
+	}
+
+	return objects
+}
+
+// Render writes the objects as a multi-document YAML.
+func Render(w io.Writer, objects ...runtime.Object) error {
+	for _, obj := range objects {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+
+		raw, err := yaml.Marshal(content)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(w, "---\n%s", raw); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Verify checks, using SubjectAccessReviews, that the given ServiceAccount is granted all the roles permissions
+// in the namespace.
+func Verify(ctx context.Context, client kubernetes.Interface, namespace, serviceAccountName string, roles ...Role) error {
+	return verify(roles, namespace, func(attributes *authorizationv1.ResourceAttributes) (bool, error) {
+		review, err := client.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				ResourceAttributes: attributes,
+				User:               serviceAccountUsername(namespace, serviceAccountName),
+				Groups:             serviceAccountGroups(namespace),
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return false, err
+		}
+		return review.Status.Allowed, nil
+	})
+}
+
+// VerifySelf checks, using a SelfSubjectRulesReview, that the current identity is granted all the roles permissions
+// in the namespace.
+// Permissions the review could not determine are checked using SelfSubjectAccessReviews.
+func VerifySelf(ctx context.Context, client kubernetes.Interface, namespace string, roles ...Role) error {
+	review, err := client.AuthorizationV1().SelfSubjectRulesReviews().Create(ctx, &authorizationv1.SelfSubjectRulesReview{
+		Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: namespace},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+
+	return verify(roles, namespace, func(attributes *authorizationv1.ResourceAttributes) (bool, error) {
+		if coveredByRules(review.Status.ResourceRules, attributes) {
+			return true, nil
+		}
+		if !review.Status.Incomplete {
+			return false, nil
+		}
+
+		accessReview, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: attributes},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return false, err
+		}
+		return accessReview.Status.Allowed, nil
+	})
+}
+
+func verify(roles []Role, namespace string, allowed func(*authorizationv1.ResourceAttributes) (bool, error)) error {
+	var missing []string
+	for _, attributes := range resourceAttributes(namespace, roles) {
+		isAllowed, err := allowed(attributes)
+		if err != nil {
+			return err
+		}
+		if !isAllowed {
+			missing = append(missing, describe(attributes))
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrMissingPermissions, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func resourceAttributes(namespace string, roles []Role) []*authorizationv1.ResourceAttributes {
+	var attributes []*authorizationv1.ResourceAttributes
+	for _, role := range roles {
+		for _, rule := range role.Rules {
+			for _, group := range rule.APIGroups {
+				for _, resource := range rule.Resources {
+					resourceName, subresource := splitResource(resource)
+					for _, verb := range rule.Verbs {
+						attributes = append(attributes, &authorizationv1.ResourceAttributes{
+							Namespace:   namespace,
+							Verb:        verb,
+							Group:       group,
+							Resource:    resourceName,
+							Subresource: subresource,
+						})
+					}
+				}
+			}
+		}
+	}
+	return attributes
+}
+
+func coveredByRules(rules []authorizationv1.ResourceRule, attributes *authorizationv1.ResourceAttributes) bool {
+	resource := attributes.Resource
+	if attributes.Subresource != "" {
+		resource += "/" + attributes.Subresource
+	}
+
+	for _, rule := range rules {
+		if matches(rule.APIGroups, attributes.Group) && matches(rule.Resources, resource) && matches(rule.Verbs, attributes.Verb) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(values []string, value string) bool {
+	for _, v := range values {
+		if v == rbacv1.ResourceAll || v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func splitResource(resource string) (name, subresource string) {
+	const parts = 2
+	split := strings.SplitN(resource, "/", parts)
+	if len(split) == parts {
+		return split[0], split[1]
+	}
+	return resource, ""
+}
+
+func describe(attributes *authorizationv1.ResourceAttributes) string {
+	resource := attributes.Resource
+	if attributes.Subresource != "" {
+		resource += "/" + attributes.Subresource
+	}
+	if attributes.Group != "" {
+		resource += "." + attributes.Group
+	}
+	return attributes.Verb + " " + resource
+}
+
+func serviceAccountUsername(namespace, name string) string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name)
+}
+
+func serviceAccountGroups(namespace string) []string {
+	return []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace}
+}
@@ -0,0 +1,218 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package rbac_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kiagnose/kiagnose/kiagnose/rbac"
+)
+
+const (
+	testNamespace          = "target-ns"
+	testServiceAccountName = "checkup-sa"
+)
+
+var testRole = rbac.Role{
+	Name: "checker",
+	Rules: []rbacv1.PolicyRule{
+		{APIGroups: []string{"kubevirt.io"}, Resources: []string{"virtualmachineinstances"}, Verbs: []string{"get", "create"}},
+		{APIGroups: []string{"subresources.kubevirt.io"}, Resources: []string{"virtualmachineinstances/console"}, Verbs: []string{"get"}},
+	},
+}
+
+func TestRenderManifests(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, rbac.Render(&out, rbac.Manifests(testNamespace, testServiceAccountName, testRole)...))
+
+	const expected = `---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: checkup-sa
+  namespace: target-ns
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: checker
+  namespace: target-ns
+rules:
+- apiGroups:
+  - kubevirt.io
+  resources:
+  - virtualmachineinstances
+  verbs:
+  - get
+  - create
+- apiGroups:
+  - subresources.kubevirt.io
+  resources:
+  - virtualmachineinstances/console
+  verbs:
+  - get
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: checker
+  namespace: target-ns
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: checker
+subjects:
+- kind: ServiceAccount
+  name: checkup-sa
+`
+	assert.Equal(t, expected, out.String())
+}
+
+func TestFrameworkRoleShouldMatchManifest(t *testing.T) {
+	const frameworkRoleObjectIndex = 1
+	manifest, err := os.ReadFile("../../manifests/kiagnose-configmap-access.yaml")
+	assert.NoError(t, err)
+
+	var actual rbacv1.Role
+	assert.NoError(t, yaml.UnmarshalStrict(manifest, &actual))
+
+	assert.Equal(t, rbac.Manifests("", "", rbac.FrameworkRole())[frameworkRoleObjectIndex], &actual)
+}
+
+func TestCheckupRoleShouldMatchManifest(t *testing.T) {
+	const checkupRoleObjectIndex = 1
+	manifest, err := os.ReadFile("../../manifests/kiagnose-checkup-access.yaml")
+	assert.NoError(t, err)
+
+	var actual rbacv1.Role
+	assert.NoError(t, yaml.UnmarshalStrict(manifest, &actual))
+
+	assert.Equal(t, rbac.Manifests("", "", rbac.CheckupRole())[checkupRoleObjectIndex], &actual)
+}
+
+func TestVerify(t *testing.T) {
+	t.Run("succeed when all permissions are granted to the ServiceAccount", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset()
+		var reviews []*authorizationv1.SubjectAccessReview
+		fakeClient.PrependReactor("create", "subjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+			reviews = append(reviews, review)
+			review.Status.Allowed = true
+			return true, review, nil
+		})
+
+		assert.NoError(t, rbac.Verify(context.Background(), fakeClient, testNamespace, testServiceAccountName, testRole))
+
+		const expectedReviewsCount = 3
+		assert.Len(t, reviews, expectedReviewsCount)
+		assert.Equal(t, "system:serviceaccount:target-ns:checkup-sa", reviews[0].Spec.User)
+		assert.Equal(t, &authorizationv1.ResourceAttributes{
+			Namespace:   testNamespace,
+			Verb:        "get",
+			Group:       "subresources.kubevirt.io",
+			Resource:    "virtualmachineinstances",
+			Subresource: "console",
+		}, reviews[2].Spec.ResourceAttributes)
+	})
+
+	t.Run("fail listing the missing permissions", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset()
+		fakeClient.PrependReactor("create", "subjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+			review.Status.Allowed = review.Spec.ResourceAttributes.Verb != "create"
+			return true, review, nil
+		})
+
+		err := rbac.Verify(context.Background(), fakeClient, testNamespace, testServiceAccountName, testRole)
+		assert.ErrorIs(t, err, rbac.ErrMissingPermissions)
+		assert.ErrorContains(t, err, "create virtualmachineinstances.kubevirt.io")
+	})
+
+	t.Run("fail when the review fails", func(t *testing.T) {
+		expectedErr := errors.New("review failed")
+		fakeClient := fake.NewSimpleClientset()
+		fakeClient.PrependReactor("create", "subjectaccessreviews", func(clienttesting.Action) (bool, runtime.Object, error) {
+			return true, nil, expectedErr
+		})
+
+		assert.ErrorIs(t, rbac.Verify(context.Background(), fakeClient, testNamespace, testServiceAccountName, testRole), expectedErr)
+	})
+}
+
+func TestVerifySelf(t *testing.T) {
+	t.Run("succeed when the rules cover all permissions", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset()
+		setSelfRules(fakeClient, false,
+			authorizationv1.ResourceRule{APIGroups: []string{"kubevirt.io"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+			authorizationv1.ResourceRule{
+				APIGroups: []string{"subresources.kubevirt.io"},
+				Resources: []string{"virtualmachineinstances/console"},
+				Verbs:     []string{"get"},
+			},
+		)
+
+		assert.NoError(t, rbac.VerifySelf(context.Background(), fakeClient, testNamespace, testRole))
+	})
+
+	t.Run("fail when the rules do not cover all permissions", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset()
+		setSelfRules(fakeClient, false,
+			authorizationv1.ResourceRule{APIGroups: []string{"kubevirt.io"}, Resources: []string{"virtualmachineinstances"}, Verbs: []string{"get"}},
+		)
+
+		err := rbac.VerifySelf(context.Background(), fakeClient, testNamespace, testRole)
+		assert.ErrorIs(t, err, rbac.ErrMissingPermissions)
+		assert.ErrorContains(t, err, "create virtualmachineinstances.kubevirt.io, get virtualmachineinstances/console.subresources.kubevirt.io")
+	})
+
+	t.Run("fall back to access reviews when the rules are incomplete", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset()
+		setSelfRules(fakeClient, true)
+		fakeClient.PrependReactor("create", "selfsubjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+			review.Status.Allowed = true
+			return true, review, nil
+		})
+
+		assert.NoError(t, rbac.VerifySelf(context.Background(), fakeClient, testNamespace, testRole))
+	})
+}
+
+func setSelfRules(fakeClient *fake.Clientset, incomplete bool, rules ...authorizationv1.ResourceRule) {
+	fakeClient.PrependReactor("create", "selfsubjectrulesreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectRulesReview)
+		review.Status.ResourceRules = rules
+		review.Status.Incomplete = incomplete
+		return true, review, nil
+	})
+}
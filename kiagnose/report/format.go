@@ -0,0 +1,49 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package report
+
+import (
+	"fmt"
+	"io"
+)
+
+const (
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+)
+
+func ValidateFormat(format string) error {
+	if format != FormatJSON && format != FormatJUnit {
+		return fmt.Errorf("report format %q is not supported, use %q or %q", format, FormatJSON, FormatJUnit)
+	}
+	return nil
+}
+
+// Write writes the report in the given format.
+func (r Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON:
+		return r.WriteJSON(w)
+	case FormatJUnit:
+		return r.WriteJUnit(w)
+	default:
+		return ValidateFormat(format)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+)
+
+// unphasedTestCaseName names the test case holding the failures which are not attributed to a phase.
+const unphasedTestCaseName = "Checkup"
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	Time       string           `xml:"time,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// WriteJUnit writes the report as a JUnit XML document.
+// The checkup is a test suite with a test case per phase, and its results and artifacts are the suite properties.
+func (r Report) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:      r.Name,
+		Time:      formatSeconds(r.DurationSeconds),
+		Timestamp: r.StartTimestamp,
+	}
+
+	for _, result := range r.Results {
+		suite.Properties = append(suite.Properties, junitProperty{Name: "result." + result.Name, Value: result.Value})
+	}
+	for _, artifact := range r.Artifacts {
+		suite.Properties = append(suite.Properties, junitProperty{Name: "artifact." + artifact.Name, Value: artifact.Value})
+	}
+
+	for _, phase := range r.Phases {
+		testCase := junitTestCase{Name: string(phase.Name), ClassName: r.Name}
+		switch phase.Outcome {
+		case OutcomeFailed:
+			testCase.Failure = junitFailureOf(phase.Failures)
+		case OutcomeSkipped:
+			testCase.Skipped = &junitSkipped{Message: "skipped after an earlier phase failed"}
+		case OutcomeUnknown:
+			testCase.Skipped = &junitSkipped{Message: "outcome is unknown, the checkup failed outside of its phases"}
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	if unphased := r.UnphasedFailures(); len(unphased) > 0 {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      unphasedTestCaseName,
+			ClassName: r.Name,
+			Failure:   junitFailureOf(unphased),
+		})
+	}
+
+	for _, testCase := range suite.TestCases {
+		suite.Tests++
+		if testCase.Failure != nil {
+			suite.Failures++
+		}
+		if testCase.Skipped != nil {
+			suite.Skipped++
+		}
+	}
+
+	suites := junitTestSuites{
+		Tests:      suite.Tests,
+		Failures:   suite.Failures,
+		Skipped:    suite.Skipped,
+		Time:       suite.Time,
+		TestSuites: []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitFailureOf describes the failures of a single test case, typed by the first failure code.
+func junitFailureOf(fs []failure.Failure) *junitFailure {
+	messages := make([]string, 0, len(fs))
+	var text strings.Builder
+	for _, f := range fs {
+		messages = append(messages, f.Message)
+		fmt.Fprintf(&text, "%s: %s\n", f.Code, f.Message)
+		if f.Object != nil {
+			fmt.Fprintf(&text, "  object: %s %s/%s\n", f.Object.Kind, f.Object.Namespace, f.Object.Name)
+		}
+		if f.Retryable {
+			fmt.Fprintln(&text, "  retryable: true")
+		}
+	}
+
+	return &junitFailure{
+		Message: strings.Join(messages, ", "),
+		Type:    string(fs[0].Code),
+		Text:    text.String(),
+	}
+}
+
+func formatSeconds(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
@@ -0,0 +1,88 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package report_test
+
+import (
+	"bytes"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/report"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+)
+
+func TestWriteJUnitShould(t *testing.T) {
+	t.Run("describe a succeeded checkup", func(t *testing.T) {
+		s := completedStatus()
+		s.Results = map[string]string{"latency": "10"}
+
+		actual := writeJUnit(t, s)
+
+		expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="4" failures="0" skipped="0" time="90.000">
+  <testsuite name="checkup1" tests="4" failures="0" skipped="0" time="90.000" timestamp="2022-01-01T10:00:00Z">
+    <properties>
+      <property name="result.latency" value="10"></property>
+    </properties>
+    <testcase name="Validating" classname="checkup1"></testcase>
+    <testcase name="SettingUp" classname="checkup1"></testcase>
+    <testcase name="Running" classname="checkup1"></testcase>
+    <testcase name="TearingDown" classname="checkup1"></testcase>
+  </testsuite>
+</testsuites>
+`
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("describe failed and skipped phases", func(t *testing.T) {
+		s := completedStatus()
+		s.Succeeded = false
+		s.FailureDetails = []failure.Failure{newFailure(status.PhaseSettingUp)}
+
+		actual := writeJUnit(t, s)
+
+		assert.Contains(t, actual, `<testsuites tests="4" failures="1" skipped="2" time="90.000">`)
+		assert.Contains(t, actual, `<failure message="SettingUp failed" type="Unknown">Unknown: SettingUp failed&#xA;</failure>`)
+		assert.Contains(t, actual, `<skipped message="skipped after an earlier phase failed"></skipped>`)
+	})
+
+	t.Run("describe failures outside of the phases", func(t *testing.T) {
+		s := completedStatus()
+		s.Succeeded = false
+		s.FailureReason = []string{"job failed"}
+
+		actual := writeJUnit(t, s)
+
+		assert.Contains(t, actual, `<testsuites tests="5" failures="1" skipped="4" time="90.000">`)
+		assert.Contains(t, actual, `<testcase name="Checkup" classname="checkup1">`)
+		assert.Contains(t, actual, `<failure message="job failed" type="Unknown">`)
+	})
+}
+
+func writeJUnit(t *testing.T, s status.Status) string {
+	r, err := report.New(checkupName, s)
+	assert.NoError(t, err)
+
+	var actual bytes.Buffer
+	assert.NoError(t, r.Write(&actual, report.FormatJUnit))
+	return actual.String()
+}
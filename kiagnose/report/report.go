@@ -0,0 +1,199 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+)
+
+var ErrCheckupNotCompleted = errors.New("checkup has not completed")
+
+type Outcome string
+
+const (
+	OutcomePassed  Outcome = "Passed"
+	OutcomeFailed  Outcome = "Failed"
+	OutcomeSkipped Outcome = "Skipped"
+	// OutcomeUnknown describes a phase which did not fail by itself, while the checkup
+	// has failed for reasons which cannot be attributed to a phase (e.g. the checkup Job failed).
+	OutcomeUnknown Outcome = "Unknown"
+)
+
+// phases lists the checkup phases in their execution order.
+var phases = []status.Phase{
+	status.PhaseValidating,
+	status.PhaseSettingUp,
+	status.PhaseRunning,
+	status.PhaseTearingDown,
+}
+
+// Report describes a completed checkup.
+// Its JSON encoding is stable: fields are always present, and lists are ordered.
+type Report struct {
+	Name                string            `json:"name"`
+	Succeeded           bool              `json:"succeeded"`
+	StartTimestamp      string            `json:"startTimestamp"`
+	CompletionTimestamp string            `json:"completionTimestamp"`
+	DurationSeconds     float64           `json:"durationSeconds"`
+	Phases              []Phase           `json:"phases"`
+	Failures            []failure.Failure `json:"failures"`
+	Results             []Entry           `json:"results"`
+	Artifacts           []Entry           `json:"artifacts"`
+}
+
+type Phase struct {
+	Name     status.Phase      `json:"name"`
+	Outcome  Outcome           `json:"outcome"`
+	Failures []failure.Failure `json:"failures"`
+}
+
+// Entry is a named value, such as a checkup result or an artifact reference.
+type Entry struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// New describes the completed checkup with the given name and status.
+func New(name string, s status.Status) (Report, error) {
+	if s.CompletionTimestamp.IsZero() {
+		return Report{}, ErrCheckupNotCompleted
+	}
+
+	r := Report{
+		Name:                name,
+		Succeeded:           s.Succeeded,
+		StartTimestamp:      formatTimestamp(s.StartTimestamp),
+		CompletionTimestamp: formatTimestamp(s.CompletionTimestamp),
+		Failures:            failures(s),
+		Results:             entries(s.Results),
+		Artifacts:           entries(s.Artifacts),
+	}
+
+	if !s.StartTimestamp.IsZero() {
+		r.DurationSeconds = s.CompletionTimestamp.Sub(s.StartTimestamp).Round(time.Second).Seconds()
+	}
+
+	r.Phases = phaseReports(r.Failures)
+
+	return r, nil
+}
+
+// WriteJSON writes the report as an indented JSON document.
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// UnphasedFailures returns the failures which are not attributed to any of the checkup phases.
+func (r Report) UnphasedFailures() []failure.Failure {
+	var unphased []failure.Failure
+	for _, f := range r.Failures {
+		if !isPhase(f.Phase) {
+			unphased = append(unphased, f)
+		}
+	}
+	return unphased
+}
+
+// failures returns the status failure details, or describes the failure reasons when details were not reported.
+func failures(s status.Status) []failure.Failure {
+	if len(s.FailureDetails) > 0 {
+		return append([]failure.Failure{}, s.FailureDetails...)
+	}
+
+	fs := []failure.Failure{}
+	for _, reason := range s.FailureReason {
+		if reason != "" {
+			fs = append(fs, failure.Failure{Code: failure.CodeUnknown, Message: reason})
+		}
+	}
+	return fs
+}
+
+// phaseReports derives the phases outcomes from the failures.
+// The launcher skips the remaining phases once validation or setup fail.
+func phaseReports(fs []failure.Failure) []Phase {
+	unphased := false
+	for _, f := range fs {
+		if !isPhase(f.Phase) {
+			unphased = true
+		}
+	}
+
+	var reports []Phase
+	skipRest := false
+	for _, phase := range phases {
+		p := Phase{Name: phase, Failures: []failure.Failure{}}
+		for _, f := range fs {
+			if f.Phase == string(phase) {
+				p.Failures = append(p.Failures, f)
+			}
+		}
+
+		switch {
+		case skipRest:
+			p.Outcome = OutcomeSkipped
+		case len(p.Failures) > 0:
+			p.Outcome = OutcomeFailed
+			skipRest = phase == status.PhaseValidating || phase == status.PhaseSettingUp
+		case unphased:
+			p.Outcome = OutcomeUnknown
+		default:
+			p.Outcome = OutcomePassed
+		}
+
+		reports = append(reports, p)
+	}
+
+	return reports
+}
+
+func isPhase(name string) bool {
+	for _, phase := range phases {
+		if name == string(phase) {
+			return true
+		}
+	}
+	return false
+}
+
+func entries(values map[string]string) []Entry {
+	es := make([]Entry, 0, len(values))
+	for name, value := range values {
+		es = append(es, Entry{Name: name, Value: value})
+	}
+	sort.Slice(es, func(i, j int) bool { return es[i].Name < es[j].Name })
+	return es
+}
+
+func formatTimestamp(timestamp time.Time) string {
+	if timestamp.IsZero() {
+		return ""
+	}
+	return timestamp.UTC().Format(time.RFC3339)
+}
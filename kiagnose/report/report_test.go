@@ -0,0 +1,188 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package report_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/report"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+)
+
+const checkupName = "checkup1"
+
+func TestNewShouldFailWhenCheckupHasNotCompleted(t *testing.T) {
+	_, err := report.New(checkupName, status.Status{StartTimestamp: time.Now()})
+
+	assert.ErrorIs(t, err, report.ErrCheckupNotCompleted)
+}
+
+func TestNewShouldDescribePhasesOutcome(t *testing.T) {
+	type phasesTestCase struct {
+		description      string
+		failures         []failure.Failure
+		failureReason    []string
+		expectedOutcomes []report.Outcome
+	}
+
+	testCases := []phasesTestCase{
+		{
+			description:      "when the checkup succeeded",
+			expectedOutcomes: []report.Outcome{report.OutcomePassed, report.OutcomePassed, report.OutcomePassed, report.OutcomePassed},
+		},
+		{
+			description:      "when setup failed",
+			failures:         []failure.Failure{newFailure(status.PhaseSettingUp)},
+			expectedOutcomes: []report.Outcome{report.OutcomePassed, report.OutcomeFailed, report.OutcomeSkipped, report.OutcomeSkipped},
+		},
+		{
+			description:      "when run failed",
+			failures:         []failure.Failure{newFailure(status.PhaseRunning)},
+			expectedOutcomes: []report.Outcome{report.OutcomePassed, report.OutcomePassed, report.OutcomeFailed, report.OutcomePassed},
+		},
+		{
+			description:      "when run and teardown failed",
+			failures:         []failure.Failure{newFailure(status.PhaseRunning), newFailure(status.PhaseTearingDown)},
+			expectedOutcomes: []report.Outcome{report.OutcomePassed, report.OutcomePassed, report.OutcomeFailed, report.OutcomeFailed},
+		},
+		{
+			description:      "when only failure reasons were reported",
+			failureReason:    []string{"job failed"},
+			expectedOutcomes: []report.Outcome{report.OutcomeUnknown, report.OutcomeUnknown, report.OutcomeUnknown, report.OutcomeUnknown},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			s := completedStatus()
+			s.FailureDetails = testCase.failures
+			s.FailureReason = testCase.failureReason
+			s.Succeeded = len(testCase.failures) == 0 && len(testCase.failureReason) == 0
+
+			r, err := report.New(checkupName, s)
+			assert.NoError(t, err)
+
+			var outcomes []report.Outcome
+			for _, phase := range r.Phases {
+				outcomes = append(outcomes, phase.Outcome)
+			}
+			assert.Equal(t, testCase.expectedOutcomes, outcomes)
+		})
+	}
+}
+
+func TestWriteJSONShouldBeStable(t *testing.T) {
+	s := completedStatus()
+	s.Succeeded = false
+	s.FailureReason = []string{"setup failed"}
+	s.FailureDetails = []failure.Failure{newFailure(status.PhaseSettingUp)}
+	s.Results = map[string]string{"b": "2", "a": "1"}
+
+	r, err := report.New(checkupName, s)
+	assert.NoError(t, err)
+
+	var actual bytes.Buffer
+	assert.NoError(t, r.Write(&actual, report.FormatJSON))
+
+	expected := `{
+  "name": "checkup1",
+  "succeeded": false,
+  "startTimestamp": "2022-01-01T10:00:00Z",
+  "completionTimestamp": "2022-01-01T10:01:30Z",
+  "durationSeconds": 90,
+  "phases": [
+    {
+      "name": "Validating",
+      "outcome": "Passed",
+      "failures": []
+    },
+    {
+      "name": "SettingUp",
+      "outcome": "Failed",
+      "failures": [
+        {
+          "code": "Unknown",
+          "phase": "SettingUp",
+          "message": "SettingUp failed",
+          "retryable": false
+        }
+      ]
+    },
+    {
+      "name": "Running",
+      "outcome": "Skipped",
+      "failures": []
+    },
+    {
+      "name": "TearingDown",
+      "outcome": "Skipped",
+      "failures": []
+    }
+  ],
+  "failures": [
+    {
+      "code": "Unknown",
+      "phase": "SettingUp",
+      "message": "SettingUp failed",
+      "retryable": false
+    }
+  ],
+  "results": [
+    {
+      "name": "a",
+      "value": "1"
+    },
+    {
+      "name": "b",
+      "value": "2"
+    }
+  ],
+  "artifacts": []
+}
+`
+	assert.Equal(t, expected, actual.String())
+}
+
+func TestWriteShouldFailOnUnknownFormat(t *testing.T) {
+	r, err := report.New(checkupName, completedStatus())
+	assert.NoError(t, err)
+
+	assert.Error(t, r.Write(&bytes.Buffer{}, "yaml"))
+}
+
+func completedStatus() status.Status {
+	startTimestamp := time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC)
+	return status.Status{
+		Succeeded:           true,
+		StartTimestamp:      startTimestamp,
+		CompletionTimestamp: startTimestamp.Add(90 * time.Second),
+		Phase:               status.PhaseCompleted,
+	}
+}
+
+func newFailure(phase status.Phase) failure.Failure {
+	return failure.From(errors.New(string(phase)+" failed"), string(phase))
+}
@@ -0,0 +1,187 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package reporter
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/kiagnose/kiagnose/kiagnose/artifacts"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const (
+	// DefaultSizeBudget leaves room for the ConfigMap metadata under the 1MiB object size limit.
+	DefaultSizeBudget = 900 * 1024
+
+	// compressionThreshold is the minimal size of a result worth compressing.
+	compressionThreshold = 1024
+
+	// overflowReferenceSize is reserved for the overflow reference key, once results are spilled.
+	overflowReferenceSize = 512
+
+	overflowArtifactName = "overflow.json.gz"
+)
+
+// payload is the content of a status report patch.
+// A nil value removes the key from the ConfigMap.
+type payload struct {
+	data       map[string]interface{}
+	binaryData map[string]interface{}
+	overflow   map[string]string
+}
+
+// fit arranges the status keys so the ConfigMap stays within the size budget.
+// The largest results are first compressed into binaryData, and if that is not enough, they are spilled into
+// overflow ConfigMaps. The other status keys, e.g. status.succeeded and status.failureReason, are always kept as is.
+func (r *Reporter) fit(data map[string]string) payload {
+	p := payload{data: map[string]interface{}{}, binaryData: map[string]interface{}{}, overflow: map[string]string{}}
+	for k, v := range data {
+		p.data[k] = v
+	}
+
+	// Drop the compressed leftovers of previous reports, for the keys which are reported again.
+	for k := range r.configMap.BinaryData {
+		if _, reported := data[k]; reported {
+			p.binaryData[k] = nil
+		}
+	}
+
+	size := r.baseSize() + dataSize(data)
+	if size <= r.sizeBudget {
+		return p
+	}
+
+	resultKeys := largestResultKeys(data)
+	storedSizes := map[string]int{}
+	for _, k := range resultKeys {
+		storedSizes[k] = len(k) + len(data[k])
+	}
+
+	for _, k := range resultKeys {
+		if size <= r.sizeBudget || len(data[k]) < compressionThreshold {
+			break
+		}
+
+		compressed, err := status.CompressValue(data[k])
+		if err != nil {
+			log.Printf("failed to compress %q: %v", k, err)
+			continue
+		}
+		compressedSize := len(k) + base64.StdEncoding.EncodedLen(len(compressed))
+		if compressedSize >= storedSizes[k] {
+			continue
+		}
+
+		p.data[k] = nil
+		p.binaryData[k] = compressed
+		size -= storedSizes[k] - compressedSize
+		storedSizes[k] = compressedSize
+	}
+
+	if size <= r.sizeBudget {
+		return p
+	}
+
+	size += overflowReferenceSize
+	for _, k := range resultKeys {
+		if size <= r.sizeBudget {
+			break
+		}
+
+		p.overflow[k] = data[k]
+		p.data[k] = nil
+		p.binaryData[k] = nil
+		size -= storedSizes[k]
+	}
+
+	if size > r.sizeBudget {
+		log.Printf("status exceeds the ConfigMap size budget by %d bytes, after spilling all results", size-r.sizeBudget)
+	}
+
+	return p
+}
+
+// storeOverflow stores the spilled results into overflow ConfigMaps, owned by the user ConfigMap,
+// and returns their reference.
+func (r *Reporter) storeOverflow(overflow map[string]string) (string, error) {
+	rawOverflow, err := json.Marshal(overflow)
+	if err != nil {
+		return "", err
+	}
+
+	compressed, err := status.CompressValue(string(rawOverflow))
+	if err != nil {
+		return "", err
+	}
+
+	store := artifacts.NewStore(r.client, r.configMap.Namespace, r.configMap.Name, string(r.configMap.UID))
+	references, err := store.Store(context.Background(), []artifacts.Artifact{{Name: overflowArtifactName, Content: compressed}})
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("spilled %d results exceeding the ConfigMap size budget into overflow ConfigMaps", len(overflow))
+	return references[overflowArtifactName], nil
+}
+
+// baseSize returns the size of the ConfigMap keys which are not reported by the reporter.
+func (r *Reporter) baseSize() int {
+	size := 0
+	for k, v := range r.configMap.Data {
+		if !strings.HasPrefix(k, types.StatusKeyPrefix) {
+			size += len(k) + len(v)
+		}
+	}
+	for k, v := range r.configMap.BinaryData {
+		if !strings.HasPrefix(k, types.StatusKeyPrefix) {
+			size += len(k) + base64.StdEncoding.EncodedLen(len(v))
+		}
+	}
+	return size
+}
+
+func dataSize(data map[string]string) int {
+	size := 0
+	for k, v := range data {
+		size += len(k) + len(v)
+	}
+	return size
+}
+
+// largestResultKeys returns the results keys, from the largest value to the smallest.
+func largestResultKeys(data map[string]string) []string {
+	var keys []string
+	for k := range data {
+		// The attempts hold the results of previous attempts, so they are spilled as results are.
+		if strings.HasPrefix(k, types.ResultsPrefix) || k == types.AttemptsKey {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return len(data[keys[i]]) > len(data[keys[j]]) || len(data[keys[i]]) == len(data[keys[j]]) && keys[i] < keys[j]
+	})
+	return keys
+}
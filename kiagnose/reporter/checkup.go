@@ -0,0 +1,165 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package reporter
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	checkupv1alpha1 "github.com/kiagnose/kiagnose/kiagnose/apis/checkup/v1alpha1"
+	"github.com/kiagnose/kiagnose/kiagnose/checkup"
+	"github.com/kiagnose/kiagnose/kiagnose/client/clientset/versioned"
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+)
+
+// CheckupReporter reports the checkup status to a Checkup custom resource status.
+type CheckupReporter struct {
+	client    versioned.Interface
+	namespace string
+	name      string
+}
+
+func NewCheckupReporter(client versioned.Interface, checkupNamespace, checkupName string) *CheckupReporter {
+	return &CheckupReporter{
+		client:    client,
+		namespace: checkupNamespace,
+		name:      checkupName,
+	}
+}
+
+// Report writes the checkup status to the Checkup object status.
+// The Checkup object is read and updated again on a conflict, e.g. with a concurrent report of the controller.
+func (r *CheckupReporter) Report(statusData status.Status) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		checkupObj, err := checkup.Get(r.client, r.namespace, r.name)
+		if err != nil {
+			return err
+		}
+
+		setCheckupStatus(&checkupObj.Status, statusData)
+
+		_, err = checkup.UpdateStatus(r.client, checkupObj)
+		return err
+	})
+}
+
+// setCheckupStatus merges the status data into the Checkup object status.
+func setCheckupStatus(checkupStatus *checkupv1alpha1.CheckupStatus, statusData status.Status) {
+	if !statusData.StartTimestamp.IsZero() {
+		startTimestamp := metav1.NewTime(statusData.StartTimestamp)
+		checkupStatus.StartTimestamp = &startTimestamp
+	}
+
+	if statusData.Phase != "" {
+		checkupStatus.Phase = string(statusData.Phase)
+		checkupStatus.Progress = statusData.Progress
+	}
+
+	if !statusData.LastHeartbeat.IsZero() {
+		lastHeartbeat := metav1.NewTime(statusData.LastHeartbeat)
+		checkupStatus.LastHeartbeat = &lastHeartbeat
+	}
+
+	if statusData.CompletionTimestamp.IsZero() {
+		meta.SetStatusCondition(&checkupStatus.Conditions, metav1.Condition{
+			Type:   checkupv1alpha1.ConditionCompleted,
+			Status: metav1.ConditionFalse,
+			Reason: checkupv1alpha1.ReasonRunning,
+		})
+	} else {
+		completionTimestamp := metav1.NewTime(statusData.CompletionTimestamp)
+		checkupStatus.CompletionTimestamp = &completionTimestamp
+		meta.SetStatusCondition(&checkupStatus.Conditions, metav1.Condition{
+			Type:   checkupv1alpha1.ConditionCompleted,
+			Status: metav1.ConditionTrue,
+			Reason: checkupv1alpha1.ReasonCompleted,
+		})
+		meta.SetStatusCondition(&checkupStatus.Conditions, succeededCondition(statusData))
+		checkupStatus.FailureDetails = failureDetails(statusData.FailureDetails)
+	}
+
+	if len(statusData.Results) > 0 && checkupStatus.Results == nil {
+		checkupStatus.Results = map[string]string{}
+	}
+	for k, v := range statusData.Results {
+		checkupStatus.Results[k] = v
+	}
+
+	if len(statusData.Artifacts) > 0 && checkupStatus.Artifacts == nil {
+		checkupStatus.Artifacts = map[string]string{}
+	}
+	for k, v := range statusData.Artifacts {
+		checkupStatus.Artifacts[k] = v
+	}
+
+	if len(statusData.Attempts) > 0 {
+		checkupStatus.Attempts = attempts(statusData.Attempts)
+	}
+}
+
+func succeededCondition(statusData status.Status) metav1.Condition {
+	if statusData.Succeeded {
+		return metav1.Condition{
+			Type:   checkupv1alpha1.ConditionSucceeded,
+			Status: metav1.ConditionTrue,
+			Reason: checkupv1alpha1.ReasonSucceeded,
+		}
+	}
+
+	return metav1.Condition{
+		Type:    checkupv1alpha1.ConditionSucceeded,
+		Status:  metav1.ConditionFalse,
+		Reason:  checkupv1alpha1.ReasonFailed,
+		Message: strings.Join(statusData.FailureReason, ","),
+	}
+}
+
+func failureDetails(failures []failure.Failure) []checkupv1alpha1.FailureDetail {
+	var details []checkupv1alpha1.FailureDetail
+	for _, f := range failures {
+		details = append(details, checkupv1alpha1.FailureDetail{
+			Code:      string(f.Code),
+			Phase:     f.Phase,
+			Message:   f.Message,
+			Object:    f.Object,
+			Retryable: f.Retryable,
+		})
+	}
+	return details
+}
+
+func attempts(statusAttempts []status.Attempt) []checkupv1alpha1.Attempt {
+	var checkupAttempts []checkupv1alpha1.Attempt
+	for _, a := range statusAttempts {
+		checkupAttempts = append(checkupAttempts, checkupv1alpha1.Attempt{
+			StartTimestamp:      metav1.NewTime(a.StartTimestamp),
+			CompletionTimestamp: metav1.NewTime(a.CompletionTimestamp),
+			Succeeded:           a.Succeeded,
+			FailureReason:       a.FailureReason,
+			FailureDetails:      failureDetails(a.FailureDetails),
+			Results:             a.Results,
+		})
+	}
+	return checkupAttempts
+}
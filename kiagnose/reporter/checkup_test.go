@@ -0,0 +1,243 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package reporter_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
+
+	checkupv1alpha1 "github.com/kiagnose/kiagnose/kiagnose/apis/checkup/v1alpha1"
+	"github.com/kiagnose/kiagnose/kiagnose/client/clientset/versioned"
+	"github.com/kiagnose/kiagnose/kiagnose/client/clientset/versioned/fake"
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/reporter"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+)
+
+const (
+	checkupNamespace = "kiagnose"
+	checkupName      = "checkup1"
+)
+
+func TestCheckupReportShouldSucceed(t *testing.T) {
+	t.Run("on initial report", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newCheckup())
+		reporterUnderTest := reporter.NewCheckupReporter(fakeClient, checkupNamespace, checkupName)
+
+		checkupStatus := status.Status{StartTimestamp: time.Now()}
+		assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+
+		actualStatus := getCheckupStatus(t, fakeClient)
+		assert.Equal(t, timestamp(checkupStatus.StartTimestamp), timestamp(actualStatus.StartTimestamp.Time))
+		assert.Nil(t, actualStatus.CompletionTimestamp)
+		assert.True(t, meta.IsStatusConditionFalse(actualStatus.Conditions, checkupv1alpha1.ConditionCompleted))
+		assert.Nil(t, meta.FindStatusCondition(actualStatus.Conditions, checkupv1alpha1.ConditionSucceeded))
+	})
+
+	t.Run("on progress report", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newCheckup())
+		reporterUnderTest := reporter.NewCheckupReporter(fakeClient, checkupNamespace, checkupName)
+
+		checkupStatus := status.Status{
+			StartTimestamp: time.Now(),
+			Phase:          status.PhaseSettingUp,
+			Progress:       "creating VMs",
+			LastHeartbeat:  time.Now(),
+		}
+		assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+
+		actualStatus := getCheckupStatus(t, fakeClient)
+		assert.Equal(t, string(status.PhaseSettingUp), actualStatus.Phase)
+		assert.Equal(t, "creating VMs", actualStatus.Progress)
+		assert.Equal(t, timestamp(checkupStatus.LastHeartbeat), timestamp(actualStatus.LastHeartbeat.Time))
+	})
+
+	t.Run("on checkup successful completion", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newCheckup())
+		reporterUnderTest := reporter.NewCheckupReporter(fakeClient, checkupNamespace, checkupName)
+
+		checkupStatus := status.Status{StartTimestamp: time.Now()}
+		assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+
+		checkupStatus.Succeeded = true
+		checkupStatus.CompletionTimestamp = checkupStatus.StartTimestamp.Add(time.Minute)
+		checkupStatus.Results = map[string]string{"key1": "value1"}
+		assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+
+		actualStatus := getCheckupStatus(t, fakeClient)
+		assert.Equal(t, timestamp(checkupStatus.CompletionTimestamp), timestamp(actualStatus.CompletionTimestamp.Time))
+		assert.True(t, meta.IsStatusConditionTrue(actualStatus.Conditions, checkupv1alpha1.ConditionCompleted))
+		assert.True(t, meta.IsStatusConditionTrue(actualStatus.Conditions, checkupv1alpha1.ConditionSucceeded))
+		assert.Equal(t, checkupStatus.Results, actualStatus.Results)
+	})
+
+	t.Run("on checkup failed completion", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newCheckup())
+		reporterUnderTest := reporter.NewCheckupReporter(fakeClient, checkupNamespace, checkupName)
+
+		checkupStatus := status.Status{StartTimestamp: time.Now()}
+		checkupStatus.CompletionTimestamp = checkupStatus.StartTimestamp.Add(time.Minute)
+		checkupStatus.FailureReason = []string{"some reason", "some other reason"}
+		assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+
+		actualStatus := getCheckupStatus(t, fakeClient)
+		assert.True(t, meta.IsStatusConditionTrue(actualStatus.Conditions, checkupv1alpha1.ConditionCompleted))
+		succeededCondition := meta.FindStatusCondition(actualStatus.Conditions, checkupv1alpha1.ConditionSucceeded)
+		assert.NotNil(t, succeededCondition)
+		assert.Equal(t, metav1.ConditionFalse, succeededCondition.Status)
+		assert.Equal(t, "some reason,some other reason", succeededCondition.Message)
+	})
+}
+
+func TestCheckupReportShouldReportFailureDetailsArtifactsAndAttempts(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(newCheckup())
+	reporterUnderTest := reporter.NewCheckupReporter(fakeClient, checkupNamespace, checkupName)
+
+	const artifactReference = `{"kind":"ConfigMap","objects":["checkup1-artifact-x7k2q"],"size":3}`
+	startTimestamp := time.Now().Truncate(time.Second)
+	vmiFailure := failure.Failure{
+		Code:    "VMIStartFailed",
+		Phase:   string(status.PhaseSettingUp),
+		Message: "failed to start VMI",
+		Object:  &corev1.ObjectReference{Kind: "VirtualMachineInstance", Name: "source-vmi"},
+	}
+	checkupStatus := status.Status{
+		StartTimestamp:      startTimestamp,
+		CompletionTimestamp: startTimestamp.Add(time.Minute),
+		FailureReason:       []string{"failed to start VMI"},
+		FailureDetails:      []failure.Failure{vmiFailure},
+		Artifacts:           map[string]string{"console.log": artifactReference},
+		Attempts: []status.Attempt{{
+			StartTimestamp:      startTimestamp,
+			CompletionTimestamp: startTimestamp.Add(time.Second),
+			FailureReason:       []string{"failed to start VMI"},
+			FailureDetails:      []failure.Failure{vmiFailure},
+		}},
+	}
+	assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+
+	expectedFailureDetails := []checkupv1alpha1.FailureDetail{{
+		Code:    "VMIStartFailed",
+		Phase:   string(status.PhaseSettingUp),
+		Message: "failed to start VMI",
+		Object:  &corev1.ObjectReference{Kind: "VirtualMachineInstance", Name: "source-vmi"},
+	}}
+	actualStatus := getCheckupStatus(t, fakeClient)
+	assert.Equal(t, expectedFailureDetails, actualStatus.FailureDetails)
+	assert.Equal(t, map[string]string{"console.log": artifactReference}, actualStatus.Artifacts)
+	assert.Equal(t, []checkupv1alpha1.Attempt{{
+		StartTimestamp:      metav1.NewTime(startTimestamp),
+		CompletionTimestamp: metav1.NewTime(startTimestamp.Add(time.Second)),
+		FailureReason:       []string{"failed to start VMI"},
+		FailureDetails:      expectedFailureDetails,
+	}}, actualStatus.Attempts)
+}
+
+func TestCheckupReportShouldRetryOnConflict(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(newCheckup())
+	conflicts := withCheckupOptimisticConcurrency(fakeClient)
+	reporterUnderTest := reporter.NewCheckupReporter(fakeClient, checkupNamespace, checkupName)
+
+	// The controller updates the Checkup object status concurrently, once the reporter has read it.
+	concurrentUpdate := true
+	fakeClient.PrependReactor("get", "checkups", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		obj, err := fakeClient.Tracker().Get(action.GetResource(), checkupNamespace, checkupName)
+		if err != nil || !concurrentUpdate {
+			return true, obj, err
+		}
+		concurrentUpdate = false
+		read := obj.(*checkupv1alpha1.Checkup).DeepCopy()
+		updated := read.DeepCopy()
+		updated.Status.Progress = "updated concurrently"
+		updated.ResourceVersion = nextResourceVersion(updated.ResourceVersion)
+		return true, read, fakeClient.Tracker().Update(action.GetResource(), updated, checkupNamespace)
+	})
+
+	checkupStatus := status.Status{StartTimestamp: time.Now(), CompletionTimestamp: time.Now(), Succeeded: true}
+	assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+
+	assert.Equal(t, 1, *conflicts)
+	actualStatus := getCheckupStatus(t, fakeClient)
+	assert.True(t, meta.IsStatusConditionTrue(actualStatus.Conditions, checkupv1alpha1.ConditionSucceeded))
+	assert.Equal(t, "updated concurrently", actualStatus.Progress)
+}
+
+func TestCheckupReportShouldFail(t *testing.T) {
+	t.Run("when Checkup doesn't exist", func(t *testing.T) {
+		reporterUnderTest := reporter.NewCheckupReporter(fake.NewSimpleClientset(), checkupNamespace, checkupName)
+
+		assert.ErrorContains(t, reporterUnderTest.Report(status.Status{}), "not found")
+	})
+}
+
+func newCheckup() *checkupv1alpha1.Checkup {
+	return &checkupv1alpha1.Checkup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      checkupName,
+			Namespace: checkupNamespace,
+		},
+		Spec: checkupv1alpha1.CheckupSpec{Timeout: metav1.Duration{Duration: time.Minute}},
+	}
+}
+
+func getCheckupStatus(t *testing.T, client versioned.Interface) checkupv1alpha1.CheckupStatus {
+	checkup, err := client.KiagnoseV1alpha1().Checkups(checkupNamespace).Get(context.Background(), checkupName, metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	return checkup.Status
+}
+
+// withCheckupOptimisticConcurrency makes the fake client behave as the API server does with Checkup status updates:
+// each update bumps the Checkup resourceVersion, and an update of a stale resourceVersion fails with a conflict.
+// It returns the number of conflicts which occurred.
+func withCheckupOptimisticConcurrency(client *fake.Clientset) *int {
+	conflicts := 0
+	tracker := client.Tracker()
+
+	client.PrependReactor("update", "checkups", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		checkupObj := action.(clienttesting.UpdateAction).GetObject().(*checkupv1alpha1.Checkup).DeepCopy()
+		current, err := tracker.Get(action.GetResource(), checkupObj.Namespace, checkupObj.Name)
+		if err != nil {
+			return true, nil, err
+		}
+
+		currentVersion := current.(*checkupv1alpha1.Checkup).ResourceVersion
+		if checkupObj.ResourceVersion != currentVersion {
+			conflicts++
+			return true, nil, k8serrors.NewConflict(checkupv1alpha1.Resource("checkups"), checkupObj.Name, errors.New("object has been modified"))
+		}
+
+		checkupObj.ResourceVersion = nextResourceVersion(currentVersion)
+		return true, checkupObj, tracker.Update(action.GetResource(), checkupObj, checkupObj.Namespace)
+	})
+
+	return &conflicts
+}
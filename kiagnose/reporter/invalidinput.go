@@ -0,0 +1,61 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package reporter
+
+import (
+	"log"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kiagnose/kiagnose/kiagnose/configmap"
+	"github.com/kiagnose/kiagnose/kiagnose/events"
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+// ReportInvalidInput reports a checkup whose configuration could not be read from the ConfigMap,
+// as completed with an InvalidInput failure.
+// The status of a previous run of a re-runnable ConfigMap is kept intact, and the failure is only recorded as an Event.
+func ReportInvalidInput(client kubernetes.Interface, configMapNamespace, configMapName string, inputErr error) error {
+	configMap, err := configmap.Get(client, configMapNamespace, configMapName)
+	if err != nil {
+		return err
+	}
+
+	events.NewRecorder(client, configMapNamespace, configMapName, string(configMap.UID)).
+		Event(corev1.EventTypeWarning, events.ReasonInvalidInput, inputErr.Error())
+
+	if _, started := configMap.Data[types.StartTimestampKey]; started {
+		log.Printf("keeping the status of the previous run of ConfigMap %s/%s", configMapNamespace, configMapName)
+		return nil
+	}
+
+	now := time.Now()
+	return New(client, configMapNamespace, configMapName).Report(status.Status{
+		StartTimestamp:      now,
+		CompletionTimestamp: now,
+		Phase:               status.PhaseCompleted,
+		FailureReason:       []string{inputErr.Error()},
+		FailureDetails:      []failure.Failure{failure.From(failure.Wrap(failure.CodeInvalidInput, inputErr), string(status.PhaseValidating))},
+	})
+}
@@ -0,0 +1,75 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package reporter_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kiagnose/kiagnose/kiagnose/events"
+	"github.com/kiagnose/kiagnose/kiagnose/reporter"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+var errInvalidTimeout = errors.New("timeout field is illegal")
+
+func TestReportInvalidInputShouldReportFailedCompletion(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(newConfigMap(checkupSpecData()))
+
+	assert.NoError(t, reporter.ReportInvalidInput(fakeClient, configMapNamespace, configMapName, errInvalidTimeout))
+
+	data := getCheckupData(t, fakeClient, configMapNamespace, configMapName)
+	assert.Equal(t, "false", data[types.SucceededKey])
+	assert.Equal(t, errInvalidTimeout.Error(), data[types.FailureReasonKey])
+	assert.Contains(t, data[types.FailureDetailsKey], `"code":"InvalidInput"`)
+	assert.NotEmpty(t, data[types.CompletionTimestampKey])
+	assertInvalidInputEvent(t, fakeClient)
+}
+
+func TestReportInvalidInputShouldKeepPreviousRunStatus(t *testing.T) {
+	previousRunData := mergeMaps(checkupSpecData(), map[string]string{
+		types.RerunnableKey:          "true",
+		types.StartTimestampKey:      timestamp(time.Now()),
+		types.CompletionTimestampKey: timestamp(time.Now()),
+		types.SucceededKey:           "true",
+		types.FailureReasonKey:       "",
+	})
+	fakeClient := fake.NewSimpleClientset(newConfigMap(previousRunData))
+
+	assert.NoError(t, reporter.ReportInvalidInput(fakeClient, configMapNamespace, configMapName, errInvalidTimeout))
+
+	assert.Equal(t, previousRunData, getCheckupData(t, fakeClient, configMapNamespace, configMapName))
+	assertInvalidInputEvent(t, fakeClient)
+}
+
+func assertInvalidInputEvent(t *testing.T, client *fake.Clientset) {
+	eventList, err := client.CoreV1().Events(configMapNamespace).List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, eventList.Items, 1)
+	assert.Equal(t, events.ReasonInvalidInput, eventList.Items[0].Reason)
+	assert.Equal(t, errInvalidTimeout.Error(), eventList.Items[0].Message)
+}
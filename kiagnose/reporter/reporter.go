@@ -20,15 +20,18 @@
 package reporter
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
-	"strconv"
-	"strings"
-	"time"
+	"log"
 
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 
+	"github.com/kiagnose/kiagnose/kiagnose/artifacts"
 	"github.com/kiagnose/kiagnose/kiagnose/configmap"
 	"github.com/kiagnose/kiagnose/kiagnose/status"
 	"github.com/kiagnose/kiagnose/kiagnose/types"
@@ -37,12 +40,22 @@ import (
 var ErrConfigMapDataIsNil = errors.New("configMap Data is nil")
 
 type Reporter struct {
-	client    kubernetes.Interface
-	configMap *corev1.ConfigMap
+	client     kubernetes.Interface
+	configMap  *corev1.ConfigMap
+	sizeBudget int
 }
 
-func New(client kubernetes.Interface, configMapNamespace, configMapName string) *Reporter {
-	return &Reporter{
+type Option func(*Reporter)
+
+// WithSizeBudget sets the maximal size of the user ConfigMap data, above which results are compressed and spilled.
+func WithSizeBudget(size int) Option {
+	return func(r *Reporter) {
+		r.sizeBudget = size
+	}
+}
+
+func New(client kubernetes.Interface, configMapNamespace, configMapName string, options ...Option) *Reporter {
+	r := &Reporter{
 		client: client,
 		configMap: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -50,47 +63,111 @@ func New(client kubernetes.Interface, configMapNamespace, configMapName string)
 				Namespace: configMapNamespace,
 			},
 		},
+		sizeBudget: DefaultSizeBudget,
 	}
+
+	for _, option := range options {
+		option(r)
+	}
+
+	return r
 }
 
 func (r *Reporter) HasData() bool {
 	return r.configMap.Data != nil
 }
 
+// Report writes the checkup status to the ConfigMap.
+// Only the status keys are written, using a JSON merge patch, so concurrent changes to other
+// ConfigMap fields are preserved.
+// The patch is conditioned on the resourceVersion of the ConfigMap as last read, so it fails with a conflict
+// once the ConfigMap has changed since; the ConfigMap is then re-read, and the status is fitted and patched again.
+// Results which do not fit the ConfigMap size budget are compressed into binaryData,
+// or spilled into overflow ConfigMaps referenced by the status.overflow key.
 func (r *Reporter) Report(statusData status.Status) error {
 	if r.configMap.Data == nil {
-		configMap, err := configmap.Get(r.client, r.configMap.Namespace, r.configMap.Name)
-		if err != nil {
+		if err := r.refresh(); err != nil {
 			return err
 		}
-
-		r.configMap = configMap
 	}
 
 	if r.configMap.Data == nil {
 		return ErrConfigMapDataIsNil
 	}
 
-	if !statusData.StartTimestamp.IsZero() {
-		r.configMap.Data[types.StartTimestampKey] = statusData.StartTimestamp.Format(time.RFC3339)
+	data, err := status.ToConfigMapData(statusData)
+	if err != nil {
+		return err
 	}
 
-	if !statusData.CompletionTimestamp.IsZero() {
-		r.configMap.Data[types.CompletionTimestampKey] = statusData.CompletionTimestamp.Format(time.RFC3339)
-		r.configMap.Data[types.SucceededKey] = strconv.FormatBool(statusData.Succeeded)
-		r.configMap.Data[types.FailureReasonKey] = strings.Join(statusData.FailureReason, ",")
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		err := r.patch(statusData, data)
+		if k8serrors.IsConflict(err) {
+			if refreshErr := r.refresh(); refreshErr != nil {
+				return refreshErr
+			}
+		}
+		return err
+	})
+}
+
+// patch writes the status data to the ConfigMap, unless the ConfigMap has changed since it was last read.
+func (r *Reporter) patch(statusData status.Status, data map[string]string) error {
+	p := r.fit(data)
+	// The overflow holds results, so it is replaced by a report of the results or by a report which overflows.
+	previousOverflow, hasPreviousOverflow := r.configMap.Data[types.OverflowKey]
+	replacesOverflow := hasPreviousOverflow && (len(statusData.Results) > 0 || len(p.overflow) > 0)
+	if replacesOverflow {
+		p.data[types.OverflowKey] = nil
+	}
+	overflow := ""
+	if len(p.overflow) > 0 {
+		var err error
+		if overflow, err = r.storeOverflow(p.overflow); err != nil {
+			return err
+		}
+		p.data[types.OverflowKey] = overflow
 	}
 
-	for k, v := range statusData.Results {
-		r.configMap.Data[types.ResultsPrefix+k] = v
+	patchData := map[string]interface{}{
+		"metadata": map[string]interface{}{"resourceVersion": r.configMap.ResourceVersion},
+		"data":     p.data,
+	}
+	if len(p.binaryData) > 0 {
+		patchData["binaryData"] = p.binaryData
+	}
+	patch, err := json.Marshal(patchData)
+	if err != nil {
+		return err
 	}
 
-	updatedConfigMap, err := configmap.Update(r.client, r.configMap)
+	patchedConfigMap, err := configmap.Patch(r.client, r.configMap.Namespace, r.configMap.Name, patch)
 	if err != nil {
+		if overflow != "" {
+			// The overflow is not referenced, it is stored again once the report is retried.
+			if deleteErr := artifacts.Delete(context.Background(), r.client, r.configMap.Namespace, overflow); deleteErr != nil {
+				log.Printf("failed to delete the unreferenced overflow ConfigMaps: %v", deleteErr)
+			}
+		}
 		return err
 	}
+	r.configMap = patchedConfigMap
 
-	r.configMap = updatedConfigMap
+	if replacesOverflow && r.configMap.Data[types.OverflowKey] != previousOverflow {
+		if err := artifacts.Delete(context.Background(), r.client, r.configMap.Namespace, previousOverflow); err != nil {
+			log.Printf("failed to delete the previous overflow ConfigMaps: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Reporter) refresh() error {
+	configMap, err := configmap.Get(r.client, r.configMap.Namespace, r.configMap.Name)
+	if err != nil {
+		return err
+	}
 
+	r.configMap = configMap
 	return nil
 }
@@ -21,6 +21,11 @@ package reporter_test
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 	"testing"
@@ -29,11 +34,16 @@ import (
 	assert "github.com/stretchr/testify/require"
 
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
 
 	"github.com/kiagnose/kiagnose/kiagnose/configmap"
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/internal/generatename"
 	"github.com/kiagnose/kiagnose/kiagnose/reporter"
 	"github.com/kiagnose/kiagnose/kiagnose/status"
 	"github.com/kiagnose/kiagnose/kiagnose/types"
@@ -79,6 +89,28 @@ func TestReportShouldSucceed(t *testing.T) {
 		)
 	})
 
+	t.Run("on progress report", func(t *testing.T) {
+		setup()
+
+		checkupStatus.Phase = status.PhaseRunning
+		checkupStatus.Progress = "measuring"
+		checkupStatus.LastHeartbeat = checkupStatus.StartTimestamp.Add(time.Second)
+
+		assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+
+		expectedReportData := map[string]string{
+			types.StartTimestampKey: timestamp(checkupStatus.StartTimestamp),
+			types.PhaseKey:          string(status.PhaseRunning),
+			types.ProgressKey:       "measuring",
+			types.LastHeartbeatKey:  timestamp(checkupStatus.LastHeartbeat),
+		}
+
+		assert.Equal(t,
+			mergeMaps(checkupSpecData(), expectedReportData),
+			getCheckupData(t, fakeClient, configMapNamespace, configMapName),
+		)
+	})
+
 	testCases := []successTestCase{
 		{
 			description:   "on checkup successful completion",
@@ -119,6 +151,282 @@ func TestReportShouldSucceed(t *testing.T) {
 	}
 }
 
+func TestReportShouldWriteFailureDetails(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(newConfigMap(checkupSpecData()))
+	generatename.Emulate(fakeClient)
+	reporterUnderTest := reporter.New(fakeClient, configMapNamespace, configMapName)
+
+	checkupStatus := status.Status{
+		StartTimestamp:      time.Now(),
+		CompletionTimestamp: time.Now(),
+		FailureReason:       []string{"run: network not found"},
+		FailureDetails: []failure.Failure{
+			{Code: "NetworkNotFound", Phase: string(status.PhaseRunning), Message: "run: network not found", Retryable: true},
+		},
+	}
+	assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+
+	assert.JSONEq(t,
+		`[{"code":"NetworkNotFound","phase":"Running","message":"run: network not found","retryable":true}]`,
+		getCheckupData(t, fakeClient, configMapNamespace, configMapName)[types.FailureDetailsKey],
+	)
+}
+
+func TestReportShouldWriteArtifactReferences(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(newConfigMap(checkupSpecData()))
+	generatename.Emulate(fakeClient)
+	reporterUnderTest := reporter.New(fakeClient, configMapNamespace, configMapName)
+
+	const reference = `{"kind":"ConfigMap","objects":["cm-artifact-abcde"],"size":3}`
+	checkupStatus := status.Status{
+		StartTimestamp:      time.Now(),
+		CompletionTimestamp: time.Now(),
+		Succeeded:           true,
+		Artifacts:           map[string]string{"console.log": reference},
+	}
+	assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+
+	assert.Equal(t, reference, getCheckupData(t, fakeClient, configMapNamespace, configMapName)[types.ArtifactsPrefix+"console.log"])
+}
+
+func TestReportShouldFitSizeBudget(t *testing.T) {
+	const sizeBudget = 4 * 1024
+
+	t.Run("by compressing large results into binaryData", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newConfigMap(checkupSpecData()))
+		generatename.Emulate(fakeClient)
+		reporterUnderTest := reporter.New(fakeClient, configMapNamespace, configMapName, reporter.WithSizeBudget(sizeBudget))
+
+		checkupStatus := completedStatus(map[string]string{
+			"samples": strings.Repeat("1ms,", 2*sizeBudget),
+			"count":   "8192",
+		})
+		assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+
+		configMap, err := configmap.Get(fakeClient, configMapNamespace, configMapName)
+		assert.NoError(t, err)
+		assert.NotContains(t, configMap.Data, types.ResultsPrefix+"samples")
+		assert.Contains(t, configMap.BinaryData, types.ResultsPrefix+"samples")
+		assert.Equal(t, "8192", configMap.Data[types.ResultsPrefix+"count"])
+		assert.NotContains(t, configMap.Data, types.OverflowKey)
+
+		assertReportedStatus(t, fakeClient, checkupStatus)
+	})
+
+	t.Run("by spilling results into overflow ConfigMaps, while keeping the core keys", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newConfigMap(checkupSpecData()))
+		generatename.Emulate(fakeClient)
+		reporterUnderTest := reporter.New(fakeClient, configMapNamespace, configMapName, reporter.WithSizeBudget(sizeBudget))
+
+		checkupStatus := completedStatus(randomResults(t, 8, sizeBudget/2))
+		checkupStatus.Succeeded = false
+		checkupStatus.FailureReason = []string{"some reason"}
+		assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+
+		configMap, err := configmap.Get(fakeClient, configMapNamespace, configMapName)
+		assert.NoError(t, err)
+		assert.Contains(t, configMap.Data, types.OverflowKey)
+		assert.Equal(t, "false", configMap.Data[types.SucceededKey])
+		assert.Equal(t, "some reason", configMap.Data[types.FailureReasonKey])
+		assert.LessOrEqual(t, dataSize(configMap), sizeBudget)
+
+		assertReportedStatus(t, fakeClient, checkupStatus)
+	})
+
+	t.Run("by replacing the overflow of a previous report", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newConfigMap(checkupSpecData()))
+		generatename.Emulate(fakeClient)
+		reporterUnderTest := reporter.New(fakeClient, configMapNamespace, configMapName, reporter.WithSizeBudget(sizeBudget))
+
+		assert.NoError(t, reporterUnderTest.Report(completedStatus(randomResults(t, 8, sizeBudget/2))))
+		checkupStatus := completedStatus(map[string]string{"count": "1"})
+		assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+
+		configMap, err := configmap.Get(fakeClient, configMapNamespace, configMapName)
+		assert.NoError(t, err)
+		assert.NotContains(t, configMap.Data, types.OverflowKey)
+		configMaps, err := fakeClient.CoreV1().ConfigMaps(configMapNamespace).List(context.Background(), metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, configMaps.Items, 1)
+	})
+
+	t.Run("by replacing the overflow of a previous report without results", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newConfigMap(checkupSpecData()))
+		generatename.Emulate(fakeClient)
+		reporterUnderTest := reporter.New(fakeClient, configMapNamespace, configMapName, reporter.WithSizeBudget(sizeBudget))
+
+		const reports = 3
+		for i := 0; i < reports; i++ {
+			checkupStatus := status.Status{StartTimestamp: time.Now(), LastHeartbeat: time.Now()}
+			for attempt := 0; attempt < 4; attempt++ {
+				checkupStatus.Attempts = append(checkupStatus.Attempts, status.Attempt{Results: randomResults(t, 1, sizeBudget/2)})
+			}
+			assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+		}
+
+		configMap, err := configmap.Get(fakeClient, configMapNamespace, configMapName)
+		assert.NoError(t, err)
+		assert.Contains(t, configMap.Data, types.OverflowKey)
+		configMaps, err := fakeClient.CoreV1().ConfigMaps(configMapNamespace).List(context.Background(), metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, configMaps.Items, 2)
+	})
+
+	t.Run("by keeping the overflow of a previous report, on a report without results which fits", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newConfigMap(checkupSpecData()))
+		generatename.Emulate(fakeClient)
+		reporterUnderTest := reporter.New(fakeClient, configMapNamespace, configMapName, reporter.WithSizeBudget(sizeBudget))
+
+		checkupStatus := completedStatus(randomResults(t, 8, sizeBudget/2))
+		assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+		checkupStatus.Results = nil
+		checkupStatus.LastHeartbeat = time.Now()
+		assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+
+		configMap, err := configmap.Get(fakeClient, configMapNamespace, configMapName)
+		assert.NoError(t, err)
+		assert.Contains(t, configMap.Data, types.OverflowKey)
+		configMaps, err := fakeClient.CoreV1().ConfigMaps(configMapNamespace).List(context.Background(), metav1.ListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, configMaps.Items, 2)
+	})
+}
+
+func completedStatus(results map[string]string) status.Status {
+	now := time.Now().Truncate(time.Second)
+	return status.Status{
+		StartTimestamp:      now,
+		CompletionTimestamp: now,
+		Succeeded:           true,
+		Results:             results,
+	}
+}
+
+// randomResults returns incompressible results.
+func randomResults(t *testing.T, count, size int) map[string]string {
+	results := map[string]string{}
+	for i := 0; i < count; i++ {
+		raw := make([]byte, size/2)
+		_, err := rand.Read(raw)
+		assert.NoError(t, err)
+		results[fmt.Sprintf("sample%d", i)] = hex.EncodeToString(raw)
+	}
+	return results
+}
+
+func assertReportedStatus(t *testing.T, client kubernetes.Interface, expectedStatus status.Status) {
+	configMap, err := configmap.Get(client, configMapNamespace, configMapName)
+	assert.NoError(t, err)
+
+	actualStatus, err := status.FromConfigMap(context.Background(), client, configMap)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedStatus.Results, actualStatus.Results)
+	assert.Equal(t, len(expectedStatus.FailureReason) == 0, actualStatus.Succeeded)
+}
+
+func dataSize(configMap *corev1.ConfigMap) int {
+	size := 0
+	for k, v := range configMap.Data {
+		size += len(k) + len(v)
+	}
+	for k, v := range configMap.BinaryData {
+		size += len(k) + len(v)
+	}
+	return size
+}
+
+func TestReportShouldPreserveConcurrentChanges(t *testing.T) {
+	const (
+		userLabelKey   = "team"
+		userLabelValue = "network"
+		userDataKey    = "note"
+		userDataValue  = "checked by the night shift"
+	)
+
+	fakeClient := fake.NewSimpleClientset(newConfigMap(checkupSpecData()))
+	generatename.Emulate(fakeClient)
+	reporterUnderTest := reporter.New(fakeClient, configMapNamespace, configMapName)
+
+	checkupStatus := status.Status{StartTimestamp: time.Now()}
+	assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+
+	configMap, err := configmap.Get(fakeClient, configMapNamespace, configMapName)
+	assert.NoError(t, err)
+	configMap.Labels = map[string]string{userLabelKey: userLabelValue}
+	configMap.Data[userDataKey] = userDataValue
+	_, err = configmap.Update(fakeClient, configMap)
+	assert.NoError(t, err)
+
+	checkupStatus.CompletionTimestamp = checkupStatus.StartTimestamp.Add(time.Minute)
+	checkupStatus.Succeeded = true
+	assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+
+	configMap, err = configmap.Get(fakeClient, configMapNamespace, configMapName)
+	assert.NoError(t, err)
+	assert.Equal(t, userLabelValue, configMap.Labels[userLabelKey])
+	assert.Equal(t, userDataValue, configMap.Data[userDataKey])
+	assert.Equal(t, "true", configMap.Data[types.SucceededKey])
+}
+
+func TestReportShouldRetryOnConflict(t *testing.T) {
+	const (
+		userDataKey   = "note"
+		userDataValue = "checked by the night shift"
+	)
+
+	fakeClient := fake.NewSimpleClientset(newConfigMap(checkupSpecData()))
+	generatename.Emulate(fakeClient)
+	conflicts := withOptimisticConcurrency(fakeClient)
+	reporterUnderTest := reporter.New(fakeClient, configMapNamespace, configMapName)
+
+	checkupStatus := status.Status{StartTimestamp: time.Now()}
+	assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+	assert.Zero(t, *conflicts)
+
+	// A concurrent change makes the ConfigMap read by the reporter stale.
+	configMap, err := configmap.Get(fakeClient, configMapNamespace, configMapName)
+	assert.NoError(t, err)
+	configMap.Data[userDataKey] = userDataValue
+	_, err = configmap.Update(fakeClient, configMap)
+	assert.NoError(t, err)
+
+	checkupStatus.CompletionTimestamp = checkupStatus.StartTimestamp.Add(time.Minute)
+	checkupStatus.Succeeded = true
+	assert.NoError(t, reporterUnderTest.Report(checkupStatus))
+
+	assert.Equal(t, 1, *conflicts)
+	data := getCheckupData(t, fakeClient, configMapNamespace, configMapName)
+	assert.Equal(t, userDataValue, data[userDataKey])
+	assert.Equal(t, "true", data[types.SucceededKey])
+}
+
+func TestReportShouldFailOnPersistentConflicts(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(newConfigMap(checkupSpecData()))
+	generatename.Emulate(fakeClient)
+	withOptimisticConcurrency(fakeClient)
+	reporterUnderTest := reporter.New(fakeClient, configMapNamespace, configMapName)
+	assert.NoError(t, reporterUnderTest.Report(status.Status{StartTimestamp: time.Now()}))
+
+	// Another writer changes the ConfigMap after the reporter read it, and again whenever the reporter re-reads it.
+	configMap, err := configmap.Get(fakeClient, configMapNamespace, configMapName)
+	assert.NoError(t, err)
+	_, err = configmap.Update(fakeClient, configMap)
+	assert.NoError(t, err)
+	fakeClient.PrependReactor("get", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		obj, err := fakeClient.Tracker().Get(corev1.SchemeGroupVersion.WithResource("configmaps"), configMapNamespace, configMapName)
+		if err != nil {
+			return true, nil, err
+		}
+		configMap := obj.(*corev1.ConfigMap)
+		stale := configMap.DeepCopy()
+		configMap.ResourceVersion = nextResourceVersion(configMap.ResourceVersion)
+		return true, stale, fakeClient.Tracker().Update(corev1.SchemeGroupVersion.WithResource("configmaps"), configMap, configMapNamespace)
+	})
+
+	err = reporterUnderTest.Report(status.Status{StartTimestamp: time.Now(), CompletionTimestamp: time.Now()})
+	assert.True(t, k8serrors.IsConflict(err))
+}
+
 func TestReportShouldFail(t *testing.T) {
 	t.Run("when checkup spec is fetched with nil Data", func(t *testing.T) {
 		fakeClient := fake.NewSimpleClientset(newConfigMap(nil))
@@ -140,6 +448,7 @@ func TestReportShouldFail(t *testing.T) {
 
 	t.Run("when checkup status fails to be updated", func(t *testing.T) {
 		fakeClient := fake.NewSimpleClientset(newConfigMap(checkupSpecData()))
+		generatename.Emulate(fakeClient)
 		reporterUnderTest := reporter.New(fakeClient, configMapNamespace, configMapName)
 
 		checkupStatus := status.Status{}
@@ -198,3 +507,57 @@ func getCheckupData(t *testing.T, client kubernetes.Interface, configMapNamespac
 func injectFailureToAccessCheckupData(t *testing.T, client kubernetes.Interface, configMapNamespace, configMapName string) {
 	assert.NoError(t, client.CoreV1().ConfigMaps(configMapNamespace).Delete(context.Background(), configMapName, metav1.DeleteOptions{}))
 }
+
+// withOptimisticConcurrency makes the fake client behave as the API server does with ConfigMap writes:
+// each write bumps the ConfigMap resourceVersion, and a write conditioned on a stale resourceVersion fails with a conflict.
+// It returns the number of conflicts which occurred.
+func withOptimisticConcurrency(client *fake.Clientset) *int {
+	conflicts := 0
+	tracker := client.Tracker()
+
+	reactor := func(action clienttesting.Action) (bool, runtime.Object, error) {
+		obj, err := tracker.Get(action.GetResource(), action.GetNamespace(), configMapName)
+		if err != nil {
+			return false, nil, nil
+		}
+		currentVersion := obj.(*corev1.ConfigMap).ResourceVersion
+
+		var expectedVersion string
+		switch a := action.(type) {
+		case clienttesting.UpdateAction:
+			expectedVersion = a.GetObject().(*corev1.ConfigMap).ResourceVersion
+		case clienttesting.PatchAction:
+			var patch struct {
+				Metadata struct {
+					ResourceVersion string `json:"resourceVersion"`
+				} `json:"metadata"`
+			}
+			if err := json.Unmarshal(a.GetPatch(), &patch); err != nil {
+				return true, nil, err
+			}
+			expectedVersion = patch.Metadata.ResourceVersion
+		}
+
+		if expectedVersion != "" && expectedVersion != currentVersion {
+			conflicts++
+			return true, nil, k8serrors.NewConflict(corev1.Resource("configmaps"), configMapName, errors.New("object has been modified"))
+		}
+
+		_, written, err := clienttesting.ObjectReaction(tracker)(action)
+		if err != nil {
+			return true, nil, err
+		}
+		configMap := written.(*corev1.ConfigMap)
+		configMap.ResourceVersion = nextResourceVersion(currentVersion)
+		return true, configMap, tracker.Update(action.GetResource(), configMap, action.GetNamespace())
+	}
+
+	client.PrependReactor("update", "configmaps", reactor)
+	client.PrependReactor("patch", "configmaps", reactor)
+	return &conflicts
+}
+
+func nextResourceVersion(resourceVersion string) string {
+	version, _ := strconv.Atoi(resourceVersion)
+	return strconv.Itoa(version + 1)
+}
@@ -0,0 +1,171 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const DefaultRetention = 5
+
+var ErrRetentionIsIllegal = errors.New("schedule retention is illegal")
+
+// templateAnnotations are the annotations configuring the schedule, which are not copied into the runs.
+var templateAnnotations = map[string]struct{}{
+	types.ScheduleAnnotation:          {},
+	types.ScheduleRetentionAnnotation: {},
+	types.SummaryResultsAnnotation:    {},
+	types.LastScheduleTimeAnnotation:  {},
+}
+
+// RunNameFor returns the name of the ConfigMap of the run scheduled at the given time.
+func RunNameFor(templateName string, scheduledTime time.Time) string {
+	return fmt.Sprintf("%s-%d", templateName, scheduledTime.Unix())
+}
+
+// NewRun clones the template ConfigMap into the ConfigMap of the run scheduled at the given time.
+// The run is labeled with the template name and owned by it, and carries the template input fields
+// and annotations, except for those configuring the schedule.
+func NewRun(template *corev1.ConfigMap, scheduledTime time.Time) *corev1.ConfigMap {
+	annotations := map[string]string{types.ScheduledTimeAnnotation: scheduledTime.UTC().Format(time.RFC3339)}
+	for k, v := range template.Annotations {
+		if _, exists := templateAnnotations[k]; !exists {
+			annotations[k] = v
+		}
+	}
+
+	data := map[string]string{}
+	for k, v := range template.Data {
+		if !strings.HasPrefix(k, types.StatusKeyPrefix) {
+			data[k] = v
+		}
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            RunNameFor(template.Name, scheduledTime),
+			Namespace:       template.Namespace,
+			Labels:          map[string]string{types.ScheduledByLabel: template.Name},
+			Annotations:     annotations,
+			OwnerReferences: []metav1.OwnerReference{ownerReference(template)},
+		},
+		Data: data,
+	}
+}
+
+// ScheduledTime returns the time a run was scheduled at, or the zero time in case it is unknown.
+func ScheduledTime(run *corev1.ConfigMap) time.Time {
+	scheduledTime, err := time.Parse(time.RFC3339, run.Annotations[types.ScheduledTimeAnnotation])
+	if err != nil {
+		return time.Time{}
+	}
+	return scheduledTime
+}
+
+// Retention returns the number of completed runs to keep, as set on the template ConfigMap.
+func Retention(template *corev1.ConfigMap) (int, error) {
+	rawRetention, exists := template.Annotations[types.ScheduleRetentionAnnotation]
+	if !exists {
+		return DefaultRetention, nil
+	}
+
+	retention, err := strconv.Atoi(rawRetention)
+	if err != nil || retention < 1 {
+		return 0, fmt.Errorf("%w: %q: should be a positive integer", ErrRetentionIsIllegal, rawRetention)
+	}
+	return retention, nil
+}
+
+// ListRuns returns the runs scheduled from the given template, ordered from the oldest to the latest.
+func ListRuns(client kubernetes.Interface, namespace, templateName string) ([]corev1.ConfigMap, error) {
+	selector := labels.SelectorFromSet(labels.Set{types.ScheduledByLabel: templateName})
+	configMapList, err := client.CoreV1().ConfigMaps(namespace).List(
+		context.Background(), metav1.ListOptions{LabelSelector: selector.String()},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := configMapList.Items
+	sort.SliceStable(runs, func(i, j int) bool {
+		return ScheduledTime(&runs[i]).Before(ScheduledTime(&runs[j]))
+	})
+
+	return runs, nil
+}
+
+// Prune deletes the completed runs beyond the given retention, oldest first, and returns the remaining runs.
+// Runs which have not completed yet are kept.
+// The objects created by a run (e.g. its Job and artifacts) are owned by its ConfigMap and garbage collected with it.
+func Prune(client kubernetes.Interface, runs []corev1.ConfigMap, retention int) ([]corev1.ConfigMap, error) {
+	completedCount := 0
+	for i := range runs {
+		if IsCompleted(&runs[i]) {
+			completedCount++
+		}
+	}
+
+	var remaining []corev1.ConfigMap
+	for i := range runs {
+		if completedCount > retention && IsCompleted(&runs[i]) {
+			err := client.CoreV1().ConfigMaps(runs[i].Namespace).Delete(context.Background(), runs[i].Name, deleteOptions())
+			if err != nil {
+				return nil, err
+			}
+			completedCount--
+			continue
+		}
+		remaining = append(remaining, runs[i])
+	}
+
+	return remaining, nil
+}
+
+func IsCompleted(run *corev1.ConfigMap) bool {
+	_, exists := run.Data[types.CompletionTimestampKey]
+	return exists
+}
+
+func ownerReference(template *corev1.ConfigMap) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Name:       template.Name,
+		UID:        template.UID,
+	}
+}
+
+func deleteOptions() metav1.DeleteOptions {
+	propagationPolicy := metav1.DeletePropagationBackground
+	return metav1.DeleteOptions{PropagationPolicy: &propagationPolicy}
+}
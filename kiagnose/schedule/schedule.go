@@ -0,0 +1,238 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrScheduleIsIllegal = errors.New("schedule is illegal")
+
+// MinInterval is the shortest interval accepted by an @every schedule.
+const MinInterval = time.Second
+
+// Schedule computes the times at which a periodic checkup is run.
+type Schedule interface {
+	// Next returns the first scheduled time after the given time.
+	Next(t time.Time) time.Time
+}
+
+var shorthands = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a schedule given in the cron format ("minute hour day-of-month month day-of-week"),
+// as one of the @yearly, @monthly, @weekly, @daily and @hourly shorthands, or as "@every <duration>".
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if rawInterval := strings.TrimPrefix(spec, "@every "); rawInterval != spec {
+		interval, err := time.ParseDuration(strings.TrimSpace(rawInterval))
+		if err != nil || interval < MinInterval {
+			return nil, fmt.Errorf("%w: %q: interval should be a duration of at least %s", ErrScheduleIsIllegal, spec, MinInterval)
+		}
+		return every(interval), nil
+	}
+
+	if expanded, exists := shorthands[spec]; exists {
+		spec = expanded
+	}
+
+	c, err := parseCron(spec)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %v", ErrScheduleIsIllegal, spec, err)
+	}
+	return c, nil
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e)).Truncate(time.Second)
+}
+
+// cron is a set of allowed values per field, as bit masks.
+type cron struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+	// Days match when either the day of month or the day of week matches, in case both are restricted.
+	anyDayOfMonth, anyDayOfWeek bool
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField     = field{name: "minute", min: 0, max: 59}
+	hourField       = field{name: "hour", min: 0, max: 23}
+	dayOfMonthField = field{name: "day of month", min: 1, max: 31}
+	monthField      = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6, "jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Both 0 and 7 stand for Sunday.
+	dayOfWeekField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+func parseCron(spec string) (*cron, error) {
+	const fieldsCount = 5
+
+	fields := strings.Fields(spec)
+	if len(fields) != fieldsCount {
+		return nil, fmt.Errorf("expected %d fields, found %d", fieldsCount, len(fields))
+	}
+
+	c := &cron{
+		anyDayOfMonth: fields[2] == "*",
+		anyDayOfWeek:  fields[4] == "*",
+	}
+
+	targets := []struct {
+		mask  *uint64
+		field field
+	}{
+		{&c.minute, minuteField},
+		{&c.hour, hourField},
+		{&c.dayOfMonth, dayOfMonthField},
+		{&c.month, monthField},
+		{&c.dayOfWeek, dayOfWeekField},
+	}
+
+	for i, target := range targets {
+		mask, err := target.field.parse(fields[i])
+		if err != nil {
+			return nil, err
+		}
+		*target.mask = mask
+	}
+
+	const sunday = 7
+	if c.dayOfWeek&(1<<sunday) != 0 {
+		c.dayOfWeek |= 1
+	}
+
+	return c, nil
+}
+
+// parse parses a comma separated list of values, ranges ("a-b") and steps ("*/n", "a-b/n" or "a/n").
+func (f field) parse(raw string) (uint64, error) {
+	var mask uint64
+
+	for _, element := range strings.Split(raw, ",") {
+		rawRange, rawStep, hasStep := strings.Cut(element, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(rawStep); err != nil || step <= 0 {
+				return 0, fmt.Errorf("illegal %s step %q", f.name, rawStep)
+			}
+		}
+
+		first, last := f.min, f.max
+		if rawRange != "*" {
+			rawFirst, rawLast, isRange := strings.Cut(rawRange, "-")
+
+			var err error
+			if first, err = f.value(rawFirst); err != nil {
+				return 0, err
+			}
+			last = first
+			if isRange {
+				if last, err = f.value(rawLast); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				last = f.max
+			}
+		}
+
+		if first > last {
+			return 0, fmt.Errorf("illegal %s range %q", f.name, rawRange)
+		}
+
+		for v := first; v <= last; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+
+	return mask, nil
+}
+
+func (f field) value(raw string) (int, error) {
+	if v, exists := f.names[strings.ToLower(raw)]; exists {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("illegal %s %q, expected a value between %d and %d", f.name, raw, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next finds the first matching minute after t, advancing by the largest mismatching unit.
+// It returns the zero time when no time matches within the next years, e.g. for February 30.
+func (c *cron) Next(t time.Time) time.Time {
+	const searchYears = 5
+
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(searchYears, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (c *cron) matchesDay(t time.Time) bool {
+	dayOfMonth := c.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := c.dayOfWeek&(1<<uint(t.Weekday())) != 0
+
+	if c.anyDayOfMonth || c.anyDayOfWeek {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
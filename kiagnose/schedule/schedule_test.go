@@ -0,0 +1,95 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package schedule_test
+
+import (
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/kiagnose/kiagnose/kiagnose/schedule"
+)
+
+func TestNextShouldReturnFirstScheduledTimeAfterGivenTime(t *testing.T) {
+	// A Monday.
+	from := time.Date(2022, time.June, 13, 10, 30, 15, 0, time.UTC)
+
+	testCases := []struct {
+		description string
+		schedule    string
+		expected    time.Time
+	}{
+		{"every minute", "* * * * *", time.Date(2022, time.June, 13, 10, 31, 0, 0, time.UTC)},
+		{"daily shorthand", "@daily", time.Date(2022, time.June, 14, 0, 0, 0, 0, time.UTC)},
+		{"hourly shorthand", "@hourly", time.Date(2022, time.June, 13, 11, 0, 0, 0, time.UTC)},
+		{"weekly shorthand", "@weekly", time.Date(2022, time.June, 19, 0, 0, 0, 0, time.UTC)},
+		{"monthly shorthand", "@monthly", time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{"nightly at a given time", "30 2 * * *", time.Date(2022, time.June, 14, 2, 30, 0, 0, time.UTC)},
+		{"list", "0,45 * * * *", time.Date(2022, time.June, 13, 10, 45, 0, 0, time.UTC)},
+		{"step", "*/20 * * * *", time.Date(2022, time.June, 13, 10, 40, 0, 0, time.UTC)},
+		{"range with step", "0 1-23/6 * * *", time.Date(2022, time.June, 13, 13, 0, 0, 0, time.UTC)},
+		{"day of week list", "0 0 * * sat,sun", time.Date(2022, time.June, 18, 0, 0, 0, 0, time.UTC)},
+		{"day of week range", "0 0 * * tue-fri", time.Date(2022, time.June, 14, 0, 0, 0, 0, time.UTC)},
+		{"Sunday as 7", "0 0 * * 7", time.Date(2022, time.June, 19, 0, 0, 0, 0, time.UTC)},
+		{"month name", "0 0 1 jan *", time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"either day of month or day of week", "0 0 15 * mon", time.Date(2022, time.June, 15, 0, 0, 0, 0, time.UTC)},
+		{"leap day", "0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"interval", "@every 90s", time.Date(2022, time.June, 13, 10, 31, 45, 0, time.UTC)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			s, err := schedule.Parse(testCase.schedule)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, s.Next(from))
+		})
+	}
+}
+
+func TestNextShouldReturnZeroTimeWhenScheduleNeverMatches(t *testing.T) {
+	s, err := schedule.Parse("0 0 30 2 *")
+	assert.NoError(t, err)
+	assert.True(t, s.Next(time.Now()).IsZero())
+}
+
+func TestParseShouldFail(t *testing.T) {
+	testCases := []struct {
+		description string
+		schedule    string
+	}{
+		{"when schedule is empty", ""},
+		{"when fields are missing", "0 0 * *"},
+		{"when a value is out of range", "60 * * * *"},
+		{"when a value is not a number", "x * * * *"},
+		{"when a range is reversed", "0 10-5 * * *"},
+		{"when a step is zero", "*/0 * * * *"},
+		{"when shorthand is unknown", "@nightly"},
+		{"when interval is not a duration", "@every day"},
+		{"when interval is too short", "@every 100ms"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			_, err := schedule.Parse(testCase.schedule)
+			assert.ErrorIs(t, err, schedule.ErrScheduleIsIllegal)
+		})
+	}
+}
@@ -0,0 +1,230 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const (
+	floatBitSize     = 64
+	averagePrecision = 3
+)
+
+// RunSummary is the outcome of a single scheduled run, as listed in the summary ConfigMap.
+type RunSummary struct {
+	Name                string            `json:"name"`
+	ScheduledTime       string            `json:"scheduledTime"`
+	Succeeded           bool              `json:"succeeded"`
+	StartTimestamp      string            `json:"startTimestamp,omitempty"`
+	CompletionTimestamp string            `json:"completionTimestamp,omitempty"`
+	FailureReason       string            `json:"failureReason,omitempty"`
+	Results             map[string]string `json:"results,omitempty"`
+}
+
+// SummaryNameFor returns the name of the ConfigMap summarizing the runs of the given template.
+func SummaryNameFor(templateName string) string {
+	return templateName + "-summary"
+}
+
+// Summarize aggregates the completed runs into the summary ConfigMap data:
+// the runs outcome from the latest to the oldest, the succeeded and failed runs count,
+// and the last, minimal, maximal and average values of each numeric result.
+// Only the results listed on the template are summarized, or all of them in case none is listed.
+func Summarize(template *corev1.ConfigMap, runs []corev1.ConfigMap) (map[string]string, error) {
+	resultNames := summaryResultNames(template)
+
+	var summaries []RunSummary
+	for i := len(runs) - 1; i >= 0; i-- {
+		if IsCompleted(&runs[i]) {
+			summaries = append(summaries, newRunSummary(&runs[i], resultNames))
+		}
+	}
+
+	rawSummaries, err := json.Marshal(summaries)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]string{types.SummaryRunsKey: string(rawSummaries)}
+
+	succeededCount := 0
+	for _, summary := range summaries {
+		if summary.Succeeded {
+			succeededCount++
+		}
+	}
+	data[types.SummarySucceededKey] = strconv.Itoa(succeededCount)
+	data[types.SummaryFailedKey] = strconv.Itoa(len(summaries) - succeededCount)
+
+	if len(summaries) > 0 {
+		data[types.SummaryLastRunKey] = summaries[0].Name
+	}
+
+	for k, v := range summarizeResults(summaries) {
+		data[k] = v
+	}
+
+	return data, nil
+}
+
+// ApplySummary creates or updates the ConfigMap summarizing the runs of the given template.
+// The summary ConfigMap is owned by the template.
+func ApplySummary(client kubernetes.Interface, template *corev1.ConfigMap, runs []corev1.ConfigMap) error {
+	data, err := Summarize(template, runs)
+	if err != nil {
+		return err
+	}
+
+	configMaps := client.CoreV1().ConfigMaps(template.Namespace)
+	summary, err := configMaps.Get(context.Background(), SummaryNameFor(template.Name), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		_, err = configMaps.Create(context.Background(), newSummary(template, data), metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if equal(summary.Data, data) {
+		return nil
+	}
+
+	summary.Data = data
+	_, err = configMaps.Update(context.Background(), summary, metav1.UpdateOptions{})
+	return err
+}
+
+func newSummary(template *corev1.ConfigMap, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            SummaryNameFor(template.Name),
+			Namespace:       template.Namespace,
+			Labels:          map[string]string{types.SummaryOfLabel: template.Name},
+			OwnerReferences: []metav1.OwnerReference{ownerReference(template)},
+		},
+		Data: data,
+	}
+}
+
+func newRunSummary(run *corev1.ConfigMap, resultNames map[string]struct{}) RunSummary {
+	results := map[string]string{}
+	for k, v := range run.Data {
+		name := strings.TrimPrefix(k, types.ResultsPrefix)
+		if name == k {
+			continue
+		}
+		if _, selected := resultNames[name]; len(resultNames) == 0 || selected {
+			results[name] = v
+		}
+	}
+
+	succeeded, _ := strconv.ParseBool(run.Data[types.SucceededKey])
+	return RunSummary{
+		Name:                run.Name,
+		ScheduledTime:       run.Annotations[types.ScheduledTimeAnnotation],
+		Succeeded:           succeeded,
+		StartTimestamp:      run.Data[types.StartTimestampKey],
+		CompletionTimestamp: run.Data[types.CompletionTimestampKey],
+		FailureReason:       run.Data[types.FailureReasonKey],
+		Results:             results,
+	}
+}
+
+func summaryResultNames(template *corev1.ConfigMap) map[string]struct{} {
+	names := map[string]struct{}{}
+	for _, name := range strings.Split(template.Annotations[types.SummaryResultsAnnotation], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names[name] = struct{}{}
+		}
+	}
+	return names
+}
+
+// summarizeResults computes the statistics of the numeric results, given the runs from the latest to the oldest.
+// Results which are not numeric in any of the runs are skipped.
+func summarizeResults(summaries []RunSummary) map[string]string {
+	values := map[string][]float64{}
+	nonNumeric := map[string]struct{}{}
+	for _, summary := range summaries {
+		for name, rawValue := range summary.Results {
+			value, err := strconv.ParseFloat(rawValue, floatBitSize)
+			if err != nil {
+				nonNumeric[name] = struct{}{}
+				continue
+			}
+			values[name] = append(values[name], value)
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		if _, skipped := nonNumeric[name]; !skipped {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	data := map[string]string{}
+	for _, name := range names {
+		minValue, maxValue, sum := math.Inf(1), math.Inf(-1), 0.0
+		for _, value := range values[name] {
+			minValue = math.Min(minValue, value)
+			maxValue = math.Max(maxValue, value)
+			sum += value
+		}
+
+		prefix := types.SummaryResultsKeyPrefix + name + "."
+		data[prefix+"last"] = formatFloat(values[name][0])
+		data[prefix+"min"] = formatFloat(minValue)
+		data[prefix+"max"] = formatFloat(maxValue)
+		data[prefix+"avg"] = strconv.FormatFloat(sum/float64(len(values[name])), 'f', averagePrecision, floatBitSize)
+	}
+
+	return data
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, floatBitSize)
+}
+
+func equal(data1, data2 map[string]string) bool {
+	if len(data1) != len(data2) {
+		return false
+	}
+	for k, v := range data1 {
+		if v2, exists := data2[k]; !exists || v != v2 {
+			return false
+		}
+	}
+	return true
+}
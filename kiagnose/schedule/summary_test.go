@@ -0,0 +1,203 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package schedule_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kiagnose/kiagnose/kiagnose/configmap"
+	"github.com/kiagnose/kiagnose/kiagnose/schedule"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const (
+	testNamespace    = "target-ns"
+	testTemplateName = "nightly-latency"
+)
+
+var testScheduleStart = time.Date(2022, time.June, 13, 2, 30, 0, 0, time.UTC)
+
+func TestNewRunShouldCloneTemplate(t *testing.T) {
+	template := newTemplate()
+	template.Data[types.SucceededKey] = "true"
+
+	run := schedule.NewRun(template, testScheduleStart)
+
+	assert.Equal(t, schedule.RunNameFor(testTemplateName, testScheduleStart), run.Name)
+	assert.Equal(t, map[string]string{types.ScheduledByLabel: testTemplateName}, run.Labels)
+	assert.Equal(t, map[string]string{
+		types.CheckupImageAnnotation:  "my-registry/example-checkup:main",
+		types.ScheduledTimeAnnotation: "2022-06-13T02:30:00Z",
+	}, run.Annotations)
+	assert.Equal(t, map[string]string{types.TimeoutKey: "1m"}, run.Data)
+	assert.Equal(t, testTemplateName, run.OwnerReferences[0].Name)
+	assert.Equal(t, testScheduleStart, schedule.ScheduledTime(run))
+}
+
+func TestRetention(t *testing.T) {
+	template := newTemplate()
+	delete(template.Annotations, types.ScheduleRetentionAnnotation)
+	retention, err := schedule.Retention(template)
+	assert.NoError(t, err)
+	assert.Equal(t, schedule.DefaultRetention, retention)
+
+	template.Annotations[types.ScheduleRetentionAnnotation] = "2"
+	retention, err = schedule.Retention(template)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, retention)
+
+	for _, rawRetention := range []string{"0", "-1", "two"} {
+		template.Annotations[types.ScheduleRetentionAnnotation] = rawRetention
+		_, err = schedule.Retention(template)
+		assert.ErrorIs(t, err, schedule.ErrRetentionIsIllegal, rawRetention)
+	}
+}
+
+func TestPruneShouldKeepRetainedAndActiveRuns(t *testing.T) {
+	template := newTemplate()
+	fakeClient := fake.NewSimpleClientset(
+		newRun(template, 3, nil),
+		newRun(template, 0, completedRunData("true", "10")),
+		newRun(template, 2, completedRunData("true", "30")),
+		newRun(template, 1, completedRunData("false", "20")),
+	)
+
+	runs, err := schedule.ListRuns(fakeClient, testNamespace, testTemplateName)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{runName(0), runName(1), runName(2), runName(3)}, names(runs))
+
+	remaining, err := schedule.Prune(fakeClient, runs, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{runName(1), runName(2), runName(3)}, names(remaining))
+
+	runs, err = schedule.ListRuns(fakeClient, testNamespace, testTemplateName)
+	assert.NoError(t, err)
+	assert.Equal(t, names(remaining), names(runs))
+}
+
+func TestSummarizeShouldAggregateCompletedRuns(t *testing.T) {
+	template := newTemplate()
+	template.Annotations[types.SummaryResultsAnnotation] = "latency, node"
+	runs := []corev1.ConfigMap{
+		*newRun(template, 0, completedRunData("true", "10")),
+		*newRun(template, 1, completedRunData("false", "20")),
+		*newRun(template, 2, completedRunData("true", "30")),
+		*newRun(template, 3, nil),
+	}
+	runs[2].Data[types.ResultsPrefix+"node"] = "worker1"
+
+	data, err := schedule.Summarize(template, runs)
+	assert.NoError(t, err)
+
+	var summaries []schedule.RunSummary
+	assert.NoError(t, json.Unmarshal([]byte(data[types.SummaryRunsKey]), &summaries))
+	assert.Equal(t, []string{runName(2), runName(1), runName(0)}, []string{summaries[0].Name, summaries[1].Name, summaries[2].Name})
+	assert.Equal(t, map[string]string{"latency": "30", "node": "worker1"}, summaries[0].Results)
+	assert.False(t, summaries[1].Succeeded)
+
+	delete(data, types.SummaryRunsKey)
+	assert.Equal(t, map[string]string{
+		types.SummarySucceededKey:                      "2",
+		types.SummaryFailedKey:                         "1",
+		types.SummaryLastRunKey:                        runName(2),
+		types.SummaryResultsKeyPrefix + "latency.last": "30",
+		types.SummaryResultsKeyPrefix + "latency.min":  "10",
+		types.SummaryResultsKeyPrefix + "latency.max":  "30",
+		types.SummaryResultsKeyPrefix + "latency.avg":  "20.000",
+	}, data)
+}
+
+func TestApplySummaryShouldCreateAndUpdateSummaryConfigMap(t *testing.T) {
+	template := newTemplate()
+	fakeClient := fake.NewSimpleClientset(template)
+
+	runs := []corev1.ConfigMap{*newRun(template, 0, completedRunData("true", "10"))}
+	assert.NoError(t, schedule.ApplySummary(fakeClient, template, runs))
+
+	summary, err := configmap.Get(fakeClient, testNamespace, schedule.SummaryNameFor(testTemplateName))
+	assert.NoError(t, err)
+	assert.Equal(t, testTemplateName, summary.Labels[types.SummaryOfLabel])
+	assert.Equal(t, testTemplateName, summary.OwnerReferences[0].Name)
+	assert.Equal(t, "1", summary.Data[types.SummarySucceededKey])
+
+	runs = append(runs, *newRun(template, 1, completedRunData("false", "20")))
+	assert.NoError(t, schedule.ApplySummary(fakeClient, template, runs))
+
+	summary, err = configmap.Get(fakeClient, testNamespace, schedule.SummaryNameFor(testTemplateName))
+	assert.NoError(t, err)
+	assert.Equal(t, "1", summary.Data[types.SummaryFailedKey])
+	assert.Equal(t, runName(1), summary.Data[types.SummaryLastRunKey])
+}
+
+func newTemplate() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testTemplateName,
+			Namespace: testNamespace,
+			UID:       "0123-4567",
+			Annotations: map[string]string{
+				types.CheckupImageAnnotation:      "my-registry/example-checkup:main",
+				types.ScheduleAnnotation:          "30 2 * * *",
+				types.LastScheduleTimeAnnotation:  "2022-06-12T02:30:00Z",
+				types.ScheduleRetentionAnnotation: "3",
+			},
+		},
+		Data: map[string]string{types.TimeoutKey: "1m"},
+	}
+}
+
+// newRun returns the run scheduled the given number of days after the test schedule start.
+func newRun(template *corev1.ConfigMap, day int, statusData map[string]string) *corev1.ConfigMap {
+	run := schedule.NewRun(template, testScheduleStart.AddDate(0, 0, day))
+	for k, v := range statusData {
+		run.Data[k] = v
+	}
+	return run
+}
+
+func runName(day int) string {
+	return schedule.RunNameFor(testTemplateName, testScheduleStart.AddDate(0, 0, day))
+}
+
+func completedRunData(succeeded, latency string) map[string]string {
+	return map[string]string{
+		types.StartTimestampKey:         testScheduleStart.Format(time.RFC3339),
+		types.CompletionTimestampKey:    testScheduleStart.Format(time.RFC3339),
+		types.SucceededKey:              succeeded,
+		types.ResultsPrefix + "latency": latency,
+		types.ResultsPrefix + "ignored": "1",
+	}
+}
+
+func names(configMaps []corev1.ConfigMap) []string {
+	var configMapNames []string
+	for _, configMap := range configMaps {
+		configMapNames = append(configMapNames, configMap.Name)
+	}
+	return configMapNames
+}
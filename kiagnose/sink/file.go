@@ -0,0 +1,157 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package sink
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kiagnose/kiagnose/kiagnose/report"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+)
+
+const (
+	DefaultTerminationMessagePath = "/dev/termination-log"
+
+	// maxTerminationMessageSize is the size limit Kubernetes applies to a container termination message.
+	maxTerminationMessageSize = 4096
+
+	fileMode = 0o644
+)
+
+// JSONFile writes every status report to a file, replacing its previous content.
+type JSONFile struct {
+	path string
+}
+
+func NewJSONFile(path string) *JSONFile {
+	return &JSONFile{path: path}
+}
+
+func (f *JSONFile) Report(s status.Status) error {
+	content, err := encode(s)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFile(f.path, content); err != nil {
+		return fmt.Errorf("json file sink: %v", err)
+	}
+	return nil
+}
+
+// ReportFile writes the completion report to a file, rendered in one of the report formats.
+type ReportFile struct {
+	name   string
+	path   string
+	format string
+}
+
+// NewReportFile returns a sink writing the report of the named checkup, in the given report format.
+func NewReportFile(name, path, format string) *ReportFile {
+	return &ReportFile{name: name, path: path, format: format}
+}
+
+func (f *ReportFile) Report(s status.Status) error {
+	if s.CompletionTimestamp.IsZero() {
+		return nil
+	}
+
+	r, err := report.New(f.name, s)
+	if err != nil {
+		return err
+	}
+
+	var content bytes.Buffer
+	if err := r.Write(&content, f.format); err != nil {
+		return err
+	}
+
+	if err := writeFile(f.path, content.Bytes()); err != nil {
+		return fmt.Errorf("%s report file sink: %v", f.format, err)
+	}
+	return nil
+}
+
+// TerminationMessage writes the completion report as the container termination message,
+// so it is available in the checkup pod status.
+// Results are dropped when the report exceeds the termination message size limit.
+type TerminationMessage struct {
+	path string
+}
+
+func NewTerminationMessage(path string) *TerminationMessage {
+	return &TerminationMessage{path: path}
+}
+
+func (t *TerminationMessage) Report(s status.Status) error {
+	if s.CompletionTimestamp.IsZero() {
+		return nil
+	}
+
+	content, err := encode(s)
+	if err != nil {
+		return err
+	}
+
+	if len(content) > maxTerminationMessageSize {
+		s.Results = nil
+		s.Artifacts = nil
+		if content, err = encode(s); err != nil {
+			return err
+		}
+	}
+
+	if len(content) > maxTerminationMessageSize {
+		s.FailureDetails = nil
+		if content, err = encode(s); err != nil {
+			return err
+		}
+	}
+
+	if err := os.WriteFile(t.path, content, fileMode); err != nil {
+		return fmt.Errorf("termination message sink: %v", err)
+	}
+	return nil
+}
+
+// writeFile writes to a temporary file first and renames it, so readers never observe a partially written file.
+func writeFile(path string, content []byte) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(content); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpFile.Name(), fileMode); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), path)
+}
@@ -0,0 +1,128 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package sink
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/kiagnose/kiagnose/kiagnose/report"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+)
+
+// Sink receives the checkup status reports.
+// Sinks can be used as the launcher reporter, on their own or combined using Multi.
+type Sink interface {
+	Report(status.Status) error
+}
+
+// Settings selects the sinks the status is reported to, in addition to the user ConfigMap.
+type Settings struct {
+	// JSONFile is the path of a file the status is written to, e.g. on a mounted volume.
+	JSONFile string
+	// TerminationMessage is the path the completion status is written to, as the container termination message.
+	TerminationMessage string
+	// Webhook is the URL the completion status is posted to.
+	Webhook string
+	// JUnitFile is the path of a file the completion report is written to, as JUnit XML.
+	JUnitFile string
+	// ReportFile is the path of a file the completion report is written to, as a JSON document.
+	ReportFile string
+}
+
+// New returns a sink reporting the status of the named checkup to the primary sink,
+// followed by the sinks selected by the settings.
+// Only the primary sink errors are returned, the selected sinks errors are logged.
+func New(primary Sink, name string, settings Settings) Sink {
+	var sinks []Sink
+
+	if settings.JSONFile != "" {
+		sinks = append(sinks, NewJSONFile(settings.JSONFile))
+	}
+
+	if settings.TerminationMessage != "" {
+		sinks = append(sinks, NewTerminationMessage(settings.TerminationMessage))
+	}
+
+	if settings.Webhook != "" {
+		sinks = append(sinks, NewWebhook(settings.Webhook))
+	}
+
+	if settings.JUnitFile != "" {
+		sinks = append(sinks, NewReportFile(name, settings.JUnitFile, report.FormatJUnit))
+	}
+
+	if settings.ReportFile != "" {
+		sinks = append(sinks, NewReportFile(name, settings.ReportFile, report.FormatJSON))
+	}
+
+	if len(sinks) == 0 {
+		return primary
+	}
+	return withSecondary{primary: primary, secondary: Multi(sinks...)}
+}
+
+type withSecondary struct {
+	primary   Sink
+	secondary Sink
+}
+
+func (w withSecondary) Report(s status.Status) error {
+	err := w.primary.Report(s)
+
+	if secondaryErr := w.secondary.Report(s); secondaryErr != nil {
+		log.Printf("warning: failed to report status to sinks: %v", secondaryErr)
+	}
+
+	return err
+}
+
+type multi []Sink
+
+// Multi reports to all the given sinks, in order.
+// A failing sink does not prevent reporting to the following ones, and the errors are returned joined.
+func Multi(sinks ...Sink) Sink {
+	return multi(sinks)
+}
+
+func (m multi) Report(s status.Status) error {
+	var errs []string
+	for _, sink := range m {
+		if err := sink.Report(s); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// encode returns the status as a JSON object of the keys reported to the user ConfigMap.
+func encode(s status.Status) ([]byte, error) {
+	data, err := status.ToConfigMapData(s)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(data)
+}
@@ -0,0 +1,218 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package sink_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/kiagnose/kiagnose/kiagnose/report"
+	"github.com/kiagnose/kiagnose/kiagnose/sink"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const (
+	checkupName    = "checkup1"
+	startTimestamp = "2022-01-01T10:00:00Z"
+)
+
+func TestJSONFileShouldWriteEveryReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "status.json")
+	jsonFile := sink.NewJSONFile(path)
+
+	assert.NoError(t, jsonFile.Report(runningStatus()))
+	assert.Equal(t, map[string]string{
+		types.StartTimestampKey: startTimestamp,
+		types.PhaseKey:          string(status.PhaseRunning),
+		types.ProgressKey:       "",
+	}, readJSON(t, path))
+
+	assert.NoError(t, jsonFile.Report(completedStatus(map[string]string{"latency": "10"})))
+	data := readJSON(t, path)
+	assert.Equal(t, "true", data[types.SucceededKey])
+	assert.Equal(t, "10", data[types.ResultsPrefix+"latency"])
+}
+
+func TestTerminationMessageShould(t *testing.T) {
+	t.Run("ignore reports before completion", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "termination-log")
+
+		assert.NoError(t, sink.NewTerminationMessage(path).Report(runningStatus()))
+
+		_, err := os.Stat(path)
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("write the completion report", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "termination-log")
+
+		assert.NoError(t, sink.NewTerminationMessage(path).Report(completedStatus(map[string]string{"latency": "10"})))
+
+		data := readJSON(t, path)
+		assert.Equal(t, "true", data[types.SucceededKey])
+		assert.Equal(t, "10", data[types.ResultsPrefix+"latency"])
+	})
+
+	t.Run("drop results exceeding the termination message limit", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "termination-log")
+
+		assert.NoError(t, sink.NewTerminationMessage(path).Report(completedStatus(map[string]string{"output": strings.Repeat("x", 5000)})))
+
+		data := readJSON(t, path)
+		assert.Equal(t, "true", data[types.SucceededKey])
+		assert.NotContains(t, data, types.ResultsPrefix+"output")
+	})
+}
+
+func TestWebhookShould(t *testing.T) {
+	t.Run("post the completion report", func(t *testing.T) {
+		var requests []map[string]string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			data := map[string]string{}
+			assert.NoError(t, json.Unmarshal(body, &data))
+			requests = append(requests, data)
+		}))
+		defer server.Close()
+
+		webhook := sink.NewWebhook(server.URL, sink.WithHTTPClient(server.Client()))
+		assert.NoError(t, webhook.Report(runningStatus()))
+		assert.NoError(t, webhook.Report(completedStatus(map[string]string{"latency": "10"})))
+
+		assert.Len(t, requests, 1)
+		assert.Equal(t, "10", requests[0][types.ResultsPrefix+"latency"])
+	})
+
+	t.Run("fail when the endpoint does not accept the report", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		webhook := sink.NewWebhook(server.URL, sink.WithHTTPClient(server.Client()))
+		assert.ErrorContains(t, webhook.Report(completedStatus(nil)), "500")
+	})
+}
+
+func TestReportFileShouldWriteTheCompletionReport(t *testing.T) {
+	dir := t.TempDir()
+	junitPath := filepath.Join(dir, "junit.xml")
+	reportPath := filepath.Join(dir, "report.json")
+	reportSink := sink.New(&sinkStub{}, checkupName, sink.Settings{JUnitFile: junitPath, ReportFile: reportPath})
+
+	assert.NoError(t, reportSink.Report(runningStatus()))
+	_, err := os.Stat(junitPath)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	assert.NoError(t, reportSink.Report(completedStatus(map[string]string{"latency": "10"})))
+
+	junit, err := os.ReadFile(junitPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(junit), `<testsuite name="checkup1" tests="4" failures="0" skipped="0"`)
+
+	content, err := os.ReadFile(reportPath)
+	assert.NoError(t, err)
+	var r report.Report
+	assert.NoError(t, json.Unmarshal(content, &r))
+	assert.Equal(t, checkupName, r.Name)
+	assert.Equal(t, []report.Entry{{Name: "latency", Value: "10"}}, r.Results)
+}
+
+func TestMultiShouldReportToAllSinks(t *testing.T) {
+	const errMessage = "sink failure"
+	failing := &sinkStub{err: errors.New(errMessage)}
+	succeeding := &sinkStub{}
+
+	err := sink.Multi(failing, succeeding).Report(runningStatus())
+
+	assert.ErrorContains(t, err, errMessage)
+	assert.Equal(t, 1, failing.reports)
+	assert.Equal(t, 1, succeeding.reports)
+}
+
+func TestNewShouldReturnPrimaryWhenNoSinkIsSelected(t *testing.T) {
+	primary := &sinkStub{}
+
+	assert.Equal(t, primary, sink.New(primary, checkupName, sink.Settings{}))
+}
+
+func TestNewShouldReportToSelectedSinks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "status.json")
+	primary := &sinkStub{}
+
+	assert.NoError(t, sink.New(primary, checkupName, sink.Settings{JSONFile: path}).Report(runningStatus()))
+
+	assert.Equal(t, 1, primary.reports)
+	assert.Equal(t, string(status.PhaseRunning), readJSON(t, path)[types.PhaseKey])
+}
+
+func TestNewShouldNotFailOnSelectedSinksErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing-dir", "status.json")
+
+	assert.NoError(t, sink.New(&sinkStub{}, checkupName, sink.Settings{JSONFile: path}).Report(runningStatus()))
+}
+
+type sinkStub struct {
+	reports int
+	err     error
+}
+
+func (s *sinkStub) Report(status.Status) error {
+	s.reports++
+	return s.err
+}
+
+func runningStatus() status.Status {
+	timestamp, _ := time.Parse(time.RFC3339, startTimestamp)
+	return status.Status{StartTimestamp: timestamp, Phase: status.PhaseRunning}
+}
+
+func completedStatus(results map[string]string) status.Status {
+	s := runningStatus()
+	s.Phase = status.PhaseCompleted
+	s.Succeeded = true
+	s.CompletionTimestamp = s.StartTimestamp.Add(time.Minute)
+	s.Results = results
+	return s
+}
+
+func readJSON(t *testing.T, path string) map[string]string {
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	data := map[string]string{}
+	assert.NoError(t, json.Unmarshal(content, &data))
+	return data
+}
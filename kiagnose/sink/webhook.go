@@ -0,0 +1,90 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package sink
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+)
+
+const DefaultWebhookTimeout = 30 * time.Second
+
+// Webhook posts the completion report as JSON to an HTTP endpoint.
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+type WebhookOption func(*Webhook)
+
+// WithHTTPClient sets the client used to post the report.
+func WithHTTPClient(client *http.Client) WebhookOption {
+	return func(w *Webhook) {
+		w.client = client
+	}
+}
+
+func NewWebhook(url string, options ...WebhookOption) *Webhook {
+	w := &Webhook{
+		url:    url,
+		client: &http.Client{Timeout: DefaultWebhookTimeout},
+	}
+
+	for _, option := range options {
+		option(w)
+	}
+
+	return w
+}
+
+func (w *Webhook) Report(s status.Status) error {
+	if s.CompletionTimestamp.IsZero() {
+		return nil
+	}
+
+	content, err := encode(s)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodPost, w.url, bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("webhook sink: %v", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := w.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("webhook sink: %v", err)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook sink: %s responded with %q", w.url, response.Status)
+	}
+	return nil
+}
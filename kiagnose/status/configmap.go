@@ -0,0 +1,253 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package status
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kiagnose/kiagnose/kiagnose/artifacts"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+// FromConfigMap reads back the status reported to a user ConfigMap, including the status keys which were
+// compressed into the ConfigMap binaryData, or spilled into overflow ConfigMaps, to fit the ConfigMap size budget.
+func FromConfigMap(ctx context.Context, client kubernetes.Interface, configMap *corev1.ConfigMap) (Status, error) {
+	data, err := ConfigMapData(ctx, client, configMap)
+	if err != nil {
+		return Status{}, err
+	}
+	return FromConfigMapData(data)
+}
+
+// ConfigMapData returns the ConfigMap data, merged with its decompressed status binaryData and overflow keys.
+func ConfigMapData(ctx context.Context, client kubernetes.Interface, configMap *corev1.ConfigMap) (map[string]string, error) {
+	data := map[string]string{}
+	for k, v := range configMap.Data {
+		data[k] = v
+	}
+
+	for k, v := range configMap.BinaryData {
+		if !strings.HasPrefix(k, types.StatusKeyPrefix) {
+			continue
+		}
+		value, err := DecompressValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("%q field is illegal: %v", k, err)
+		}
+		data[k] = value
+	}
+
+	if rawReference, exists := data[types.OverflowKey]; exists {
+		overflow, err := readOverflow(ctx, client, configMap.Namespace, rawReference)
+		if err != nil {
+			return nil, fmt.Errorf("%q field is illegal: %v", types.OverflowKey, err)
+		}
+		for k, v := range overflow {
+			data[k] = v
+		}
+		delete(data, types.OverflowKey)
+	}
+
+	return data, nil
+}
+
+func readOverflow(ctx context.Context, client kubernetes.Interface, namespace, rawReference string) (map[string]string, error) {
+	content, err := artifacts.Read(ctx, client, namespace, rawReference)
+	if err != nil {
+		return nil, err
+	}
+
+	rawOverflow, err := DecompressValue(content)
+	if err != nil {
+		return nil, err
+	}
+
+	overflow := map[string]string{}
+	if err := json.Unmarshal([]byte(rawOverflow), &overflow); err != nil {
+		return nil, err
+	}
+	return overflow, nil
+}
+
+// CompressValue gzips a status value, as stored in the ConfigMap binaryData.
+func CompressValue(value string) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(value)); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecompressValue reverses CompressValue.
+func DecompressValue(compressed []byte) (string, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	value, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// ToConfigMapData encodes the status as the keys reported to a user ConfigMap.
+// Keys of fields which are not set are omitted.
+func ToConfigMapData(statusData Status) (map[string]string, error) {
+	data := map[string]string{}
+
+	if !statusData.StartTimestamp.IsZero() {
+		data[types.StartTimestampKey] = statusData.StartTimestamp.Format(time.RFC3339)
+	}
+
+	if !statusData.CompletionTimestamp.IsZero() {
+		data[types.CompletionTimestampKey] = statusData.CompletionTimestamp.Format(time.RFC3339)
+		data[types.SucceededKey] = strconv.FormatBool(statusData.Succeeded)
+		data[types.FailureReasonKey] = strings.Join(statusData.FailureReason, ",")
+		if len(statusData.FailureDetails) > 0 {
+			failureDetails, err := json.Marshal(statusData.FailureDetails)
+			if err != nil {
+				return nil, err
+			}
+			data[types.FailureDetailsKey] = string(failureDetails)
+		}
+	}
+
+	if statusData.Phase != "" {
+		data[types.PhaseKey] = string(statusData.Phase)
+		data[types.ProgressKey] = statusData.Progress
+	}
+
+	if !statusData.LastHeartbeat.IsZero() {
+		data[types.LastHeartbeatKey] = statusData.LastHeartbeat.Format(time.RFC3339)
+	}
+
+	for k, v := range statusData.Results {
+		data[types.ResultsPrefix+k] = v
+	}
+
+	for k, v := range statusData.Artifacts {
+		data[types.ArtifactsPrefix+k] = v
+	}
+
+	if len(statusData.Attempts) > 0 {
+		attempts, err := json.Marshal(statusData.Attempts)
+		if err != nil {
+			return nil, err
+		}
+		data[types.AttemptsKey] = string(attempts)
+	}
+
+	return data, nil
+}
+
+// FromConfigMapData reads back the status reported to a user ConfigMap.
+func FromConfigMapData(data map[string]string) (Status, error) {
+	var (
+		s   Status
+		err error
+	)
+
+	if s.StartTimestamp, err = parseTimestamp(data, types.StartTimestampKey); err != nil {
+		return Status{}, err
+	}
+
+	if s.CompletionTimestamp, err = parseTimestamp(data, types.CompletionTimestampKey); err != nil {
+		return Status{}, err
+	}
+
+	if s.LastHeartbeat, err = parseTimestamp(data, types.LastHeartbeatKey); err != nil {
+		return Status{}, err
+	}
+
+	s.Phase = Phase(data[types.PhaseKey])
+	s.Progress = data[types.ProgressKey]
+
+	if rawSucceeded, exists := data[types.SucceededKey]; exists {
+		if s.Succeeded, err = strconv.ParseBool(rawSucceeded); err != nil {
+			return Status{}, fmt.Errorf("%q field is illegal: %v", types.SucceededKey, err)
+		}
+	}
+
+	if failureReason := data[types.FailureReasonKey]; failureReason != "" {
+		s.FailureReason = []string{failureReason}
+	}
+
+	if rawFailureDetails := data[types.FailureDetailsKey]; rawFailureDetails != "" {
+		if err = json.Unmarshal([]byte(rawFailureDetails), &s.FailureDetails); err != nil {
+			return Status{}, fmt.Errorf("%q field is illegal: %v", types.FailureDetailsKey, err)
+		}
+	}
+
+	if rawAttempts := data[types.AttemptsKey]; rawAttempts != "" {
+		if err = json.Unmarshal([]byte(rawAttempts), &s.Attempts); err != nil {
+			return Status{}, fmt.Errorf("%q field is illegal: %v", types.AttemptsKey, err)
+		}
+	}
+
+	for k, v := range data {
+		if strings.HasPrefix(k, types.ResultsPrefix) {
+			if s.Results == nil {
+				s.Results = map[string]string{}
+			}
+			s.Results[strings.TrimPrefix(k, types.ResultsPrefix)] = v
+		}
+
+		if strings.HasPrefix(k, types.ArtifactsPrefix) {
+			if s.Artifacts == nil {
+				s.Artifacts = map[string]string{}
+			}
+			s.Artifacts[strings.TrimPrefix(k, types.ArtifactsPrefix)] = v
+		}
+	}
+
+	return s, nil
+}
+
+func parseTimestamp(data map[string]string, key string) (time.Time, error) {
+	rawTimestamp, exists := data[key]
+	if !exists {
+		return time.Time{}, nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, rawTimestamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q field is illegal: %v", key, err)
+	}
+
+	return timestamp, nil
+}
@@ -0,0 +1,148 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package status_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kiagnose/kiagnose/kiagnose/artifacts"
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/internal/generatename"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+func TestFromConfigMapDataShouldSucceed(t *testing.T) {
+	startTimestamp := time.Date(2022, time.May, 25, 11, 53, 49, 0, time.UTC)
+	completionTimestamp := startTimestamp.Add(time.Minute)
+	lastHeartbeat := completionTimestamp
+
+	data := map[string]string{
+		types.TimeoutKey:              "5m",
+		types.StartTimestampKey:       startTimestamp.Format(time.RFC3339),
+		types.CompletionTimestampKey:  completionTimestamp.Format(time.RFC3339),
+		types.SucceededKey:            "false",
+		types.FailureReasonKey:        "some reason",
+		types.FailureDetailsKey:       `[{"code":"SomeCode","phase":"Running","message":"some reason","retryable":false}]`,
+		types.PhaseKey:                string(status.PhaseCompleted),
+		types.ProgressKey:             "",
+		types.LastHeartbeatKey:        lastHeartbeat.Format(time.RFC3339),
+		types.ResultsPrefix + "key1":  "result 1",
+		types.ArtifactsPrefix + "log": `{"kind":"ConfigMap","objects":["cm-artifact-abcde"],"size":3}`,
+		types.AttemptsKey: `[{"startTimestamp":"2022-05-25T11:53:49Z","completionTimestamp":"2022-05-25T11:54:49Z",` +
+			`"succeeded":false,"failureReason":["some reason"],"results":{"key1":"result 1"}}]`,
+	}
+
+	actualStatus, err := status.FromConfigMapData(data)
+	assert.NoError(t, err)
+
+	expectedStatus := status.Status{
+		Succeeded:           false,
+		FailureReason:       []string{"some reason"},
+		FailureDetails:      []failure.Failure{{Code: "SomeCode", Phase: "Running", Message: "some reason"}},
+		Results:             map[string]string{"key1": "result 1"},
+		Artifacts:           map[string]string{"log": `{"kind":"ConfigMap","objects":["cm-artifact-abcde"],"size":3}`},
+		StartTimestamp:      startTimestamp,
+		CompletionTimestamp: completionTimestamp,
+		Phase:               status.PhaseCompleted,
+		LastHeartbeat:       lastHeartbeat,
+		Attempts: []status.Attempt{{
+			StartTimestamp:      startTimestamp,
+			CompletionTimestamp: completionTimestamp,
+			FailureReason:       []string{"some reason"},
+			Results:             map[string]string{"key1": "result 1"},
+		}},
+	}
+	assert.Equal(t, expectedStatus, actualStatus)
+
+	encodedData, err := status.ToConfigMapData(actualStatus)
+	assert.NoError(t, err)
+	assert.JSONEq(t, data[types.AttemptsKey], encodedData[types.AttemptsKey])
+}
+
+func TestFromConfigMapDataShouldFail(t *testing.T) {
+	t.Run("when a timestamp is illegal", func(t *testing.T) {
+		_, err := status.FromConfigMapData(map[string]string{types.StartTimestampKey: "yesterday"})
+		assert.ErrorContains(t, err, types.StartTimestampKey)
+	})
+
+	t.Run("when failure details are illegal", func(t *testing.T) {
+		_, err := status.FromConfigMapData(map[string]string{types.FailureDetailsKey: "{"})
+		assert.ErrorContains(t, err, types.FailureDetailsKey)
+	})
+
+	t.Run("when attempts are illegal", func(t *testing.T) {
+		_, err := status.FromConfigMapData(map[string]string{types.AttemptsKey: "["})
+		assert.ErrorContains(t, err, types.AttemptsKey)
+	})
+
+	t.Run("when succeeded is illegal", func(t *testing.T) {
+		_, err := status.FromConfigMapData(map[string]string{types.SucceededKey: "maybe"})
+		assert.ErrorContains(t, err, types.SucceededKey)
+	})
+}
+
+func TestFromConfigMapShouldReadCompressedAndOverflowResults(t *testing.T) {
+	const (
+		namespace     = "default"
+		configMapName = "checkup1"
+	)
+	fakeClient := fake.NewSimpleClientset()
+
+	compressedSamples, err := status.CompressValue("1ms,2ms,3ms")
+	assert.NoError(t, err)
+
+	rawOverflow, err := json.Marshal(map[string]string{types.ResultsPrefix + "spilled": "spilled value"})
+	assert.NoError(t, err)
+	compressedOverflow, err := status.CompressValue(string(rawOverflow))
+	assert.NoError(t, err)
+	generatename.Emulate(fakeClient)
+	store := artifacts.NewStore(fakeClient, namespace, configMapName, "")
+	references, err := store.Store(context.Background(), []artifacts.Artifact{{Name: "overflow", Content: compressedOverflow}})
+	assert.NoError(t, err)
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: configMapName, Namespace: namespace},
+		Data: map[string]string{
+			types.SucceededKey:            "true",
+			types.ResultsPrefix + "plain": "plain value",
+			types.OverflowKey:             references["overflow"],
+		},
+		BinaryData: map[string][]byte{types.ResultsPrefix + "samples": compressedSamples},
+	}
+
+	actualStatus, err := status.FromConfigMap(context.Background(), fakeClient, configMap)
+	assert.NoError(t, err)
+	assert.True(t, actualStatus.Succeeded)
+	assert.Equal(t, map[string]string{
+		"plain":   "plain value",
+		"samples": "1ms,2ms,3ms",
+		"spilled": "spilled value",
+	}, actualStatus.Results)
+}
@@ -19,12 +19,44 @@
 
 package status
 
-import "time"
+import (
+	"time"
+
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+)
+
+type Phase string
+
+const (
+	PhaseValidating  Phase = "Validating"
+	PhaseSettingUp   Phase = "SettingUp"
+	PhaseRunning     Phase = "Running"
+	PhaseTearingDown Phase = "TearingDown"
+	PhaseCompleted   Phase = "Completed"
+)
 
 type Status struct {
-	Succeeded           bool
-	FailureReason       []string
-	Results             map[string]string
+	Succeeded      bool
+	FailureReason  []string
+	FailureDetails []failure.Failure
+	Results        map[string]string
+	// Artifacts holds the references of the stored checkup artifacts, keyed by the artifacts names.
+	Artifacts           map[string]string
 	StartTimestamp      time.Time
 	CompletionTimestamp time.Time
+	Phase               Phase
+	Progress            string
+	LastHeartbeat       time.Time
+	// Attempts holds the outcome of each attempt, from the first to the final one, in case the checkup was retried.
+	Attempts []Attempt
+}
+
+// Attempt is the outcome of a single execution of the checkup setup, run and teardown.
+type Attempt struct {
+	StartTimestamp      time.Time         `json:"startTimestamp"`
+	CompletionTimestamp time.Time         `json:"completionTimestamp"`
+	Succeeded           bool              `json:"succeeded"`
+	FailureReason       []string          `json:"failureReason,omitempty"`
+	FailureDetails      []failure.Failure `json:"failureDetails,omitempty"`
+	Results             map[string]string `json:"results,omitempty"`
 }
@@ -0,0 +1,188 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package suite
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kiagnose/kiagnose/kiagnose/cancellation"
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+type State string
+
+const (
+	StatePending   State = "Pending"
+	StateRunning   State = "Running"
+	StateSucceeded State = "Succeeded"
+	StateFailed    State = "Failed"
+	StateCancelled State = "Cancelled"
+	StateSkipped   State = "Skipped"
+)
+
+type MemberStatus struct {
+	Member        Member
+	State         State
+	FailureReason string
+}
+
+// Progress is the state of a suite run, along with the actions advancing it.
+type Progress struct {
+	Members []MemberStatus
+	// Launch lists the members to create the ConfigMap of.
+	Launch []Member
+	// Cancel lists the running members to request the cancellation of.
+	Cancel    []Member
+	Completed bool
+}
+
+// Evaluate computes the suite progress given the ConfigMaps of its members, keyed by the member names.
+// In serial mode, a member is launched once the previous one has completed, and in parallel mode all members
+// are launched at once.
+// When stopping on failure, the first failure skips the members not launched yet and cancels the running ones.
+func (s Suite) Evaluate(memberConfigMaps map[string]*corev1.ConfigMap) Progress {
+	var p Progress
+
+	failed, running := false, false
+	for _, member := range s.Members {
+		memberStatus := newMemberStatus(member, memberConfigMaps[member.Name])
+		failed = failed || memberStatus.State == StateFailed || memberStatus.State == StateCancelled
+		running = running || memberStatus.State == StateRunning
+		p.Members = append(p.Members, memberStatus)
+	}
+
+	stopped := s.StopOnFailure && failed
+	for i := range p.Members {
+		memberStatus := &p.Members[i]
+		switch {
+		case stopped && memberStatus.State == StatePending:
+			memberStatus.State = StateSkipped
+		case stopped && memberStatus.State == StateRunning:
+			if !cancellation.Requested(memberConfigMaps[memberStatus.Member.Name]) {
+				p.Cancel = append(p.Cancel, memberStatus.Member)
+			}
+		case memberStatus.State == StatePending && (s.Mode == ModeParallel || !running):
+			p.Launch = append(p.Launch, memberStatus.Member)
+			memberStatus.State = StateRunning
+			running = true
+		}
+	}
+
+	p.Completed = true
+	for _, memberStatus := range p.Members {
+		if memberStatus.State == StatePending || memberStatus.State == StateRunning {
+			p.Completed = false
+		}
+	}
+
+	return p
+}
+
+// Status returns the aggregated suite status, as reported to the suite ConfigMap.
+// The results hold the state of each member, and the suite succeeds when all of its members have succeeded.
+func (p Progress) Status(suiteConfigMap *corev1.ConfigMap, startTimestamp, now time.Time) status.Status {
+	suiteStatus := status.Status{
+		Results:        map[string]string{},
+		StartTimestamp: startTimestamp,
+		Phase:          status.PhaseRunning,
+	}
+
+	completedCount, succeededCount := 0, 0
+	for _, memberStatus := range p.Members {
+		suiteStatus.Results[memberStatus.Member.Name] = string(memberStatus.State)
+
+		switch memberStatus.State {
+		case StateSucceeded:
+			succeededCount++
+			completedCount++
+		case StateFailed, StateCancelled:
+			completedCount++
+			suiteStatus.FailureReason = append(suiteStatus.FailureReason, memberFailureReason(memberStatus))
+			suiteStatus.FailureDetails = append(suiteStatus.FailureDetails, memberFailure(suiteConfigMap, memberStatus))
+		case StateSkipped:
+			completedCount++
+		}
+	}
+	suiteStatus.Progress = fmt.Sprintf("%d/%d members completed", completedCount, len(p.Members))
+
+	if p.Completed {
+		suiteStatus.Succeeded = succeededCount == len(p.Members)
+		suiteStatus.CompletionTimestamp = now
+		suiteStatus.Phase = status.PhaseCompleted
+	}
+
+	return suiteStatus
+}
+
+func newMemberStatus(member Member, configMap *corev1.ConfigMap) MemberStatus {
+	memberStatus := MemberStatus{Member: member, State: StatePending}
+	if configMap == nil {
+		return memberStatus
+	}
+
+	if _, completed := configMap.Data[types.CompletionTimestampKey]; !completed {
+		memberStatus.State = StateRunning
+		return memberStatus
+	}
+
+	succeeded, _ := strconv.ParseBool(configMap.Data[types.SucceededKey])
+	switch {
+	case succeeded:
+		memberStatus.State = StateSucceeded
+	case cancellation.Requested(configMap):
+		memberStatus.State = StateCancelled
+	default:
+		memberStatus.State = StateFailed
+	}
+	memberStatus.FailureReason = configMap.Data[types.FailureReasonKey]
+
+	return memberStatus
+}
+
+func memberFailureReason(memberStatus MemberStatus) string {
+	if memberStatus.State == StateCancelled {
+		return fmt.Sprintf("member %q was cancelled", memberStatus.Member.Name)
+	}
+	return fmt.Sprintf("member %q failed: %s", memberStatus.Member.Name, memberStatus.FailureReason)
+}
+
+func memberFailure(suiteConfigMap *corev1.ConfigMap, memberStatus MemberStatus) failure.Failure {
+	code := failure.CodeSuiteMemberFailed
+	if memberStatus.State == StateCancelled {
+		code = failure.CodeCancelled
+	}
+
+	return failure.Failure{
+		Code:    code,
+		Message: memberFailureReason(memberStatus),
+		Object: &corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Namespace:  suiteConfigMap.Namespace,
+			Name:       MemberNameFor(suiteConfigMap.Name, memberStatus.Member.Name),
+		},
+	}
+}
@@ -0,0 +1,241 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package suite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+type Mode string
+
+const (
+	ModeSerial   Mode = "serial"
+	ModeParallel Mode = "parallel"
+)
+
+const (
+	memberImageField          = "image"
+	memberServiceAccountField = "serviceAccount"
+)
+
+var (
+	ErrModeFieldIsIllegal          = errors.New("suite mode field is illegal")
+	ErrStopOnFailureFieldIsIllegal = errors.New("suite stop on failure field is illegal")
+	ErrMembersFieldIsMissing       = errors.New("suite members field is missing")
+	ErrMemberIsIllegal             = errors.New("suite member is illegal")
+)
+
+// Member is a checkup executed as part of a suite.
+type Member struct {
+	Name           string
+	Image          string
+	ServiceAccount string
+	// Data holds the member checkup input fields, e.g. spec.timeout and spec.param.*.
+	Data map[string]string
+}
+
+// Suite is a batch of checkups, defined by a ConfigMap annotated with kiagnose.io/suite.
+type Suite struct {
+	Mode          Mode
+	StopOnFailure bool
+	// Members are ordered as they are listed in the suite ConfigMap.
+	Members []Member
+}
+
+// Parse reads the suite definition from its ConfigMap.
+// Each member is listed in the spec.suite.members key, and defined by the spec.member.<member name>.* keys:
+// the image and serviceAccount keys set the checkup image and ServiceAccount, and the other keys are
+// passed to the member checkup as its spec.* input fields.
+func Parse(configMap *corev1.ConfigMap) (Suite, error) {
+	s := Suite{Mode: ModeSerial}
+
+	if rawMode, exists := configMap.Data[types.SuiteModeKey]; exists {
+		s.Mode = Mode(rawMode)
+		if s.Mode != ModeSerial && s.Mode != ModeParallel {
+			return Suite{}, fmt.Errorf("%w: %q: should be %q or %q", ErrModeFieldIsIllegal, rawMode, ModeSerial, ModeParallel)
+		}
+	}
+
+	if rawStopOnFailure, exists := configMap.Data[types.SuiteStopOnFailureKey]; exists {
+		var err error
+		if s.StopOnFailure, err = strconv.ParseBool(rawStopOnFailure); err != nil {
+			return Suite{}, fmt.Errorf("%w: %q", ErrStopOnFailureFieldIsIllegal, rawStopOnFailure)
+		}
+	}
+
+	members, err := parseMembers(configMap)
+	if err != nil {
+		return Suite{}, err
+	}
+	s.Members = members
+
+	return s, nil
+}
+
+// MemberNameFor returns the name of the ConfigMap of the given suite member.
+func MemberNameFor(suiteName, memberName string) string {
+	return suiteName + "-" + memberName
+}
+
+// NewMemberConfigMap returns the ConfigMap of the given suite member, labeled with the suite and member names
+// and owned by the suite ConfigMap.
+func NewMemberConfigMap(suiteConfigMap *corev1.ConfigMap, member Member) *corev1.ConfigMap {
+	annotations := map[string]string{types.CheckupImageAnnotation: member.Image}
+	if member.ServiceAccount != "" {
+		annotations[types.CheckupServiceAccountAnnotation] = member.ServiceAccount
+	}
+
+	data := map[string]string{}
+	for k, v := range member.Data {
+		data[k] = v
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      MemberNameFor(suiteConfigMap.Name, member.Name),
+			Namespace: suiteConfigMap.Namespace,
+			Labels: map[string]string{
+				types.SuiteOfLabel:     suiteConfigMap.Name,
+				types.SuiteMemberLabel: member.Name,
+			},
+			Annotations: annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "v1",
+					Kind:       "ConfigMap",
+					Name:       suiteConfigMap.Name,
+					UID:        suiteConfigMap.UID,
+				},
+			},
+		},
+		Data: data,
+	}
+}
+
+func parseMembers(configMap *corev1.ConfigMap) ([]Member, error) {
+	var names []string
+	for _, name := range strings.Split(configMap.Data[types.SuiteMembersKey], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, ErrMembersFieldIsMissing
+	}
+
+	membersData := map[string]map[string]string{}
+	for k, v := range configMap.Data {
+		rawMember := strings.TrimPrefix(k, types.SuiteMemberKeyPrefix)
+		if rawMember == k {
+			continue
+		}
+		name, field, found := strings.Cut(rawMember, ".")
+		if !found || field == "" {
+			return nil, fmt.Errorf("%w: key %q should be of the form %s<member>.<field>", ErrMemberIsIllegal, k, types.SuiteMemberKeyPrefix)
+		}
+		if membersData[name] == nil {
+			membersData[name] = map[string]string{}
+		}
+		membersData[name][field] = v
+	}
+
+	var members []Member
+	for _, name := range names {
+		member, err := newMember(configMap.Name, name, membersData[name])
+		if err != nil {
+			return nil, err
+		}
+		for _, existingMember := range members {
+			if existingMember.Name == name {
+				return nil, fmt.Errorf("%w: %q is listed more than once", ErrMemberIsIllegal, name)
+			}
+		}
+		members = append(members, member)
+		delete(membersData, name)
+	}
+
+	if len(membersData) > 0 {
+		unlisted := make([]string, 0, len(membersData))
+		for name := range membersData {
+			unlisted = append(unlisted, name)
+		}
+		sort.Strings(unlisted)
+		return nil, fmt.Errorf("%w: %q not listed in %s", ErrMemberIsIllegal, unlisted, types.SuiteMembersKey)
+	}
+
+	return members, nil
+}
+
+func newMember(suiteName, name string, fields map[string]string) (Member, error) {
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return Member{}, fmt.Errorf("%w: name %q: %s", ErrMemberIsIllegal, name, strings.Join(errs, ", "))
+	}
+	if errs := validation.IsDNS1123Subdomain(MemberNameFor(suiteName, name)); len(errs) > 0 {
+		return Member{}, fmt.Errorf("%w: ConfigMap name %q: %s", ErrMemberIsIllegal, MemberNameFor(suiteName, name), strings.Join(errs, ", "))
+	}
+
+	member := Member{
+		Name:           name,
+		Image:          fields[memberImageField],
+		ServiceAccount: fields[memberServiceAccountField],
+		Data:           map[string]string{},
+	}
+	if member.Image == "" {
+		return Member{}, fmt.Errorf("%w: %q has no %s%s.%s key", ErrMemberIsIllegal, name, types.SuiteMemberKeyPrefix, name, memberImageField)
+	}
+
+	for field, v := range fields {
+		if field != memberImageField && field != memberServiceAccountField {
+			member.Data["spec."+field] = v
+		}
+	}
+
+	return member, nil
+}
+
+// ListMembers returns the ConfigMaps of the members of the given suite, keyed by the member names.
+func ListMembers(client kubernetes.Interface, namespace, suiteName string) (map[string]*corev1.ConfigMap, error) {
+	selector := labels.SelectorFromSet(labels.Set{types.SuiteOfLabel: suiteName})
+	configMapList, err := client.CoreV1().ConfigMaps(namespace).List(
+		context.Background(), metav1.ListOptions{LabelSelector: selector.String()},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	members := map[string]*corev1.ConfigMap{}
+	for i := range configMapList.Items {
+		members[configMapList.Items[i].Labels[types.SuiteMemberLabel]] = &configMapList.Items[i]
+	}
+	return members, nil
+}
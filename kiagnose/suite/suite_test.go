@@ -0,0 +1,310 @@
+/*
+ * This file is part of the kiagnose project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2022 Red Hat, Inc.
+ *
+ */
+
+package suite_test
+
+import (
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiagnose/kiagnose/kiagnose/failure"
+	"github.com/kiagnose/kiagnose/kiagnose/status"
+	"github.com/kiagnose/kiagnose/kiagnose/suite"
+	"github.com/kiagnose/kiagnose/kiagnose/types"
+)
+
+const (
+	testNamespace = "target-ns"
+	testSuiteName = "network-suite"
+	latencyImage  = "quay.io/kiagnose/kubevirt-vm-latency:main"
+	dpdkImage     = "quay.io/kiagnose/kubevirt-dpdk-checkup:main"
+)
+
+func TestParseShouldSucceed(t *testing.T) {
+	suiteConfigMap := newSuiteConfigMap(map[string]string{
+		types.SuiteModeKey:          string(suite.ModeParallel),
+		types.SuiteStopOnFailureKey: "true",
+		"spec.member.dpdk.timeout":  "10m",
+	})
+
+	s, err := suite.Parse(suiteConfigMap)
+	assert.NoError(t, err)
+	assert.Equal(t, suite.Suite{
+		Mode:          suite.ModeParallel,
+		StopOnFailure: true,
+		Members: []suite.Member{
+			{
+				Name:           "latency",
+				Image:          latencyImage,
+				ServiceAccount: "vm-latency-checkup-sa",
+				Data:           map[string]string{types.TimeoutKey: "5m", types.ParamNameKeyPrefix + "sampleDurationSeconds": "5"},
+			},
+			{
+				Name:  "dpdk",
+				Image: dpdkImage,
+				Data:  map[string]string{types.TimeoutKey: "10m"},
+			},
+		},
+	}, s)
+}
+
+func TestParseShouldDefaultToSerialMode(t *testing.T) {
+	s, err := suite.Parse(newSuiteConfigMap(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, suite.ModeSerial, s.Mode)
+	assert.False(t, s.StopOnFailure)
+}
+
+func TestParseShouldFail(t *testing.T) {
+	testCases := []struct {
+		description   string
+		data          map[string]string
+		expectedError error
+	}{
+		{"when mode is unknown", map[string]string{types.SuiteModeKey: "random"}, suite.ErrModeFieldIsIllegal},
+		{"when stop on failure is not a boolean", map[string]string{types.SuiteStopOnFailureKey: "yes"}, suite.ErrStopOnFailureFieldIsIllegal},
+		{"when members are not listed", map[string]string{types.SuiteMembersKey: " "}, suite.ErrMembersFieldIsMissing},
+		{"when a member is listed twice", map[string]string{types.SuiteMembersKey: "latency,dpdk,latency"}, suite.ErrMemberIsIllegal},
+		{"when a member is not listed", map[string]string{types.SuiteMembersKey: "latency"}, suite.ErrMemberIsIllegal},
+		{"when a member has no image", map[string]string{types.SuiteMembersKey: "latency,dpdk,other"}, suite.ErrMemberIsIllegal},
+		{"when a member key has no field", map[string]string{"spec.member.dpdk": "value"}, suite.ErrMemberIsIllegal},
+		{
+			"when a member name is not a DNS label",
+			map[string]string{types.SuiteMembersKey: "latency,dpdk,Other", "spec.member.Other.image": dpdkImage},
+			suite.ErrMemberIsIllegal,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			_, err := suite.Parse(newSuiteConfigMap(testCase.data))
+			assert.ErrorIs(t, err, testCase.expectedError)
+		})
+	}
+}
+
+func TestNewMemberConfigMap(t *testing.T) {
+	suiteConfigMap := newSuiteConfigMap(nil)
+	s, err := suite.Parse(suiteConfigMap)
+	assert.NoError(t, err)
+
+	memberConfigMap := suite.NewMemberConfigMap(suiteConfigMap, s.Members[0])
+
+	assert.Equal(t, testSuiteName+"-latency", memberConfigMap.Name)
+	assert.Equal(t, testNamespace, memberConfigMap.Namespace)
+	assert.Equal(t, map[string]string{types.SuiteOfLabel: testSuiteName, types.SuiteMemberLabel: "latency"}, memberConfigMap.Labels)
+	assert.Equal(t, map[string]string{
+		types.CheckupImageAnnotation:          latencyImage,
+		types.CheckupServiceAccountAnnotation: "vm-latency-checkup-sa",
+	}, memberConfigMap.Annotations)
+	assert.Equal(t, s.Members[0].Data, memberConfigMap.Data)
+	assert.Equal(t, testSuiteName, memberConfigMap.OwnerReferences[0].Name)
+}
+
+func TestEvaluate(t *testing.T) {
+	testCases := []struct {
+		description       string
+		mode              suite.Mode
+		stopOnFailure     bool
+		memberConfigMaps  map[string]*corev1.ConfigMap
+		expectedStates    []suite.State
+		expectedLaunched  []string
+		expectedCancelled []string
+		expectedCompleted bool
+	}{
+		{
+			description:      "serial suite launches the first member",
+			mode:             suite.ModeSerial,
+			expectedStates:   []suite.State{suite.StateRunning, suite.StatePending},
+			expectedLaunched: []string{"latency"},
+		},
+		{
+			description:      "serial suite waits for the running member",
+			mode:             suite.ModeSerial,
+			memberConfigMaps: map[string]*corev1.ConfigMap{"latency": newMemberConfigMap(nil)},
+			expectedStates:   []suite.State{suite.StateRunning, suite.StatePending},
+		},
+		{
+			description:      "serial suite launches the next member after a failure",
+			mode:             suite.ModeSerial,
+			memberConfigMaps: map[string]*corev1.ConfigMap{"latency": newMemberConfigMap(completedData("false"))},
+			expectedStates:   []suite.State{suite.StateFailed, suite.StateRunning},
+			expectedLaunched: []string{"dpdk"},
+		},
+		{
+			description:       "serial suite stops on failure",
+			mode:              suite.ModeSerial,
+			stopOnFailure:     true,
+			memberConfigMaps:  map[string]*corev1.ConfigMap{"latency": newMemberConfigMap(completedData("false"))},
+			expectedStates:    []suite.State{suite.StateFailed, suite.StateSkipped},
+			expectedCompleted: true,
+		},
+		{
+			description:      "parallel suite launches all members",
+			mode:             suite.ModeParallel,
+			expectedStates:   []suite.State{suite.StateRunning, suite.StateRunning},
+			expectedLaunched: []string{"latency", "dpdk"},
+		},
+		{
+			description:   "parallel suite cancels running members on failure",
+			mode:          suite.ModeParallel,
+			stopOnFailure: true,
+			memberConfigMaps: map[string]*corev1.ConfigMap{
+				"latency": newMemberConfigMap(nil),
+				"dpdk":    newMemberConfigMap(completedData("false")),
+			},
+			expectedStates:    []suite.State{suite.StateRunning, suite.StateFailed},
+			expectedCancelled: []string{"latency"},
+		},
+		{
+			description:   "parallel suite completes once cancelled members complete",
+			mode:          suite.ModeParallel,
+			stopOnFailure: true,
+			memberConfigMaps: map[string]*corev1.ConfigMap{
+				"latency": cancelled(newMemberConfigMap(completedData("false"))),
+				"dpdk":    newMemberConfigMap(completedData("false")),
+			},
+			expectedStates:    []suite.State{suite.StateCancelled, suite.StateFailed},
+			expectedCompleted: true,
+		},
+		{
+			description: "suite completes once all members complete",
+			mode:        suite.ModeSerial,
+			memberConfigMaps: map[string]*corev1.ConfigMap{
+				"latency": newMemberConfigMap(completedData("true")),
+				"dpdk":    newMemberConfigMap(completedData("true")),
+			},
+			expectedStates:    []suite.State{suite.StateSucceeded, suite.StateSucceeded},
+			expectedCompleted: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			s, err := suite.Parse(newSuiteConfigMap(nil))
+			assert.NoError(t, err)
+			s.Mode = testCase.mode
+			s.StopOnFailure = testCase.stopOnFailure
+
+			progress := s.Evaluate(testCase.memberConfigMaps)
+
+			var states []suite.State
+			for _, memberStatus := range progress.Members {
+				states = append(states, memberStatus.State)
+			}
+			assert.Equal(t, testCase.expectedStates, states)
+			assert.Equal(t, testCase.expectedLaunched, memberNames(progress.Launch))
+			assert.Equal(t, testCase.expectedCancelled, memberNames(progress.Cancel))
+			assert.Equal(t, testCase.expectedCompleted, progress.Completed)
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	suiteConfigMap := newSuiteConfigMap(nil)
+	s, err := suite.Parse(suiteConfigMap)
+	assert.NoError(t, err)
+	startTimestamp := time.Now().Add(-time.Minute)
+	now := time.Now()
+
+	t.Run("while running", func(t *testing.T) {
+		progress := s.Evaluate(map[string]*corev1.ConfigMap{"latency": newMemberConfigMap(completedData("true"))})
+
+		assert.Equal(t, status.Status{
+			Results:        map[string]string{"latency": string(suite.StateSucceeded), "dpdk": string(suite.StateRunning)},
+			StartTimestamp: startTimestamp,
+			Phase:          status.PhaseRunning,
+			Progress:       "1/2 members completed",
+		}, progress.Status(suiteConfigMap, startTimestamp, now))
+	})
+
+	t.Run("once completed", func(t *testing.T) {
+		progress := s.Evaluate(map[string]*corev1.ConfigMap{
+			"latency": newMemberConfigMap(completedData("true")),
+			"dpdk":    newMemberConfigMap(completedData("false")),
+		})
+
+		suiteStatus := progress.Status(suiteConfigMap, startTimestamp, now)
+		assert.False(t, suiteStatus.Succeeded)
+		assert.Equal(t, now, suiteStatus.CompletionTimestamp)
+		assert.Equal(t, status.PhaseCompleted, suiteStatus.Phase)
+		assert.Equal(t, []string{`member "dpdk" failed: some failure`}, suiteStatus.FailureReason)
+		assert.Equal(t, []failure.Failure{{
+			Code:    failure.CodeSuiteMemberFailed,
+			Message: `member "dpdk" failed: some failure`,
+			Object:  &corev1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: testNamespace, Name: testSuiteName + "-dpdk"},
+		}}, suiteStatus.FailureDetails)
+	})
+}
+
+func newSuiteConfigMap(data map[string]string) *corev1.ConfigMap {
+	suiteData := map[string]string{
+		types.SuiteMembersKey:                             "latency, dpdk",
+		"spec.member.latency.image":                       latencyImage,
+		"spec.member.latency.serviceAccount":              "vm-latency-checkup-sa",
+		"spec.member.latency.timeout":                     "5m",
+		"spec.member.latency.param.sampleDurationSeconds": "5",
+		"spec.member.dpdk.image":                          dpdkImage,
+	}
+	for k, v := range data {
+		suiteData[k] = v
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        testSuiteName,
+			Namespace:   testNamespace,
+			Annotations: map[string]string{types.SuiteAnnotation: ""},
+		},
+		Data: suiteData,
+	}
+}
+
+func newMemberConfigMap(statusData map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{Data: statusData}
+}
+
+func completedData(succeeded string) map[string]string {
+	data := map[string]string{
+		types.CompletionTimestampKey: time.Now().Format(time.RFC3339),
+		types.SucceededKey:           succeeded,
+	}
+	if succeeded != "true" {
+		data[types.FailureReasonKey] = "some failure"
+	}
+	return data
+}
+
+func cancelled(configMap *corev1.ConfigMap) *corev1.ConfigMap {
+	configMap.Annotations = map[string]string{types.CancelAnnotation: "true"}
+	return configMap
+}
+
+func memberNames(members []suite.Member) []string {
+	var names []string
+	for _, member := range members {
+		names = append(names, member.Name)
+	}
+	return names
+}
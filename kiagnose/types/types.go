@@ -22,12 +22,75 @@ package types
 const (
 	TimeoutKey         = "spec.timeout"
 	ParamNameKeyPrefix = "spec.param."
+	RerunnableKey      = "spec.rerunnable"
+	HistoryLimitKey    = "spec.historyLimit"
+	CancelKey          = "spec.cancel"
+	SetupTimeoutKey    = "spec.setupTimeout"
+	RunTimeoutKey      = "spec.runTimeout"
+	TeardownTimeoutKey = "spec.teardownTimeout"
+	RetriesKey         = "spec.retries"
+	RetryBackoffKey    = "spec.retryBackoff"
+	SuccessCriteriaKey = "spec.successCriteria"
+
+	BaselineConfigMapKey    = "spec.baselineConfigMap"
+	BaselineTolerancePrefix = "spec.baselineTolerance."
+
+	SinkKeyPrefix             = "spec.sink."
+	SinkJSONFileKey           = "spec.sink.jsonFile"
+	SinkTerminationMessageKey = "spec.sink.terminationMessage"
+	SinkWebhookKey            = "spec.sink.webhook"
+	SinkJUnitFileKey          = "spec.sink.junitFile"
+	SinkReportFileKey         = "spec.sink.reportFile"
+
+	SuiteModeKey          = "spec.suite.mode"
+	SuiteStopOnFailureKey = "spec.suite.stopOnFailure"
+	SuiteMembersKey       = "spec.suite.members"
+	SuiteMemberKeyPrefix  = "spec.member."
 )
 
+// StatusKeyPrefix is the prefix shared by all the keys reported to the ConfigMap.
+const StatusKeyPrefix = "status."
+
 const (
 	SucceededKey           = "status.succeeded"
 	FailureReasonKey       = "status.failureReason"
+	FailureDetailsKey      = "status.failureDetails"
 	ResultsPrefix          = "status.result."
+	ArtifactsPrefix        = "status.artifacts."
+	OverflowKey            = "status.overflow"
 	StartTimestampKey      = "status.startTimestamp"
 	CompletionTimestampKey = "status.completionTimestamp"
+	PhaseKey               = "status.phase"
+	ProgressKey            = "status.progress"
+	LastHeartbeatKey       = "status.lastHeartbeat"
+	AttemptsKey            = "status.attempts"
+)
+
+const (
+	CheckupImageAnnotation          = "kiagnose.io/checkup-image"
+	CheckupServiceAccountAnnotation = "kiagnose.io/checkup-service-account"
+	CheckupConfigMapLabel           = "kiagnose.io/checkup-configmap"
+	CheckupResourceLabel            = "kiagnose.io/checkup-resource"
+	HistoryOfLabel                  = "kiagnose.io/history-of"
+	RunNumberLabel                  = "kiagnose.io/run"
+	CancelAnnotation                = "kiagnose.io/cancel"
+	ArtifactOfLabel                 = "kiagnose.io/artifact-of"
+	ScheduleAnnotation              = "kiagnose.io/schedule"
+	ScheduleRetentionAnnotation     = "kiagnose.io/schedule-retention"
+	SummaryResultsAnnotation        = "kiagnose.io/summary-results"
+	LastScheduleTimeAnnotation      = "kiagnose.io/last-schedule-time"
+	ScheduledTimeAnnotation         = "kiagnose.io/scheduled-time"
+	ScheduledByLabel                = "kiagnose.io/scheduled-by"
+	SummaryOfLabel                  = "kiagnose.io/summary-of"
+	SuiteAnnotation                 = "kiagnose.io/suite"
+	SuiteOfLabel                    = "kiagnose.io/suite-of"
+	SuiteMemberLabel                = "kiagnose.io/suite-member"
+)
+
+const (
+	SummaryRunsKey          = "summary.runs"
+	SummarySucceededKey     = "summary.succeeded"
+	SummaryFailedKey        = "summary.failed"
+	SummaryLastRunKey       = "summary.lastRun"
+	SummaryResultsKeyPrefix = "summary.result."
 )